@@ -0,0 +1,396 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"space/database"
+	"space/repositories"
+	"space/services"
+	"space/telegram"
+	"space/utils"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// command is a subcommand of the server binary. Every command reads the same
+// ./config/config.json and environment as serve.
+type command struct {
+	name  string
+	args  string
+	about string
+	run   func(args []string) error
+}
+
+// commands lists the subcommands in the order printUsage shows them; serve runs when none is given
+var commands = []command{
+	{"serve", "[-seed] [-seed-dir DIR]", "migrate the database and start the HTTP server", serve},
+	{"migrate", "up [-to VERSION] | down [-steps N] [-include-baseline] | status", "apply, revert or list schema migrations", runMigrate},
+	{"seed", "[-dir DIR]", "apply new or changed seed files", runSeed},
+	{"create-admin", "-username U [-email E] [-password P] [-group ID]", "create or promote a site administrator, optionally making it a group owner", runCreateAdmin},
+	{"reset-password", "-username U [-password P]", "set a new password for an account", runResetPassword},
+	{"export", "[-out FILE] [-tables a,b]", "dump tables as JSON", runExport},
+	{"check-config", "", "validate settings and database access", runCheckConfig},
+	{"help", "", "show this message", nil},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: go-api [command] [flags]")
+	fmt.Fprintln(os.Stderr)
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.about)
+	}
+	w.Flush()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Passwords left empty are generated and printed once.")
+}
+
+// connect opens the database without migrating it. SQL is only logged on warnings, and to
+// stderr, so command output stays readable.
+func connect() error {
+	if err := database.Connect(); err != nil {
+		return err
+	}
+	database.DB.Logger = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold:             time.Second,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+	})
+	return nil
+}
+
+// generatedPassword returns password, or a random one when it is empty
+func generatedPassword(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	generated, err := utils.RandomCode(12)
+	return generated, true, err
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected up, down or status")
+	}
+	action, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	to := flags.Int("to", 0, "apply migrations up to this version (default: all)")
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	includeBaseline := flags.Bool("include-baseline", false, "allow reverting the baseline, which drops every table")
+
+	switch action {
+	case "up":
+		flags.Parse(args)
+		if err := connect(); err != nil {
+			return err
+		}
+		applied, err := database.MigrateUp(database.DB, *to)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+	case "down":
+		flags.Parse(args)
+		if *steps < 1 {
+			return errors.New("steps must be at least 1")
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		reverted, err := database.MigrateDown(database.DB, *steps, *includeBaseline)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		flags.Parse(args)
+		if err := connect(); err != nil {
+			return err
+		}
+		statuses, err := database.MigrationStatuses(database.DB)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Modified {
+				state += " (modified)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate action %q, expected up, down or status", action)
+	}
+}
+
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	dir := flags.String("dir", "", "directory with seed files (default: the seeds built into the binary)")
+	flags.Parse(args)
+
+	if err := connect(); err != nil {
+		return err
+	}
+	results, err := database.Seed(database.DB, *dir)
+	for _, result := range results {
+		fmt.Printf("%-8s %s (%d rows)\n", result.Status, result.Name, result.Rows)
+	}
+	return err
+}
+
+func runCreateAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	username := flags.String("username", "", "username of the account")
+	email := flags.String("email", "", "email of the account")
+	password := flags.String("password", "", "password (default: generated)")
+	groupID := flags.Int("group", 0, "make the account the owner of this group")
+	flags.Parse(args)
+
+	if *username == "" {
+		return errors.New("-username is required")
+	}
+	if err := connect(); err != nil {
+		return err
+	}
+	userRepo := repositories.NewUserRepository(database.DB)
+	authService := services.NewAuthService(userRepo)
+
+	// An existing account is promoted
+	existing, err := userRepo.GetByUsername(*username)
+	if err != nil {
+		if *email == "" {
+			return errors.New("-email is required")
+		}
+		if _, err := mail.ParseAddress(*email); err != nil {
+			return fmt.Errorf("invalid email: %w", err)
+		}
+		secret, generated, err := generatedPassword(*password)
+		if err != nil {
+			return err
+		}
+		if err := authService.RegisterUser(services.RegisterInput{
+			Username: *username,
+			Email:    *email,
+			Password: secret,
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("created account %s\n", *username)
+		if generated {
+			fmt.Printf("password: %s\n", secret)
+		}
+		if existing, err = userRepo.GetByUsername(*username); err != nil {
+			return err
+		}
+	} else if existing.DeletedAt != nil {
+		return errors.New("user account is deleted")
+	} else {
+		fmt.Printf("using existing account %s (id %d)\n", existing.Username, existing.UserID)
+	}
+	if err := userRepo.SetAdmin(existing.UserID, true); err != nil {
+		return err
+	}
+	utils.Logger.WithField("username", *username).Info("Site administrator role granted by operator")
+	fmt.Printf("%s is a site administrator\n", *username)
+
+	if *groupID == 0 {
+		return nil
+	}
+	groupRepo := repositories.NewGroupRepository(database.DB, userRepo)
+	ownershipService := services.NewGroupOwnershipService(
+		repositories.NewGroupOwnershipRepository(database.DB),
+		groupRepo,
+		repositories.NewGroupUserRepository(database.DB),
+		userRepo,
+	)
+	if err := ownershipService.AssignOwner(int32(*groupID), *username); err != nil {
+		return err
+	}
+	fmt.Printf("%s now owns group %d\n", *username, *groupID)
+	return nil
+}
+
+func runResetPassword(args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	username := flags.String("username", "", "username of the account")
+	password := flags.String("password", "", "new password (default: generated)")
+	flags.Parse(args)
+
+	if *username == "" {
+		return errors.New("-username is required")
+	}
+	secret, generated, err := generatedPassword(*password)
+	if err != nil {
+		return err
+	}
+	if err := connect(); err != nil {
+		return err
+	}
+	authService := services.NewAuthService(repositories.NewUserRepository(database.DB))
+	if err := authService.ResetPassword(*username, secret); err != nil {
+		return err
+	}
+	utils.Logger.WithField("username", *username).Info("Password reset by operator")
+	fmt.Printf("password of %s reset\n", *username)
+	if generated {
+		fmt.Printf("password: %s\n", secret)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "", "output file (default: export-<timestamp>.json)")
+	tables := flags.String("tables", "", "comma-separated tables to export (default: all)")
+	flags.Parse(args)
+
+	var names []string
+	for _, table := range strings.Split(*tables, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			names = append(names, table)
+		}
+	}
+	path := *out
+	if path == "" {
+		path = "export-" + time.Now().Format("20060102-150405") + ".json"
+	}
+	if err := connect(); err != nil {
+		return err
+	}
+
+	// The export holds password hashes and tokens
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	results, err := database.Export(database.DB, file, names)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	total := 0
+	for _, result := range results {
+		total += result.Rows
+	}
+	fmt.Printf("exported %d rows from %d tables to %s\n", total, len(results), path)
+	return nil
+}
+
+func runCheckConfig(args []string) error {
+	flags := flag.NewFlagSet("check-config", flag.ExitOnError)
+	flags.Parse(args)
+
+	var problems []string
+	report := func(ok bool, what, detail string) {
+		state := "ok  "
+		if !ok {
+			state = "FAIL"
+			problems = append(problems, what)
+		}
+		fmt.Printf("%s %-28s %s\n", state, what, detail)
+	}
+
+	config, err := database.LoadConfig(database.ConfigPath)
+	if err == nil {
+		err = config.Validate()
+	}
+	if err != nil {
+		report(false, database.ConfigPath, strings.ReplaceAll(err.Error(), "\n", "; "))
+	} else {
+		report(true, database.ConfigPath, fmt.Sprintf("%s@%s:%d/%s", config.User, config.Host, config.Port, config.DBName))
+	}
+
+	if value := os.Getenv("APPLICATION_EXPIRY_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		report(err == nil && days > 0, "APPLICATION_EXPIRY_DAYS", value)
+	}
+	if value := os.Getenv("SEMESTER_STARTS"); value != "" {
+		_, err := services.ParseSemesterStarts(value)
+		report(err == nil, "SEMESTER_STARTS", value)
+	}
+	if value := os.Getenv("TELEGRAM_DIGEST_HOUR"); value != "" {
+		hour, err := strconv.Atoi(value)
+		report(err == nil && hour >= 0 && hour < 24, "TELEGRAM_DIGEST_HOUR", value)
+	}
+	if value := strings.ToLower(os.Getenv("LOG_LEVEL")); value != "" {
+		switch value {
+		case "trace", "debug", "info", "warn", "error":
+			report(true, "LOG_LEVEL", value)
+		default:
+			report(false, "LOG_LEVEL", value+" (expected trace, debug, info, warn or error)")
+		}
+	}
+	if value := strings.ToLower(os.Getenv("LOG_FORMAT")); value != "" {
+		report(value == "text" || value == "json", "LOG_FORMAT", value)
+	}
+	telegramConfig := telegram.LoadConfig()
+	if telegramConfig.Enabled() {
+		detail := "enabled as @" + telegramConfig.BotUsername
+		if telegramConfig.BotUsername == "" {
+			detail = "enabled, TELEGRAM_BOT_USERNAME not set so link codes come without a deep link"
+		}
+		report(true, "telegram", detail)
+	} else {
+		report(true, "telegram", "disabled (TELEGRAM_BOT_TOKEN not set)")
+	}
+
+	if err == nil {
+		if err := connect(); err != nil {
+			report(false, "database", err.Error())
+		} else if sqlDB, err := database.DB.DB(); err != nil {
+			report(false, "database", err.Error())
+		} else if err := sqlDB.Ping(); err != nil {
+			report(false, "database", err.Error())
+		} else {
+			report(true, "database", "reachable")
+			if statuses, err := database.MigrationStatuses(database.DB); err != nil {
+				report(false, "migrations", err.Error())
+			} else {
+				pending, modified := 0, 0
+				for _, status := range statuses {
+					if !status.Applied {
+						pending++
+					}
+					if status.Modified {
+						modified++
+					}
+				}
+				report(modified == 0, "migrations", fmt.Sprintf("%d pending, %d modified", pending, modified))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s): %s", len(problems), strings.Join(problems, ", "))
+	}
+	return nil
+}
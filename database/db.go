@@ -3,11 +3,11 @@ package database
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
-	"space/models"
 	"space/utils"
 	"strings"
 
@@ -19,6 +19,9 @@ import (
 
 var DB *gorm.DB
 
+// ConfigPath is where the database settings are read from
+const ConfigPath = "./config/config.json"
+
 type Config struct {
 	Host     string `json:"host"`
 	User     string `json:"user"`
@@ -44,6 +47,29 @@ func LoadConfig(filepath string) (Config, error) {
 	return config, nil
 }
 
+// Validate reports every missing or malformed setting at once
+func (c Config) Validate() error {
+	var problems []error
+	if c.Host == "" {
+		problems = append(problems, errors.New("host is required"))
+	}
+	if c.User == "" {
+		problems = append(problems, errors.New("user is required"))
+	}
+	if c.DBName == "" {
+		problems = append(problems, errors.New("dbname is required"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		problems = append(problems, fmt.Errorf("port %d is out of range", c.Port))
+	}
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		problems = append(problems, fmt.Errorf("sslmode %q is not a valid PostgreSQL sslmode", c.SSLMode))
+	}
+	return errors.Join(problems...)
+}
+
 func ensureDatabaseExists(config Config) error {
 	// Connect to PostgreSQL server without specifying a database
 	serverDSN := fmt.Sprintf(
@@ -82,8 +108,9 @@ func quoteIdentifier(name string) string {
 	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
 
-func ConnectDatabase() error {
-	config, err := LoadConfig("./config/config.json")
+// Connect opens the database from ./config/config.json without touching the schema
+func Connect() error {
+	config, err := LoadConfig(ConfigPath)
 	if err != nil {
 		utils.Logger.WithField("error", err).Error("Failed to load .env file")
 		return fmt.Errorf("error loading config: %w", err)
@@ -114,20 +141,15 @@ func ConnectDatabase() error {
 	// if err := ensureDatabaseExists(config); err != nil {
 	// 	return err
 	// }
-	// Автоматическая миграция всех таблиц
-	err = DB.AutoMigrate(
-		&models.User{},          // No dependencies
-		&models.AcademicGroup{}, // No dependencies
-		&models.Group{},         // Depends on User, AcademicGroup
-		&models.GroupModer{},    // Depends on Group, User
-		&models.GroupUser{},     // Depends on Group, User
-		&models.Subject{},       // Depends on Group
-		&models.Task{},          // Depends on Subject, User
-		&models.Material{},      // Depends on Subject, User
-		&models.TimeSlot{},      // No dependencies
-		&models.Schedule{},      // Depends on AcademicGroup, Subject, TimeSlot
-		&models.GroupApplication{},
-	)
+	return nil
+}
+
+// ConnectDatabase connects and applies the pending schema migrations
+func ConnectDatabase() error {
+	if err := Connect(); err != nil {
+		return err
+	}
+	applied, err := MigrateUp(DB, 0)
 	if err != nil {
 		utils.Logger.
 			WithError(err).
@@ -136,8 +158,9 @@ func ConnectDatabase() error {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 	utils.Logger.WithFields(logrus.Fields{
-		"event":  "database_startup",
-		"status": "success",
+		"event":    "database_startup",
+		"status":   "success",
+		"migrated": len(applied),
 	}).Info("Database connected and migrated successfully")
 	log.Println("Database connected and migrated successfully.")
 	return nil
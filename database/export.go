@@ -0,0 +1,104 @@
+package database
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportResult is the number of rows written for one table
+type ExportResult struct {
+	Table string
+	Rows  int
+}
+
+// ExportTables lists the tables of the current schema in name order
+func ExportTables(db *gorm.DB) ([]string, error) {
+	var tables []string
+	err := db.Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`).Scan(&tables).Error
+	return tables, err
+}
+
+// Export writes the rows of the given tables, or of every table when none are given, as one
+// JSON document: {"exported_at", "schema_version", "tables": {"<table>": [rows]}}. All tables
+// are read from the same snapshot. Rows are written as stored, password hashes and tokens
+// included, so the output must be kept private.
+func Export(db *gorm.DB, w io.Writer, tables []string) ([]ExportResult, error) {
+	known, err := ExportTables(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	if len(tables) == 0 {
+		tables = known
+	} else {
+		exists := make(map[string]bool, len(known))
+		for _, table := range known {
+			exists[table] = true
+		}
+		for _, table := range tables {
+			if !exists[table] {
+				return nil, fmt.Errorf("unknown table %q", table)
+			}
+		}
+	}
+
+	var results []ExportResult
+	out := bufio.NewWriter(w)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var version int
+		if tx.Migrator().HasTable(&schemaMigration{}) {
+			if err := tx.Raw("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version).Error; err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "{\n  \"exported_at\": %q,\n  \"schema_version\": %d,\n  \"tables\": {", time.Now().UTC().Format(time.RFC3339), version)
+
+		for i, table := range tables {
+			name, _ := json.Marshal(table)
+			if i > 0 {
+				out.WriteString(",")
+			}
+			fmt.Fprintf(out, "\n    %s: [", name)
+			rows, err := tx.Raw("SELECT row_to_json(t)::text FROM " + quoteIdentifier(table) + " t").Rows()
+			if err != nil {
+				return fmt.Errorf("failed to read table %s: %w", table, err)
+			}
+			count := 0
+			for rows.Next() {
+				var row string
+				if err := rows.Scan(&row); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to read table %s: %w", table, err)
+				}
+				if count > 0 {
+					out.WriteString(",")
+				}
+				out.WriteString("\n      " + row)
+				count++
+			}
+			if err := rows.Err(); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to read table %s: %w", table, err)
+			}
+			rows.Close()
+			if count > 0 {
+				out.WriteString("\n    ")
+			}
+			out.WriteString("]")
+			results = append(results, ExportResult{Table: table, Rows: count})
+		}
+		out.WriteString("\n  }\n}\n")
+		return out.Flush()
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,445 @@
+package database
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"space/models"
+	"space/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// embeddedMigrations are the versioned schema migrations shipped with the binary
+//
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockID serializes migrations across instances (pg_advisory_lock key)
+const migrationLockID = 72200
+
+// baselineVersion is the migration capturing the schema AutoMigrate used to create
+const baselineVersion = 1
+
+// migrationFilePattern matches migration scripts such as 0001_baseline.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+
+var (
+	ErrMigrationModified = errors.New("applied migration was modified")
+	ErrMigrationNoDown   = errors.New("migration has no down script")
+	// ErrBaselineRevert guards the baseline's down script, which drops every table
+	ErrBaselineRevert = errors.New("reverting the baseline drops every table")
+)
+
+// Migration is one schema version: the up script applies it, the down script reverts it.
+// The checksum covers the up script only.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus reports whether a migration is applied and whether its up script still
+// matches what was applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool
+}
+
+// schemaMigration is a row of schema_migrations
+type schemaMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+const createSchemaMigrationsSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version integer PRIMARY KEY,
+	name varchar(255) NOT NULL,
+	checksum varchar(64) NOT NULL,
+	applied_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// LoadMigrations reads the embedded migrations in version order
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, m[2], version)
+		}
+		data, err := fs.ReadFile(embeddedMigrations, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		if m[3] == "up" {
+			sum := sha256.Sum256(data)
+			migration.Up, migration.Checksum = string(data), hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// withMigrationLock runs fn on a single connection holding the migration advisory lock, so
+// instances starting together apply each migration exactly once. schema_migrations is
+// created under the lock if missing.
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+
+		if err := conn.Exec(createSchemaMigrationsSQL).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func appliedMigrations(db *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrateUp applies the pending migrations up to target, or all of them when target is 0,
+// each in its own transaction. It refuses to run when an applied migration was edited.
+func MigrateUp(db *gorm.DB, target int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			adopted, err := adoptLegacySchema(conn, migrations)
+			if err != nil {
+				return err
+			}
+			if adopted {
+				if applied, err = appliedMigrations(conn); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range migrations {
+			if row, ok := applied[migration.Version]; ok {
+				if row.Checksum != migration.Checksum {
+					return fmt.Errorf("%w: %04d_%s", ErrMigrationModified, migration.Version, migration.Name)
+				}
+				continue
+			}
+			if target > 0 && migration.Version > target {
+				break
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				utils.Logger.WithFields(logrus.Fields{
+					"error":     err,
+					"version":   migration.Version,
+					"migration": migration.Name,
+				}).Error("Failed to apply migration")
+				return fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			utils.Logger.WithFields(logrus.Fields{
+				"version":   migration.Version,
+				"migration": migration.Name,
+			}).Info("Migration applied")
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first. It refuses to revert
+// the baseline unless includeBaseline is set, before reverting anything.
+func MigrateDown(db *gorm.DB, steps int, includeBaseline bool) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+	var done []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		var rows []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if row.Version == baselineVersion && !includeBaseline {
+				return fmt.Errorf("%w: %04d_%s", ErrBaselineRevert, row.Version, row.Name)
+			}
+		}
+		for _, row := range rows {
+			migration, ok := byVersion[row.Version]
+			if !ok || migration.Down == "" {
+				return fmt.Errorf("%w: %04d_%s", ErrMigrationNoDown, row.Version, row.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Where("version = ?", row.Version).Delete(&schemaMigration{}).Error
+			})
+			if err != nil {
+				utils.Logger.WithFields(logrus.Fields{
+					"error":     err,
+					"version":   migration.Version,
+					"migration": migration.Name,
+				}).Error("Failed to revert migration")
+				return fmt.Errorf("failed to revert migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			utils.Logger.WithFields(logrus.Fields{
+				"version":   migration.Version,
+				"migration": migration.Name,
+			}).Info("Migration reverted")
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatuses lists every known migration with its state, plus applied versions
+// that are missing from the binary (reported with Modified set)
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration)
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		if applied, err = appliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied, status.AppliedAt = true, &appliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   row.Version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Modified:  true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// baselineCheckSchema is the scratch schema the baseline is built in to compare a legacy
+// schema against; it never outlives the adoption transaction
+const baselineCheckSchema = "baseline_check"
+
+// describeSchemaSQL lists the columns, indexes and constraints of a schema, keyed by object,
+// with definitions that only differ when the objects do
+const describeSchemaSQL = `
+	SELECT 'column ' || c.relname || '.' || a.attname AS object,
+		format_type(a.atttypid, a.atttypmod) ||
+		CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END ||
+		COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '') AS definition
+	FROM pg_attribute a
+	JOIN pg_class c ON c.oid = a.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	WHERE n.nspname = ? AND c.relkind = 'r' AND c.relname <> 'schema_migrations'
+		AND a.attnum > 0 AND NOT a.attisdropped
+	UNION ALL
+	SELECT 'index ' || tablename || '.' || indexname, indexdef
+	FROM pg_indexes
+	WHERE schemaname = ? AND tablename <> 'schema_migrations'
+	UNION ALL
+	SELECT 'constraint ' || c.relname || '.' || con.conname, pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	JOIN pg_class c ON c.oid = con.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = ? AND c.relname <> 'schema_migrations'`
+
+var ErrLegacySchemaMismatch = errors.New("existing schema doesn't match the baseline migration")
+
+// errDiscardBaseline rolls back the scratch copy of the baseline once it has been described
+var errDiscardBaseline = errors.New("discard baseline copy")
+
+// describeSchema maps each object of the schema to its definition, with the schema's own
+// name stripped so that two schemas can be compared
+func describeSchema(tx *gorm.DB, schema string) (map[string]string, error) {
+	var rows []struct {
+		Object     string
+		Definition string
+	}
+	if err := tx.Raw(describeSchemaSQL, schema, schema, schema).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	objects := make(map[string]string, len(rows))
+	for _, row := range rows {
+		objects[row.Object] = strings.ReplaceAll(row.Definition, schema+".", "")
+	}
+	return objects, nil
+}
+
+// compareWithBaseline builds the baseline in a scratch schema, compares the current schema
+// with it and rolls the scratch schema back. It returns one line per difference.
+func compareWithBaseline(tx *gorm.DB, baseline Migration) ([]string, error) {
+	var current string
+	if err := tx.Raw("SELECT current_schema()").Scan(&current).Error; err != nil {
+		return nil, err
+	}
+	var existing, expected map[string]string
+	err := tx.Transaction(func(scratch *gorm.DB) error {
+		if err := scratch.Exec(`CREATE SCHEMA "` + baselineCheckSchema + `"`).Error; err != nil {
+			return err
+		}
+		if err := scratch.Exec(`SET LOCAL search_path TO "` + baselineCheckSchema + `"`).Error; err != nil {
+			return err
+		}
+		if err := scratch.Exec(baseline.Up).Error; err != nil {
+			return fmt.Errorf("failed to build the baseline: %w", err)
+		}
+		var err error
+		if existing, err = describeSchema(scratch, current); err != nil {
+			return err
+		}
+		if expected, err = describeSchema(scratch, baselineCheckSchema); err != nil {
+			return err
+		}
+		return errDiscardBaseline
+	})
+	if !errors.Is(err, errDiscardBaseline) {
+		return nil, err
+	}
+
+	var diffs []string
+	for object, definition := range expected {
+		actual, ok := existing[object]
+		switch {
+		case !ok:
+			diffs = append(diffs, "missing "+object+": "+definition)
+		case actual != definition:
+			diffs = append(diffs, "changed "+object+": "+actual+", expected "+definition)
+		}
+	}
+	for object, definition := range existing {
+		if _, ok := expected[object]; !ok {
+			diffs = append(diffs, "unexpected "+object+": "+definition)
+		}
+	}
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// adoptLegacySchema handles databases created by AutoMigrate before schema_migrations
+// existed. Their schema must match the baseline exactly, otherwise adoption fails listing
+// the differences and nothing is changed. The baseline is recorded as applied instead of
+// being run; the later migrations then add every feature and backfill its data. Fresh
+// databases are left to the baseline migration.
+func adoptLegacySchema(db *gorm.DB, migrations []Migration) (bool, error) {
+	if !db.Migrator().HasTable(&models.User{}) {
+		return false, nil
+	}
+	if len(migrations) == 0 || migrations[0].Version != baselineVersion {
+		return false, fmt.Errorf("baseline migration %04d is missing", baselineVersion)
+	}
+	baseline := migrations[0]
+	utils.Logger.Info("Existing schema without schema_migrations found, adopting it as the baseline")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		diffs, err := compareWithBaseline(tx, baseline)
+		if err != nil {
+			return err
+		}
+		if len(diffs) > 0 {
+			return fmt.Errorf("%w (%04d_%s), bring it in line and retry:\n  %s",
+				ErrLegacySchemaMismatch, baseline.Version, baseline.Name, strings.Join(diffs, "\n  "))
+		}
+		return tx.Create(&schemaMigration{
+			Version:   baseline.Version,
+			Name:      baseline.Name,
+			Checksum:  baseline.Checksum,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
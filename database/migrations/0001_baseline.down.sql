@@ -0,0 +1,12 @@
+-- Drops every table of the baseline, and all data with it
+DROP TABLE IF EXISTS "group_applications" CASCADE;
+DROP TABLE IF EXISTS "schedules" CASCADE;
+DROP TABLE IF EXISTS "time_slots" CASCADE;
+DROP TABLE IF EXISTS "materials" CASCADE;
+DROP TABLE IF EXISTS "tasks" CASCADE;
+DROP TABLE IF EXISTS "subjects" CASCADE;
+DROP TABLE IF EXISTS "group_users" CASCADE;
+DROP TABLE IF EXISTS "group_moders" CASCADE;
+DROP TABLE IF EXISTS "groups" CASCADE;
+DROP TABLE IF EXISTS "academic_groups" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
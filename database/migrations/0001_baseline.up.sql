@@ -0,0 +1,130 @@
+-- Baseline: the schema as GORM AutoMigrate left it before versioned migrations were
+-- introduced. Existing databases without schema_migrations are compared against this file
+-- and adopted instead of running it (see database/migrate.go); every later feature is a
+-- migration of its own, so adopted databases get them and their backfills like any other.
+
+CREATE TABLE "users" (
+    "user_id" serial,
+    "username" varchar(255) NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "email" varchar(255) NOT NULL,
+    "hash_password" varchar(255) NOT NULL,
+    PRIMARY KEY ("user_id")
+);
+
+CREATE TABLE "academic_groups" (
+    "academic_group_id" serial,
+    "name" varchar(255) NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("academic_group_id")
+);
+
+CREATE TABLE "groups" (
+    "id" serial,
+    "name" varchar(255),
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "academic_group_id" integer,
+    "admin_id" integer,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_groups_academic_group" FOREIGN KEY ("academic_group_id") REFERENCES "academic_groups"("academic_group_id"),
+    CONSTRAINT "fk_groups_admin" FOREIGN KEY ("admin_id") REFERENCES "users"("user_id")
+);
+CREATE INDEX "idx_groups_admin_id" ON "groups" ("admin_id");
+CREATE INDEX "idx_groups_academic_group_id" ON "groups" ("academic_group_id");
+
+CREATE TABLE "group_moders" (
+    "group_id" integer,
+    "user_id" integer,
+    "created_at" timestamptz,
+    PRIMARY KEY ("group_id","user_id"),
+    CONSTRAINT "fk_group_moders_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_moders_user" FOREIGN KEY ("user_id") REFERENCES "users"("user_id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "group_users" (
+    "group_id" integer,
+    "user_id" integer,
+    "role" varchar(50) DEFAULT 'member',
+    "joined_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("group_id","user_id"),
+    CONSTRAINT "fk_group_users_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_users_user" FOREIGN KEY ("user_id") REFERENCES "users"("user_id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "subjects" (
+    "subject_id" serial,
+    "academic_group_id" integer,
+    "name" varchar(255) NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("subject_id"),
+    CONSTRAINT "fk_subjects_academic_group" FOREIGN KEY ("academic_group_id") REFERENCES "academic_groups"("academic_group_id")
+);
+
+CREATE TABLE "tasks" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "title" text NOT NULL,
+    "description" text,
+    "is_verified" boolean DEFAULT false,
+    "subject_id" integer,
+    "deadline" timestamp,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_tasks_user" FOREIGN KEY ("user_id") REFERENCES "users"("user_id"),
+    CONSTRAINT "fk_tasks_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id"),
+    CONSTRAINT "fk_tasks_subject" FOREIGN KEY ("subject_id") REFERENCES "subjects"("subject_id")
+);
+
+CREATE TABLE "materials" (
+    "material_id" serial,
+    "subject_id" integer,
+    "title" varchar(255) NOT NULL,
+    "content" text,
+    "created_by" integer,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("material_id"),
+    CONSTRAINT "fk_materials_subject" FOREIGN KEY ("subject_id") REFERENCES "subjects"("subject_id"),
+    CONSTRAINT "fk_materials_creator" FOREIGN KEY ("created_by") REFERENCES "users"("user_id")
+);
+
+-- AutoMigrate created the slot times as timestamptz although the model asked for time;
+-- 0004_time_slot_times converts them
+CREATE TABLE "time_slots" (
+    "slot_id" serial,
+    "slot_number" integer,
+    "start_time" timestamptz NOT NULL,
+    "end_time" timestamptz NOT NULL,
+    PRIMARY KEY ("slot_id"),
+    CONSTRAINT "uni_time_slots_slot_number" UNIQUE ("slot_number"),
+    CONSTRAINT "chk_time_slots_slot_number" CHECK (slot_number BETWEEN 1 AND 9)
+);
+
+CREATE TABLE "schedules" (
+    "schedule_id" serial,
+    "group_id" integer,
+    "subject_id" integer,
+    "teacher_initials" varchar(50),
+    "classroom" varchar(50),
+    "time_slot_id" integer,
+    "date" date NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("schedule_id"),
+    CONSTRAINT "fk_schedules_time_slot" FOREIGN KEY ("time_slot_id") REFERENCES "time_slots"("slot_id"),
+    CONSTRAINT "fk_schedules_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id"),
+    CONSTRAINT "fk_schedules_subject" FOREIGN KEY ("subject_id") REFERENCES "subjects"("subject_id")
+);
+
+CREATE TABLE "group_applications" (
+    "application_id" serial,
+    "group_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "message" text,
+    "status" varchar(50) DEFAULT 'pending',
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("application_id"),
+    CONSTRAINT "fk_group_applications_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id"),
+    CONSTRAINT "fk_group_applications_user" FOREIGN KEY ("user_id") REFERENCES "users"("user_id")
+);
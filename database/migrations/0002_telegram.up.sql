@@ -0,0 +1,24 @@
+-- Telegram bot: account links with notification settings, and the reminders already sent
+CREATE TABLE "telegram_links" (
+    "id" serial,
+    "user_id" integer NOT NULL,
+    "chat_id" bigint,
+    "link_code" varchar(16),
+    "code_expires_at" timestamptz,
+    "linked_at" timestamptz,
+    "digest_enabled" boolean DEFAULT true,
+    "reminders_enabled" boolean DEFAULT true,
+    "last_digest_at" timestamptz,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_telegram_links_link_code" ON "telegram_links" ("link_code");
+CREATE INDEX "idx_telegram_links_chat_id" ON "telegram_links" ("chat_id");
+CREATE UNIQUE INDEX "idx_telegram_links_user_id" ON "telegram_links" ("user_id");
+
+CREATE TABLE "telegram_reminders" (
+    "task_id" integer,
+    "user_id" integer,
+    "sent_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("task_id","user_id")
+);
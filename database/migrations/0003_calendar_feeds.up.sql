@@ -0,0 +1,14 @@
+-- Tokenized iCalendar feeds of task deadlines, per user or per group
+CREATE TABLE "calendar_feeds" (
+    "id" serial,
+    "user_id" integer NOT NULL,
+    "group_id" integer,
+    "token" varchar(64) NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "last_used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_calendar_feeds_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX "idx_calendar_feeds_token" ON "calendar_feeds" ("token");
+CREATE INDEX "idx_calendar_feeds_group_id" ON "calendar_feeds" ("group_id");
+CREATE INDEX "idx_calendar_feeds_user_id" ON "calendar_feeds" ("user_id");
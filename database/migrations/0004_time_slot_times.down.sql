@@ -0,0 +1,4 @@
+-- The dates are lost; slots come back on 1 January 2000 in the session time zone
+ALTER TABLE "time_slots"
+    ALTER COLUMN "start_time" TYPE timestamptz USING '2000-01-01'::date + "start_time",
+    ALTER COLUMN "end_time" TYPE timestamptz USING '2000-01-01'::date + "end_time";
@@ -0,0 +1,4 @@
+-- Time slots are times of day; AutoMigrate created them as timestamptz
+ALTER TABLE "time_slots"
+    ALTER COLUMN "start_time" TYPE time USING "start_time"::time,
+    ALTER COLUMN "end_time" TYPE time USING "end_time"::time;
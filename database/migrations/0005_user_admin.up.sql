@@ -0,0 +1,2 @@
+-- Site administrators manage the data shared by all groups: time slots, teachers and rooms
+ALTER TABLE "users" ADD COLUMN "is_admin" boolean NOT NULL DEFAULT false;
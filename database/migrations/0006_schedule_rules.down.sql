@@ -0,0 +1,3 @@
+ALTER TABLE "schedules" DROP COLUMN IF EXISTS "rule_id";
+DROP TABLE IF EXISTS "schedule_rule_exceptions";
+DROP TABLE IF EXISTS "schedule_rules";
@@ -0,0 +1,34 @@
+-- Recurring lessons with week parity, their cancelled dates, and the lessons they generate
+CREATE TABLE "schedule_rules" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "subject_id" integer NOT NULL,
+    "time_slot_id" integer NOT NULL,
+    "weekday" integer NOT NULL,
+    "week_parity" varchar(10) NOT NULL DEFAULT 'all',
+    "semester_start" date NOT NULL,
+    "semester_end" date NOT NULL,
+    "teacher_initials" varchar(50),
+    "classroom" varchar(50),
+    "created_by" integer,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_schedule_rules_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_schedule_rules_time_slot" FOREIGN KEY ("time_slot_id") REFERENCES "time_slots"("slot_id"),
+    CONSTRAINT "chk_schedule_rules_weekday" CHECK (weekday BETWEEN 1 AND 7)
+);
+CREATE INDEX "idx_schedule_rules_group_id" ON "schedule_rules" ("group_id");
+
+CREATE TABLE "schedule_rule_exceptions" (
+    "id" serial,
+    "rule_id" integer NOT NULL,
+    "date" date NOT NULL,
+    "reason" text,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_schedule_rules_exceptions" FOREIGN KEY ("rule_id") REFERENCES "schedule_rules"("id")
+);
+CREATE UNIQUE INDEX "idx_rule_exception_date" ON "schedule_rule_exceptions" ("rule_id","date");
+
+ALTER TABLE "schedules" ADD COLUMN "rule_id" integer;
+CREATE INDEX "idx_schedules_rule_id" ON "schedules" ("rule_id");
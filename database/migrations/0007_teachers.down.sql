@@ -0,0 +1,4 @@
+ALTER TABLE "schedule_rules" DROP COLUMN IF EXISTS "teacher_id";
+ALTER TABLE "schedules" DROP COLUMN IF EXISTS "teacher_id";
+ALTER TABLE "subjects" DROP COLUMN IF EXISTS "teacher_id";
+DROP TABLE IF EXISTS "teachers";
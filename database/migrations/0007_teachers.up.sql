@@ -0,0 +1,50 @@
+-- Teachers as entities of their own, linked to subjects, lessons and schedule rules
+CREATE TABLE "teachers" (
+    "id" serial,
+    "full_name" varchar(255),
+    "initials" varchar(50) NOT NULL,
+    "email" varchar(255),
+    "department" varchar(255),
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_teachers_initials" ON "teachers" ("initials");
+
+ALTER TABLE "subjects" ADD COLUMN "teacher_id" integer;
+CREATE INDEX "idx_subjects_teacher_id" ON "subjects" ("teacher_id");
+ALTER TABLE "schedules" ADD COLUMN "teacher_id" integer;
+CREATE INDEX "idx_schedules_teacher_id" ON "schedules" ("teacher_id");
+ALTER TABLE "schedule_rules" ADD COLUMN "teacher_id" integer;
+CREATE INDEX "idx_schedule_rules_teacher_id" ON "schedule_rules" ("teacher_id");
+
+-- Backfill: one teacher per distinct spelling of the free-text initials, ignoring case, spaces
+-- and dots (utils.InitialsKey). The most used spelling becomes the teacher's initials.
+CREATE TEMPORARY TABLE "teacher_spellings" ON COMMIT DROP AS
+SELECT teacher_initials AS spelling,
+    lower(regexp_replace(teacher_initials, '[[:space:].]', '', 'g')) AS key,
+    SUM(n) AS uses
+FROM (
+    SELECT teacher_initials, COUNT(*) AS n FROM schedules
+    WHERE trim(teacher_initials) <> '' GROUP BY teacher_initials
+    UNION ALL
+    SELECT teacher_initials, COUNT(*) AS n FROM schedule_rules
+    WHERE trim(teacher_initials) <> '' GROUP BY teacher_initials
+) t
+GROUP BY teacher_initials;
+
+INSERT INTO "teachers" ("initials")
+SELECT initials FROM (
+    SELECT DISTINCT ON (key) key, regexp_replace(trim(spelling), '[[:space:]]+', ' ', 'g') AS initials
+    FROM teacher_spellings
+    ORDER BY key, uses DESC, spelling
+) t
+ORDER BY initials;
+
+UPDATE "schedules" s SET "teacher_id" = t.id
+FROM teacher_spellings sp, teachers t
+WHERE s.teacher_initials = sp.spelling
+    AND lower(regexp_replace(t.initials, '[[:space:].]', '', 'g')) = sp.key;
+UPDATE "schedule_rules" r SET "teacher_id" = t.id
+FROM teacher_spellings sp, teachers t
+WHERE r.teacher_initials = sp.spelling
+    AND lower(regexp_replace(t.initials, '[[:space:].]', '', 'g')) = sp.key;
@@ -0,0 +1,4 @@
+ALTER TABLE "schedule_rules" DROP COLUMN IF EXISTS "room_id";
+ALTER TABLE "schedules" DROP COLUMN IF EXISTS "room_id";
+DROP TABLE IF EXISTS "room_equipments";
+DROP TABLE IF EXISTS "rooms";
@@ -0,0 +1,23 @@
+-- Rooms registry with equipment tags, linked to lessons and schedule rules
+CREATE TABLE "rooms" (
+    "id" serial,
+    "building" varchar(100),
+    "number" varchar(50) NOT NULL,
+    "code" varchar(150) NOT NULL,
+    "capacity" integer NOT NULL DEFAULT 0,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_rooms_code" ON "rooms" ("code");
+
+CREATE TABLE "room_equipments" (
+    "room_id" integer,
+    "tag" varchar(50),
+    PRIMARY KEY ("room_id","tag"),
+    CONSTRAINT "fk_rooms_equipment" FOREIGN KEY ("room_id") REFERENCES "rooms"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+ALTER TABLE "schedules" ADD COLUMN "room_id" integer;
+CREATE INDEX "idx_schedules_room_id" ON "schedules" ("room_id");
+ALTER TABLE "schedule_rules" ADD COLUMN "room_id" integer;
+CREATE INDEX "idx_schedule_rules_room_id" ON "schedule_rules" ("room_id");
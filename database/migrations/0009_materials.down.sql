@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "material_attachments";
+ALTER TABLE "materials"
+    DROP COLUMN IF EXISTS "group_id",
+    DROP COLUMN IF EXISTS "position",
+    DROP COLUMN IF EXISTS "updated_at";
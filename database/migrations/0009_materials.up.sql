@@ -0,0 +1,20 @@
+-- Group materials with ordering and file attachments; materials without a group are shared
+ALTER TABLE "materials"
+    ADD COLUMN "group_id" integer,
+    ADD COLUMN "position" integer NOT NULL DEFAULT 0,
+    ADD COLUMN "updated_at" timestamptz;
+CREATE INDEX "idx_materials_group_id" ON "materials" ("group_id");
+
+CREATE TABLE "material_attachments" (
+    "id" serial,
+    "material_id" integer NOT NULL,
+    "file_name" varchar(255) NOT NULL,
+    "content_type" varchar(255),
+    "size" bigint NOT NULL,
+    "data" bytea,
+    "uploaded_by" integer,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_materials_attachments" FOREIGN KEY ("material_id") REFERENCES "materials"("material_id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "idx_material_attachments_material_id" ON "material_attachments" ("material_id");
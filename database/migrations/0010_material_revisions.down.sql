@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "material_revisions";
+ALTER TABLE "materials" DROP COLUMN IF EXISTS "version";
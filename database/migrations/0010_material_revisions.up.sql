@@ -0,0 +1,24 @@
+-- Material revisions for diffs and rollbacks
+ALTER TABLE "materials" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
+
+CREATE TABLE "material_revisions" (
+    "id" serial,
+    "material_id" integer NOT NULL,
+    "version" integer NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "content" text,
+    "edited_by" integer NOT NULL,
+    "comment" varchar(255),
+    "restored_from" integer,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_material_revisions_editor" FOREIGN KEY ("edited_by") REFERENCES "users"("user_id")
+);
+CREATE UNIQUE INDEX "idx_material_revision" ON "material_revisions" ("material_id","version");
+
+-- Backfill: the current text of existing materials becomes their first revision. Materials
+-- without an author have no one to attribute it to and start their history at the next edit.
+INSERT INTO "material_revisions" ("material_id", "version", "title", "content", "edited_by", "created_at")
+SELECT material_id, version, title, content, created_by, created_at
+FROM materials
+WHERE created_by IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_groups_search";
+DROP INDEX IF EXISTS "idx_subjects_search";
+DROP INDEX IF EXISTS "idx_materials_search";
+DROP INDEX IF EXISTS "idx_tasks_search";
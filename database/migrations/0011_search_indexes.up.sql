@@ -0,0 +1,5 @@
+-- Full-text search (/api/search); the expressions must match repositories.SearchDocuments
+CREATE INDEX "idx_tasks_search" ON "tasks" USING GIN (to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, '')));
+CREATE INDEX "idx_materials_search" ON "materials" USING GIN (to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(content, '')));
+CREATE INDEX "idx_subjects_search" ON "subjects" USING GIN (to_tsvector('russian', coalesce(name, '')));
+CREATE INDEX "idx_groups_search" ON "groups" USING GIN (to_tsvector('russian', coalesce(name, '')));
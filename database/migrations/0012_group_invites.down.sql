@@ -0,0 +1,3 @@
+ALTER TABLE "group_applications" DROP COLUMN IF EXISTS "invite_id";
+DROP TABLE IF EXISTS "group_invite_uses";
+DROP TABLE IF EXISTS "group_invites";
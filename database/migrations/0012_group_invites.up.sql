@@ -0,0 +1,35 @@
+-- Group invite links and join codes, their redemptions, and the applications they created
+CREATE TABLE "group_invites" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "code" varchar(32) NOT NULL,
+    "created_by" integer NOT NULL,
+    "auto_approve" boolean NOT NULL DEFAULT false,
+    "expires_at" timestamptz,
+    "max_uses" integer,
+    "use_count" integer NOT NULL DEFAULT 0,
+    "revoked_at" timestamptz,
+    "revoked_by" integer,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_group_invites_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_invites_creator" FOREIGN KEY ("created_by") REFERENCES "users"("user_id")
+);
+CREATE UNIQUE INDEX "idx_group_invites_code" ON "group_invites" ("code");
+CREATE INDEX "idx_group_invites_group_id" ON "group_invites" ("group_id");
+
+CREATE TABLE "group_invite_uses" (
+    "id" serial,
+    "invite_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "result" varchar(20) NOT NULL,
+    "application_id" integer,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_group_invite_uses_invite" FOREIGN KEY ("invite_id") REFERENCES "group_invites"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "idx_group_invite_uses_user_id" ON "group_invite_uses" ("user_id");
+CREATE INDEX "idx_group_invite_uses_invite_id" ON "group_invite_uses" ("invite_id");
+
+ALTER TABLE "group_applications" ADD COLUMN "invite_id" integer;
+CREATE INDEX "idx_group_applications_invite_id" ON "group_applications" ("invite_id");
@@ -0,0 +1,5 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "academic_group_id";
+ALTER TABLE "groups"
+    DROP COLUMN IF EXISTS "visibility",
+    DROP COLUMN IF EXISTS "join_policy",
+    DROP COLUMN IF EXISTS "same_academic_group_only";
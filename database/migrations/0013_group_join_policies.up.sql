@@ -0,0 +1,10 @@
+-- Group visibility and join policies
+ALTER TABLE "groups"
+    ADD COLUMN "visibility" varchar(20) NOT NULL DEFAULT 'public',
+    ADD COLUMN "join_policy" varchar(20) NOT NULL DEFAULT 'application',
+    ADD COLUMN "same_academic_group_only" boolean NOT NULL DEFAULT false;
+
+-- The verified academic group of a user, recorded by a site administrator's roster import
+ALTER TABLE "users" ADD COLUMN "academic_group_id" integer
+    REFERENCES "academic_groups"("academic_group_id") ON DELETE SET NULL;
+CREATE INDEX "idx_users_academic_group_id" ON "users" ("academic_group_id");
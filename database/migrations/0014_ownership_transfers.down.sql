@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "group_ownership_transfers";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,19 @@
+-- Group ownership transfers, and soft-deleted accounts whose groups get a successor
+ALTER TABLE "users" ADD COLUMN "deleted_at" timestamptz;
+
+CREATE TABLE "group_ownership_transfers" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "from_user_id" integer NOT NULL,
+    "to_user_id" integer NOT NULL,
+    "reason" varchar(20) NOT NULL DEFAULT 'transfer',
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "responded_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_group_ownership_transfers_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_ownership_transfers_from_user" FOREIGN KEY ("from_user_id") REFERENCES "users"("user_id"),
+    CONSTRAINT "fk_group_ownership_transfers_to_user" FOREIGN KEY ("to_user_id") REFERENCES "users"("user_id")
+);
+CREATE INDEX "idx_group_ownership_transfers_to_user_id" ON "group_ownership_transfers" ("to_user_id");
+CREATE INDEX "idx_group_ownership_transfers_group_id" ON "group_ownership_transfers" ("group_id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "group_membership_events";
+DROP TABLE IF EXISTS "group_bans";
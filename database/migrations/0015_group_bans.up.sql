@@ -0,0 +1,30 @@
+-- Group bans and the log of members leaving, kicked, banned and unbanned
+CREATE TABLE "group_bans" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "reason" text,
+    "banned_by" integer NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "lifted_at" timestamptz,
+    "lifted_by" integer,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_group_bans_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_bans_moderator" FOREIGN KEY ("banned_by") REFERENCES "users"("user_id")
+);
+CREATE INDEX "idx_group_bans_user_id" ON "group_bans" ("user_id");
+CREATE INDEX "idx_group_bans_group_id" ON "group_bans" ("group_id");
+
+CREATE TABLE "group_membership_events" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "actor_id" integer NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "reason" text,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_group_membership_events_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_group_membership_events_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("user_id")
+);
+CREATE INDEX "idx_group_membership_events_group_id" ON "group_membership_events" ("group_id");
@@ -0,0 +1 @@
+ALTER TABLE "group_moders" DROP COLUMN IF EXISTS "granted_by";
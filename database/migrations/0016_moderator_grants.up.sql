@@ -0,0 +1,2 @@
+-- Who granted a moderator role; unknown for moderators from before
+ALTER TABLE "group_moders" ADD COLUMN "granted_by" integer;
@@ -0,0 +1,17 @@
+-- Group audit log
+CREATE TABLE "audit_events" (
+    "id" serial,
+    "group_id" integer NOT NULL,
+    "actor_id" integer NOT NULL,
+    "action" varchar(50) NOT NULL,
+    "target_type" varchar(30) NOT NULL,
+    "target_id" integer NOT NULL,
+    "before" jsonb,
+    "after" jsonb,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_audit_events_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("user_id")
+);
+CREATE INDEX "idx_audit_events_action" ON "audit_events" ("action");
+CREATE INDEX "idx_audit_events_actor_id" ON "audit_events" ("actor_id");
+CREATE INDEX "idx_audit_group_created" ON "audit_events" ("group_id","created_at");
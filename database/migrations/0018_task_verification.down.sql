@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "task_verification_decisions";
+DROP TABLE IF EXISTS "task_votes";
+DROP TABLE IF EXISTS "group_verification_policies";
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "verification_status";
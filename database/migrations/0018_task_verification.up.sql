@@ -0,0 +1,46 @@
+-- Task verification by a moderator or by vote: per-group policies, votes and decisions
+ALTER TABLE "tasks" ADD COLUMN "verification_status" varchar(20) NOT NULL DEFAULT 'pending';
+
+CREATE TABLE "group_verification_policies" (
+    "group_id" serial,
+    "mode" varchar(20) NOT NULL DEFAULT 'moderator',
+    "voters" varchar(20) NOT NULL DEFAULT 'moderators',
+    "quorum" bigint NOT NULL DEFAULT 3,
+    "threshold" bigint NOT NULL DEFAULT 60,
+    "updated_by" integer,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("group_id"),
+    CONSTRAINT "fk_group_verification_policies_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "task_votes" (
+    "task_id" integer,
+    "user_id" integer,
+    "vote" varchar(10) NOT NULL,
+    "reason" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("task_id","user_id"),
+    CONSTRAINT "fk_task_votes_task" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_task_votes_user" FOREIGN KEY ("user_id") REFERENCES "users"("user_id")
+);
+
+CREATE TABLE "task_verification_decisions" (
+    "id" serial,
+    "task_id" integer NOT NULL,
+    "previous_status" varchar(20) NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "method" varchar(20) NOT NULL,
+    "decided_by" integer NOT NULL,
+    "legit" bigint NOT NULL DEFAULT 0,
+    "fake" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_task_verification_decisions_decider" FOREIGN KEY ("decided_by") REFERENCES "users"("user_id"),
+    CONSTRAINT "fk_task_verification_decisions_task" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "idx_task_verification_decisions_task_id" ON "task_verification_decisions" ("task_id");
+
+-- Backfill: tasks verified through the old flag are verified. Unverified ones stay pending
+-- since the flag didn't tell them apart from rejected ones.
+UPDATE "tasks" SET "verification_status" = 'verified' WHERE "is_verified";
@@ -0,0 +1 @@
+ALTER TABLE "group_verification_policies" DROP COLUMN IF EXISTS "auto_verify_reputation";
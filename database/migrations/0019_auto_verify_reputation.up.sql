@@ -0,0 +1,2 @@
+-- New tasks from authors with at least this reputation are verified right away; 0 turns it off
+ALTER TABLE "group_verification_policies" ADD COLUMN "auto_verify_reputation" bigint NOT NULL DEFAULT 0;
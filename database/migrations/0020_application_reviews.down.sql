@@ -0,0 +1,5 @@
+ALTER TABLE "group_applications"
+    DROP COLUMN IF EXISTS "reviewed_by",
+    DROP COLUMN IF EXISTS "reviewed_at",
+    DROP COLUMN IF EXISTS "review_note",
+    DROP COLUMN IF EXISTS "closed_at";
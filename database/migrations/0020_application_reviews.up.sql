@@ -0,0 +1,7 @@
+-- Application history: who reviewed an application, when and why, and when it was closed
+ALTER TABLE "group_applications"
+    ADD COLUMN "reviewed_by" integer,
+    ADD COLUMN "reviewed_at" timestamptz,
+    ADD COLUMN "review_note" text,
+    ADD COLUMN "closed_at" timestamptz,
+    ADD CONSTRAINT "fk_group_applications_reviewer" FOREIGN KEY ("reviewed_by") REFERENCES "users"("user_id");
@@ -0,0 +1,10 @@
+-- Per-group rules approving applications automatically
+CREATE TABLE "group_approval_rules" (
+    "group_id" serial,
+    "same_academic_group" boolean NOT NULL DEFAULT false,
+    "invited_by_member" boolean NOT NULL DEFAULT false,
+    "updated_by" integer,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("group_id"),
+    CONSTRAINT "fk_group_approval_rules_group" FOREIGN KEY ("group_id") REFERENCES "groups"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "full_name",
+    DROP COLUMN IF EXISTS "student_id";
@@ -0,0 +1,5 @@
+-- Roster imports match accounts by student ID and record full names
+ALTER TABLE "users"
+    ADD COLUMN "full_name" varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN "student_id" varchar(64);
+CREATE UNIQUE INDEX "idx_users_student_id" ON "users" ("student_id");
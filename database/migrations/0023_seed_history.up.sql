@@ -0,0 +1,11 @@
+-- Seed files already applied, so that seeding is idempotent
+CREATE TABLE "seed_history" (
+    "id" serial,
+    "version" bigint NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "checksum" varchar(64) NOT NULL,
+    "rows" bigint NOT NULL DEFAULT 0,
+    "applied_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_seed_history_version" ON "seed_history" ("version");
@@ -1,131 +1,232 @@
 package database
 
 import (
-	"log"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
 	"space/models"
-	"space/repositories"
 	"space/utils"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
-func SeedAcademicGroups(db *gorm.DB, repo *repositories.AcademicGroupRepository) error {
-	// Flush academic_groups table
-	if err := DB.Exec("DELETE FROM academic_groups").Error; err != nil {
-		log.Fatalf("Failed to flush database: %v", err)
-	}
-	utils.Logger.WithFields(logrus.Fields{
-		"action": "database_flush",
-		"status": "success",
-	}).Info("Database flushed successfully")
-	log.Println("Database flushed successfully.")
+// embeddedSeeds are the seed files shipped with the binary
+//
+//go:embed seeds/*
+var embeddedSeeds embed.FS
 
-	groups := []models.AcademicGroup{
-		{AcademicGroupID: 1, Name: "ЭФМО-01-24", CreatedAt: time.Now()},
-		{AcademicGroupID: 2, Name: "ИКБО-14-20", CreatedAt: time.Now()},
-		{AcademicGroupID: 3, Name: "ИКБО-15-20", CreatedAt: time.Now()},
-	}
+// seedLockID serializes seeding across instances (pg_advisory_xact_lock key)
+const seedLockID = 72201
 
-	for _, ac_group := range groups {
-		if err := DB.Create(&ac_group).Error; err != nil {
-			utils.Logger.WithFields(logrus.Fields{
-				"error":      err,
-				"group_id":   ac_group.AcademicGroupID,
-				"group_name": ac_group.Name,
-				"operation":  "seed_academic_group",
-			}).Error("Failed to seed academic group")
-			log.Printf("Failed to seed academic group: %v", err)
-		}
-	}
-	// Successful seeding
-	utils.Logger.WithFields(logrus.Fields{
-		"event":  "data_seeding",
-		"entity": "academic_groups",
-		"count":  len(groups),
-		// "duration_ms": time.Since(startTime).Milliseconds(),
-	}).Info("Academic groups seeded successfully")
-	log.Println("Academic groups seeded.")
-	return nil
+// seedFilePattern matches versioned seed files such as 001_academic_groups.yaml
+var seedFilePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_-]+)\.(ya?ml|json)$`)
+
+// SeedSet is the content of one seed file. Academic groups are matched by name and subjects
+// by name within their academic group, so applying a file again never duplicates rows.
+type SeedSet struct {
+	AcademicGroups []SeedAcademicGroup `yaml:"academic_groups" json:"academic_groups"`
+	Subjects       []SeedSubject       `yaml:"subjects" json:"subjects"`
 }
 
-func SeedSubjects(db *gorm.DB, subjectRepo *repositories.SubjectRepository, academicGroupRepo *repositories.AcademicGroupRepository) error {
-	// Fetch existing academic groups
-	var academicGroups []models.AcademicGroup
-	if err := db.Find(&academicGroups).Error; err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Failed to fetch academic groups for seeding subjects")
-		return err
-	}
+type SeedAcademicGroup struct {
+	Name string `yaml:"name" json:"name"`
+}
 
-	if len(academicGroups) == 0 {
-		utils.Logger.Warn("No academic groups found for seeding subjects, setting AcademicGroupID to NULL")
-	}
+type SeedSubject struct {
+	Name          string `yaml:"name" json:"name"`
+	AcademicGroup string `yaml:"academic_group" json:"academic_group"`
+}
 
-	subjects := []models.Subject{
-		{
-			Name: "Mathematics",
+// SeedResult reports what happened to one seed file: applied, or skipped when the same
+// version and checksum were applied before
+type SeedResult struct {
+	Version  int
+	Name     string
+	Checksum string
+	Status   string
+	Rows     int
+}
 
-			AcademicGroupID: getAcademicGroupID(academicGroups, 0),
-		},
-		{
-			Name: "Physics",
+type seedFile struct {
+	version  int
+	name     string
+	format   string
+	data     []byte
+	checksum string
+}
 
-			AcademicGroupID: getAcademicGroupID(academicGroups, 1),
-		},
-		{
-			Name: "Computer Science",
+// loadSeedFiles reads the versioned seed files of the directory in version order
+func loadSeedFiles(fsys fs.FS, dir string) ([]seedFile, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed directory: %w", err)
+	}
+	var files []seedFile
+	versions := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := seedFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("seed files %s and %s share version %d", other, entry.Name(), version)
+		}
+		versions[version] = entry.Name()
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read seed file %s: %w", entry.Name(), err)
+		}
+		sum := sha256.Sum256(data)
+		files = append(files, seedFile{
+			version:  version,
+			name:     entry.Name(),
+			format:   m[3],
+			data:     data,
+			checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+	return files, nil
+}
 
-			AcademicGroupID: getAcademicGroupID(academicGroups, 0),
-		},
-		{
-			Name: "English Literature",
+func (f seedFile) parse() (SeedSet, error) {
+	var set SeedSet
+	var err error
+	if f.format == "json" {
+		err = json.Unmarshal(f.data, &set)
+	} else {
+		err = yaml.Unmarshal(f.data, &set)
+	}
+	if err != nil {
+		return SeedSet{}, fmt.Errorf("failed to parse seed file %s: %w", f.name, err)
+	}
+	for _, group := range set.AcademicGroups {
+		if strings.TrimSpace(group.Name) == "" {
+			return SeedSet{}, fmt.Errorf("seed file %s: academic group without a name", f.name)
+		}
+	}
+	for _, subject := range set.Subjects {
+		if strings.TrimSpace(subject.Name) == "" || strings.TrimSpace(subject.AcademicGroup) == "" {
+			return SeedSet{}, fmt.Errorf("seed file %s: subjects need a name and an academic group", f.name)
+		}
+	}
+	return set, nil
+}
 
-			AcademicGroupID: getAcademicGroupID(academicGroups, 1),
-		},
-		{
-			Name: "History",
+// apply upserts the seed set in the caller's transaction and returns how many rows it created
+func (set SeedSet) apply(tx *gorm.DB) (int, error) {
+	rows := 0
+	// academic groups used to be seeded with explicit IDs, which left the sequence behind
+	if err := tx.Exec(`SELECT setval(pg_get_serial_sequence('academic_groups', 'academic_group_id'),
+		GREATEST((SELECT COALESCE(MAX(academic_group_id), 0) FROM academic_groups), 1))`).Error; err != nil {
+		return 0, err
+	}
+	for _, seed := range set.AcademicGroups {
+		group := models.AcademicGroup{Name: strings.TrimSpace(seed.Name)}
+		result := tx.Where("name = ?", group.Name).FirstOrCreate(&group)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		rows += int(result.RowsAffected)
+	}
+	for _, seed := range set.Subjects {
+		var group models.AcademicGroup
+		if err := tx.Where("name = ?", strings.TrimSpace(seed.AcademicGroup)).First(&group).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, fmt.Errorf("subject %q refers to unknown academic group %q", seed.Name, seed.AcademicGroup)
+			}
+			return 0, err
+		}
+		subject := models.Subject{AcademicGroupID: group.AcademicGroupID, Name: strings.TrimSpace(seed.Name)}
+		result := tx.Omit("AcademicGroup").
+			Where("academic_group_id = ? AND name = ?", subject.AcademicGroupID, subject.Name).
+			FirstOrCreate(&subject)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		rows += int(result.RowsAffected)
+	}
+	return rows, nil
+}
 
-			AcademicGroupID: getAcademicGroupID(academicGroups, 0),
-		},
+// Seed applies the seed files from dir, or the embedded ones when dir is empty. Files whose
+// version was already applied with the same checksum are skipped; new or changed files are
+// upserted and recorded in seed_history, each in its own transaction. Nothing is ever
+// deleted.
+func Seed(db *gorm.DB, dir string) ([]SeedResult, error) {
+	var fsys fs.FS = embeddedSeeds
+	root := "seeds"
+	if dir != "" {
+		fsys, root = os.DirFS(dir), "."
+	}
+	files, err := loadSeedFiles(fsys, root)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, subject := range subjects {
-		existing, err := subjectRepo.FindByName(subject.Name)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			utils.Logger.WithFields(logrus.Fields{
-				"error": err,
-				"name":  subject.Name,
-			}).Error("Failed to check existing subject")
-			return err
+	var results []SeedResult
+	for _, file := range files {
+		set, err := file.parse()
+		if err != nil {
+			return results, err
 		}
-		if existing == nil {
-			if err := subjectRepo.Create(&subject); err != nil {
-				utils.Logger.WithFields(logrus.Fields{
-					"error": err,
-					"name":  subject.Name,
-				}).Error("Failed to seed subject")
+		result := SeedResult{Version: file.version, Name: file.name, Checksum: file.checksum, Status: "skipped"}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", seedLockID).Error; err != nil {
 				return err
 			}
+			var applied int64
+			if err := tx.Model(&models.SeedHistory{}).
+				Where("version = ? AND checksum = ?", file.version, file.checksum).
+				Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+			rows, err := set.apply(tx)
+			if err != nil {
+				return err
+			}
+			result.Status, result.Rows = "applied", rows
+			return tx.Create(&models.SeedHistory{
+				Version:  file.version,
+				Name:     file.name,
+				Checksum: file.checksum,
+				Rows:     rows,
+			}).Error
+		})
+		if err != nil {
 			utils.Logger.WithFields(logrus.Fields{
-				"name":              subject.Name,
-				"academic_group_id": subject.AcademicGroupID,
-			}).Info("Seeded subject")
-		} else {
-			utils.Logger.WithFields(logrus.Fields{
-				"name": subject.Name,
-			}).Debug("Subject already exists, skipping")
+				"error":   err,
+				"version": file.version,
+				"file":    file.name,
+			}).Error("Failed to apply seed file")
+			return results, fmt.Errorf("failed to apply seed file %s: %w", file.name, err)
 		}
+		utils.Logger.WithFields(logrus.Fields{
+			"version": file.version,
+			"file":    file.name,
+			"status":  result.Status,
+			"rows":    result.Rows,
+		}).Info("Seed file processed")
+		results = append(results, result)
 	}
-	return nil
-}
-
-// Helper function to get AcademicGroupID or 0 (NULL) if index is out of bounds
-func getAcademicGroupID(groups []models.AcademicGroup, index int) int32 {
-	if index < len(groups) {
-		return groups[index].AcademicGroupID
-	}
-	return 0 // Maps to NULL in database
+	return results, nil
 }
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"space/auth"
 	"space/database"
 	"space/repositories"
 	"space/routes"
 	"space/services"
+	"space/telegram"
 	"space/utils"
+	"strings"
 
 	_ "space/docs"
 
@@ -35,9 +40,41 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if cmd.run == nil {
+		printUsage()
+		return
+	}
+
 	utils.Init()
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// serve starts the HTTP server, applying pending migrations first
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	seed := flags.Bool("seed", false, "apply new or changed seed files before starting the server")
+	seedDir := flags.String("seed-dir", "", "directory with seed files (default: the seeds built into the binary)")
+	flags.Parse(args)
+
 	utils.Logger.Info("Starting application")
-	err := database.ConnectDatabase()
+	semesters, err := services.SemesterCalendarFromEnv()
+	if err != nil {
+		utils.Logger.WithField("error", err).Fatal("Invalid SEMESTER_STARTS")
+	}
+	err = database.ConnectDatabase()
 	if err != nil {
 		utils.Logger.WithField("error", err).Fatal("Failed to connect to database")
 		// log.Fatalf("failed to connect to database: %v", err)
@@ -59,38 +96,116 @@ func main() {
 	groupModerHandler := routes.NewGroupModerHandler(groupModerService)
 
 	groupRepo := repositories.NewGroupRepository(database.DB, userRepo)
-	groupService := services.NewGroupService(groupRepo, userRepo, groupuserRepo, groupModerRepo)
+	reputationRepo := repositories.NewReputationRepository(database.DB)
+	auditRepo := repositories.NewAuditRepository(database.DB)
+	auditService := services.NewAuditService(auditRepo, groupRepo, userRepo)
+	auditHandler := routes.NewAuditHandler(auditService)
+
+	groupService := services.NewGroupService(groupRepo, userRepo, groupuserRepo, groupModerRepo, reputationRepo, auditService)
 	groupHandler := routes.NewGroupHandler(groupService)
 
 	subjectRepo := repositories.NewSubjectRepository(database.DB)
-	subjectService := services.NewSubjectService(subjectRepo, groupRepo, userRepo)
+	teacherRepo := repositories.NewTeacherRepository(database.DB)
+	subjectService := services.NewSubjectService(subjectRepo, groupRepo, userRepo, teacherRepo)
 	subjectHandler := routes.NewSubjectHandler(subjectService)
 
 	taskRepo := repositories.NewTaskRepository(database.DB)
-	taskService := services.NewTaskService(taskRepo)
+	verificationRepo := repositories.NewTaskVerificationRepository(database.DB)
+	taskService := services.NewTaskService(taskRepo, verificationRepo, reputationRepo, auditService)
 	taskHandler := routes.NewTaskHandler(taskService, groupService, subjectService)
 
 	groupUserRepo := repositories.NewGroupUserRepository(database.DB)
 	groupUserService := services.NewGroupUserService(groupUserRepo)
 	groupUserHandler := routes.NewGroupUserHandler(groupUserService)
 
+	membershipRepo := repositories.NewGroupMembershipRepository(database.DB)
+	membershipService := services.NewGroupMembershipService(membershipRepo, groupRepo, groupModerRepo, userRepo, auditService)
+	membershipHandler := routes.NewGroupMembershipHandler(membershipService)
+
+	verificationService := services.NewTaskVerificationService(verificationRepo, taskRepo, groupRepo, groupUserRepo, userRepo, reputationRepo, auditService)
+	verificationHandler := routes.NewTaskVerificationHandler(verificationService)
+
 	appRepo := repositories.NewGroupApplicationRepository(database.DB)
-	appService := services.NewGroupApplicationService(appRepo, groupRepo, groupModerRepo, userRepo, groupUserRepo)
+	appService := services.NewGroupApplicationService(appRepo, groupRepo, groupModerRepo, userRepo, groupUserRepo, membershipRepo, auditService,
+		services.ApplicationExpiryFromEnv())
 	appHandler := routes.NewGroupApplicationHandler(appService)
 
+	telegramConfig := telegram.LoadConfig()
+	telegramRepo := repositories.NewTelegramRepository(database.DB)
+	telegramService := services.NewTelegramService(telegramRepo, userRepo, groupRepo, taskService,
+		telegram.NewClient(telegramConfig.APIURL, telegramConfig.Token), telegramConfig)
+	telegramHandler := routes.NewTelegramHandler(telegramService)
+
+	calendarRepo := repositories.NewCalendarRepository(database.DB)
+	calendarService := services.NewCalendarService(calendarRepo, taskRepo, groupRepo, groupUserRepo, userRepo)
+	calendarHandler := routes.NewCalendarHandler(calendarService)
+
+	scheduleRepo := repositories.NewScheduleRepository(database.DB)
+	roomRepo := repositories.NewRoomRepository(database.DB)
+	scheduleService := services.NewScheduleService(scheduleRepo, groupRepo, groupUserRepo, subjectRepo, teacherRepo, roomRepo, userRepo, semesters)
+	scheduleHandler := routes.NewScheduleHandler(scheduleService)
+
+	teacherService := services.NewTeacherService(teacherRepo, scheduleService)
+	teacherHandler := routes.NewTeacherHandler(teacherService)
+	roomService := services.NewRoomService(roomRepo, scheduleRepo, scheduleService)
+	roomHandler := routes.NewRoomHandler(roomService)
+
+	scheduleRuleRepo := repositories.NewScheduleRuleRepository(database.DB)
+	scheduleRuleService := services.NewScheduleRuleService(scheduleRuleRepo, groupRepo, scheduleService)
+	scheduleRuleHandler := routes.NewScheduleRuleHandler(scheduleRuleService)
+
+	timetableImportService := services.NewTimetableImportService(scheduleRepo, subjectRepo, groupRepo, scheduleService)
+	timetableImportHandler := routes.NewTimetableImportHandler(timetableImportService)
+
+	materialRepo := repositories.NewMaterialRepository(database.DB)
+	materialService := services.NewMaterialService(materialRepo, subjectRepo, groupRepo, groupUserRepo, userRepo)
+	materialHandler := routes.NewMaterialHandler(materialService)
+
+	searchRepo := repositories.NewSearchRepository(database.DB)
+	searchService := services.NewSearchService(searchRepo, userRepo)
+	searchHandler := routes.NewSearchHandler(searchService)
+
+	inviteRepo := repositories.NewGroupInviteRepository(database.DB)
+	inviteService := services.NewGroupInviteService(inviteRepo, groupRepo, groupUserRepo, userRepo, appService)
+	inviteHandler := routes.NewGroupInviteHandler(inviteService)
+
+	rosterRepo := repositories.NewRosterRepository(database.DB)
+	rosterService := services.NewRosterImportService(rosterRepo, groupRepo, userRepo, auditService)
+	rosterHandler := routes.NewRosterHandler(rosterService)
+
+	ownershipRepo := repositories.NewGroupOwnershipRepository(database.DB)
+	ownershipService := services.NewGroupOwnershipService(ownershipRepo, groupRepo, groupUserRepo, userRepo)
+	ownershipHandler := routes.NewGroupOwnershipHandler(ownershipService)
+
 	// Seed database
 
-	if err := database.SeedAcademicGroups(database.DB, academicGroupRepo); err != nil {
-		log.Fatalf("failed to seed academic groups: %v", err)
+	// Seed database, only when asked to with -seed
+	if *seed {
+		if _, err := database.Seed(database.DB, *seedDir); err != nil {
+			log.Fatalf("failed to seed database: %v", err)
+		}
 	}
-	if err := database.SeedSubjects(database.DB, subjectRepo, academicGroupRepo); err != nil {
-		log.Fatalf("failed to seed academic groups: %v", err)
+
+	// Expire stale group applications
+	expiryStop := make(chan struct{})
+	defer close(expiryStop)
+	go appService.RunExpiry(expiryStop)
+
+	// Telegram bot
+	if telegramConfig.Enabled() {
+		stop := make(chan struct{})
+		defer close(stop)
+		go telegramService.Run(stop)
+		go telegramService.RunScheduler(stop)
+	} else {
+		utils.Logger.Info("TELEGRAM_BOT_TOKEN not set, telegram bot disabled")
 	}
 
 	// Public routes
 	router.POST("/login", routes.LoginHandler(authService))
 	router.POST("/register", routes.RegisterHandler(authService))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/calendar/:token", calendarHandler.GetFeed) // token in URL, calendar apps can't send headers
 	router.GET("/hello", func(c *gin.Context) {
 		c.String(http.StatusOK, "Hello, World!")
 	})
@@ -109,6 +224,9 @@ func main() {
 			tasks.DELETE("/:id", taskHandler.DeleteTask)
 			// protected.PATCH("/tasks/:id", taskHandler.UpdateTask)
 			tasks.PATCH("/:id/verify", taskHandler.VerifyTask)
+			tasks.GET("/:id/verification", verificationHandler.GetTaskVerification)
+			tasks.POST("/:id/votes", verificationHandler.VoteOnTask)
+			tasks.DELETE("/:id/votes", verificationHandler.RetractVote)
 		}
 		// Group endpoints
 		groups := protected.Group("/groups")
@@ -122,6 +240,38 @@ func main() {
 			groups.GET("/my-groups", groupHandler.GetUserGroups)
 			groups.GET("/:id/subjects/:subject_id/tasks", taskHandler.GetTasksBySubject)
 			groups.GET("/:id/subjects", taskHandler.GetSubjectsByGroup)
+			groups.GET("/:id/schedule", scheduleHandler.GetGroupSchedule)
+			groups.POST("/:id/schedule", scheduleHandler.CreateScheduleEntry)
+			groups.POST("/:id/schedule/import", timetableImportHandler.ImportTimetable)
+			groups.GET("/:id/schedule-rules", scheduleRuleHandler.GetGroupRules)
+			groups.POST("/:id/schedule-rules", scheduleRuleHandler.CreateRule)
+			groups.GET("/:id/invites", inviteHandler.ListInvites)
+			groups.POST("/:id/invites", inviteHandler.CreateInvite)
+			groups.DELETE("/:id/invites/:invite_id", inviteHandler.RevokeInvite)
+			groups.GET("/:id/invites/:invite_id/uses", inviteHandler.GetInviteUses)
+			groups.GET("/transfers/incoming", ownershipHandler.GetIncomingTransfers)
+			groups.GET("/:id/transfer", ownershipHandler.GetTransfers)
+			groups.POST("/:id/transfer", ownershipHandler.ProposeTransfer)
+			groups.DELETE("/:id/transfer", ownershipHandler.CancelTransfer)
+			groups.POST("/:id/transfer/accept", ownershipHandler.AcceptTransfer)
+			groups.POST("/:id/transfer/decline", ownershipHandler.DeclineTransfer)
+			groups.POST("/:id/leave", membershipHandler.LeaveGroup)
+			groups.POST("/:id/members/:user_id/kick", membershipHandler.KickMember)
+			groups.GET("/:id/bans", membershipHandler.GetBans)
+			groups.POST("/:id/bans", membershipHandler.BanUser)
+			groups.DELETE("/:id/bans/:user_id", membershipHandler.UnbanUser)
+			groups.GET("/:id/membership-log", membershipHandler.GetMembershipEvents)
+			groups.GET("/:id/moderators", groupHandler.GetGroupModerators)
+			groups.POST("/:id/moderators", membershipHandler.AddModerator)
+			groups.DELETE("/:id/moderators/:user_id", membershipHandler.RemoveModerator)
+			groups.PATCH("/:id/members/:user_id/role", membershipHandler.SetMemberRole)
+			groups.GET("/:id/audit", auditHandler.GetAuditEvents)
+			groups.GET("/:id/verification-policy", verificationHandler.GetVerificationPolicy)
+			groups.PATCH("/:id/verification-policy", verificationHandler.UpdateVerificationPolicy)
+			groups.GET("/:id/leaderboard", verificationHandler.GetLeaderboard)
+			groups.GET("/:id/approval-rules", appHandler.GetApprovalRules)
+			groups.PATCH("/:id/approval-rules", appHandler.UpdateApprovalRules)
+			groups.POST("/:id/roster/import", rosterHandler.ImportRoster)
 		}
 
 		// Subject endpoints
@@ -132,15 +282,16 @@ func main() {
 			subjects.PATCH("/:id", subjectHandler.UpdateSubject)
 			subjects.DELETE("/:id", subjectHandler.DeleteSubject)
 			subjects.GET("/my-groups", taskHandler.GetUserSubjects)
+			subjects.GET("/:id/materials", materialHandler.ListMaterials)
+			subjects.POST("/:id/materials", materialHandler.CreateMaterial)
+			subjects.PUT("/:id/materials/order", materialHandler.ReorderMaterials)
 		}
 
 		// GroupUser endpoints
 		groupuser := protected.Group("/group-users")
 		{
 			groupuser.GET("/:group_id/:user_id", groupUserHandler.GetGroupUser)
-			groupuser.POST("", groupUserHandler.CreateGroupUser)
-			groupuser.PATCH("/:group_id/:user_id", groupUserHandler.UpdateGroupUser)
-			groupuser.DELETE("/:group_id/:user_id", groupUserHandler.DeleteGroupUser)
+			groupuser.DELETE("/:group_id/:user_id", membershipHandler.RemoveGroupUser)
 		}
 		// AcademicGroup endpoints
 		academicgroups := protected.Group("/academic-groups")
@@ -156,17 +307,98 @@ func main() {
 		groumoders := protected.Group("/group-moders")
 		{
 			groumoders.GET("/:group_id/:user_id", groupModerHandler.GetGroupModer)
-			groumoders.POST("", groupModerHandler.CreateGroupModer)
-			groumoders.DELETE("/:group_id/:user_id", groupModerHandler.DeleteGroupModer)
 		}
 		// Applications
 		applications := protected.Group("/groups/applications")
 		{
 			applications.POST("", appHandler.CreateApplication)
 			applications.GET("/pending", appHandler.GetPendingApplications)
+			applications.GET("/mine", appHandler.GetMyApplications)
 			applications.PATCH("/review/:id", appHandler.ReviewApplication)
+			applications.POST("/review", appHandler.BulkReviewApplications)
+			applications.POST("/:id/withdraw", appHandler.WithdrawApplication)
+		}
+		// Invites
+		invites := protected.Group("/invites")
+		{
+			invites.GET("/:code", inviteHandler.PreviewInvite)
+			invites.POST("/:code/accept", inviteHandler.AcceptInvite)
+		}
+		// Telegram
+		telegramRoutes := protected.Group("/telegram")
+		{
+			telegramRoutes.GET("", telegramHandler.GetStatus)
+			telegramRoutes.PATCH("", telegramHandler.UpdateSettings)
+			telegramRoutes.DELETE("", telegramHandler.Unlink)
+			telegramRoutes.POST("/link-code", telegramHandler.CreateLinkCode)
+		}
+		// Calendar feeds
+		calendar := protected.Group("/calendar/feeds")
+		{
+			calendar.GET("", calendarHandler.ListFeeds)
+			calendar.POST("", calendarHandler.CreateFeed)
+			calendar.DELETE("/:id", calendarHandler.RevokeFeed)
+		}
+		// Schedule
+		schedule := protected.Group("/schedule")
+		{
+			schedule.GET("/my", scheduleHandler.GetMySchedule)
+			schedule.GET("/conflicts", scheduleHandler.GetScheduleConflicts)
+			schedule.GET("/:id", scheduleHandler.GetScheduleEntry)
+			schedule.PATCH("/:id", scheduleHandler.UpdateScheduleEntry)
+			schedule.DELETE("/:id", scheduleHandler.DeleteScheduleEntry)
+		}
+		timeSlots := protected.Group("/time-slots")
+		{
+			timeSlots.GET("", scheduleHandler.GetTimeSlots)
+			timeSlots.POST("", scheduleHandler.CreateTimeSlot)
+			timeSlots.PATCH("/:id", scheduleHandler.UpdateTimeSlot)
+			timeSlots.DELETE("/:id", scheduleHandler.DeleteTimeSlot)
+		}
+		teachers := protected.Group("/teachers")
+		{
+			teachers.GET("", teacherHandler.ListTeachers)
+			teachers.POST("", teacherHandler.CreateTeacher)
+			teachers.GET("/:id", teacherHandler.GetTeacher)
+			teachers.PATCH("/:id", teacherHandler.UpdateTeacher)
+			teachers.DELETE("/:id", teacherHandler.DeleteTeacher)
+			teachers.GET("/:id/schedule", teacherHandler.GetTeacherSchedule)
+		}
+
+		protected.GET("/search", searchHandler.Search)
+		protected.DELETE("/account", ownershipHandler.DeleteAccount)
+
+		materials := protected.Group("/materials")
+		{
+			materials.GET("/:id", materialHandler.GetMaterial)
+			materials.PATCH("/:id", materialHandler.UpdateMaterial)
+			materials.DELETE("/:id", materialHandler.DeactivateMaterial)
+			materials.POST("/:id/attachments", materialHandler.UploadAttachment)
+			materials.GET("/:id/attachments/:attachment_id", materialHandler.DownloadAttachment)
+			materials.DELETE("/:id/attachments/:attachment_id", materialHandler.DeleteAttachment)
+			materials.GET("/:id/revisions", materialHandler.GetRevisions)
+			materials.GET("/:id/revisions/:version", materialHandler.GetRevision)
+			materials.POST("/:id/revisions/:version/restore", materialHandler.RestoreRevision)
+			materials.GET("/:id/diff", materialHandler.DiffRevisions)
+		}
+
+		rooms := protected.Group("/rooms")
+		{
+			rooms.GET("", roomHandler.ListRooms)
+			rooms.POST("", roomHandler.CreateRoom)
+			rooms.GET("/free", roomHandler.FindFreeRooms)
+			rooms.GET("/:id", roomHandler.GetRoom)
+			rooms.PATCH("/:id", roomHandler.UpdateRoom)
+			rooms.DELETE("/:id", roomHandler.DeleteRoom)
+		}
+		scheduleRules := protected.Group("/schedule-rules")
+		{
+			scheduleRules.PATCH("/:id", scheduleRuleHandler.UpdateRule)
+			scheduleRules.DELETE("/:id", scheduleRuleHandler.DeleteRule)
+			scheduleRules.POST("/:id/exceptions", scheduleRuleHandler.AddException)
+			scheduleRules.DELETE("/:id/exceptions/:date", scheduleRuleHandler.RemoveException)
 		}
 	}
 
-	router.Run(":8080")
+	return router.Run(":8080")
 }
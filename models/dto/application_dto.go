@@ -9,24 +9,104 @@ type CreateApplicationRequest struct {
 	GroupID int32  `json:"group_id" binding:"required"`
 	Message string `json:"message"`
 }
+
+// ReviewApplicationRequest is a moderator's decision on a pending application
+type ReviewApplicationRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
 type GroupApplicationDTO struct {
-	ApplicationID int32     `json:"application_id"`
-	GroupID       int32     `json:"group_id"`
-	UserID        int32     `json:"user_id"`
-	Username      string    `json:"username"`
-	Message       string    `json:"message"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
+	ApplicationID    int32      `json:"application_id"`
+	GroupID          int32      `json:"group_id"`
+	GroupName        string     `json:"group_name"`
+	UserID           int32      `json:"user_id"`
+	Username         string     `json:"username"`
+	Message          string     `json:"message"`
+	Status           string     `json:"status"`
+	InviteID         *int32     `json:"invite_id,omitempty"`
+	ReviewedBy       *int32     `json:"reviewed_by,omitempty"`
+	ReviewerUsername string     `json:"reviewer_username,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"` // only while pending
+	CreatedAt        time.Time  `json:"created_at"`
 }
 
-func ToGroupApplicationDTO(app *models.GroupApplication) GroupApplicationDTO {
-	return GroupApplicationDTO{
+// ToGroupApplicationDTO converts the application; a pending one expires after expiry
+func ToGroupApplicationDTO(app *models.GroupApplication, expiry time.Duration) GroupApplicationDTO {
+	result := GroupApplicationDTO{
 		ApplicationID: app.ApplicationID,
 		GroupID:       app.GroupID,
+		GroupName:     app.Group.Name,
 		UserID:        app.UserID,
 		Username:      app.User.Username,
 		Message:       app.Message,
 		Status:        app.Status,
+		InviteID:      app.InviteID,
+		ReviewedBy:    app.ReviewedBy,
+		ReviewedAt:    app.ReviewedAt,
+		ReviewNote:    app.ReviewNote,
+		ClosedAt:      app.ClosedAt,
 		CreatedAt:     app.CreatedAt,
 	}
+	if app.Reviewer != nil {
+		result.ReviewerUsername = app.Reviewer.Username
+	}
+	if app.Status == models.ApplicationPending {
+		expiresAt := app.CreatedAt.Add(expiry)
+		if expiresAt.Before(time.Now()) {
+			// Not swept yet by the hourly expiry
+			result.Status, result.ClosedAt = models.ApplicationExpired, &expiresAt
+		} else {
+			result.ExpiresAt = &expiresAt
+		}
+	}
+	return result
+}
+
+// BulkReviewRequest applies one decision to up to 100 applications
+type BulkReviewRequest struct {
+	ApplicationIDs []int32 `json:"application_ids" binding:"required,min=1,max=100,dive,min=1"`
+	Status         string  `json:"status" binding:"required,oneof=approved rejected"`
+	Note           string  `json:"note" binding:"max=1000"`
+}
+
+// BulkReviewItemDTO is the outcome for one application: Status is its new status when it was
+// reviewed, otherwise Reviewed is false and Error says why it was skipped
+type BulkReviewItemDTO struct {
+	ApplicationID int32  `json:"application_id"`
+	Reviewed      bool   `json:"reviewed"`
+	Status        string `json:"status,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+type BulkReviewResponse struct {
+	Reviewed int                 `json:"reviewed"`
+	Failed   int                 `json:"failed"`
+	Results  []BulkReviewItemDTO `json:"results"`
+}
+
+// ApprovalRulesDTO: an application is approved on submission when any enabled rule matches.
+// same_academic_group matches applicants verified in the group's academic group by a site
+// administrator's roster import, and invited_by_member matches applications made through an
+// invite whose creator is still a member.
+type ApprovalRulesDTO struct {
+	GroupID           int32 `json:"group_id"`
+	SameAcademicGroup bool  `json:"same_academic_group"`
+	InvitedByMember   bool  `json:"invited_by_member"`
+}
+
+type UpdateApprovalRulesRequest struct {
+	SameAcademicGroup *bool `json:"same_academic_group,omitempty"`
+	InvitedByMember   *bool `json:"invited_by_member,omitempty"`
+}
+
+func ToApprovalRulesDTO(rules *models.GroupApprovalRules) ApprovalRulesDTO {
+	return ApprovalRulesDTO{
+		GroupID:           rules.GroupID,
+		SameAcademicGroup: rules.SameAcademicGroup,
+		InvitedByMember:   rules.InvitedByMember,
+	}
 }
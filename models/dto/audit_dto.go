@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"encoding/json"
+	"space/models"
+	"time"
+)
+
+type AuditEventDTO struct {
+	ID            int32           `json:"id"`
+	Action        string          `json:"action" example:"task.verify"`
+	ActorID       int32           `json:"actor_id"`
+	ActorUsername string          `json:"actor_username"`
+	TargetType    string          `json:"target_type" example:"task"`
+	TargetID      int32           `json:"target_id"`
+	Before        json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After         json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type AuditEventsResponse struct {
+	Events     []AuditEventDTO `json:"events"`
+	Pagination PaginationMeta  `json:"pagination"`
+}
+
+func rawJSON(value *string) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	return json.RawMessage(*value)
+}
+
+func ToAuditEventDTO(event *models.AuditEvent) AuditEventDTO {
+	return AuditEventDTO{
+		ID:            event.ID,
+		Action:        event.Action,
+		ActorID:       event.ActorID,
+		ActorUsername: event.Actor.Username,
+		TargetType:    event.TargetType,
+		TargetID:      event.TargetID,
+		Before:        rawJSON(event.Before),
+		After:         rawJSON(event.After),
+		CreatedAt:     event.CreatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+type CreateCalendarFeedRequest struct {
+	GroupID *int32 `json:"group_id,omitempty"` // omit for a feed covering all groups
+}
+
+type CalendarFeedDTO struct {
+	ID         int32      `json:"id"`
+	GroupID    *int32     `json:"group_id,omitempty"`
+	GroupName  string     `json:"group_name,omitempty"`
+	URL        string     `json:"url"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func ToCalendarFeedDTO(feed *models.CalendarFeed, url string) CalendarFeedDTO {
+	return CalendarFeedDTO{
+		ID:         feed.ID,
+		GroupID:    feed.GroupID,
+		GroupName:  feed.Group.Name,
+		URL:        url,
+		CreatedAt:  feed.CreatedAt,
+		LastUsedAt: feed.LastUsedAt,
+	}
+}
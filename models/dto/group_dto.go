@@ -3,11 +3,14 @@ package dto
 import "space/models"
 
 type GroupDTO struct {
-	ID              int32  `json:"id"`
-	Name            string `json:"name"`
-	AdminUsername   string `json:"admin_username"`
-	AcademicGroupID int32  `json:"academic_group_id"`
-	AcademicGroup   string `json:"academic_group_name"`
+	ID                    int32  `json:"id"`
+	Name                  string `json:"name"`
+	AdminUsername         string `json:"admin_username"`
+	AcademicGroupID       int32  `json:"academic_group_id"`
+	AcademicGroup         string `json:"academic_group_name"`
+	Visibility            string `json:"visibility" example:"public"`
+	JoinPolicy            string `json:"join_policy" example:"application"`
+	SameAcademicGroupOnly bool   `json:"same_academic_group_only"`
 }
 
 // GetGroupsResponse represents the paginated group list
@@ -26,20 +29,31 @@ type PaginationMeta struct {
 
 func ToGroupDTO(group *models.Group) GroupDTO {
 	return GroupDTO{
-		ID:              group.ID,
-		Name:            group.Name,
-		AdminUsername:   group.Admin.Username,
-		AcademicGroupID: group.AcademicGroupID,
-		AcademicGroup:   group.AcademicGroup.Name,
+		ID:                    group.ID,
+		Name:                  group.Name,
+		AdminUsername:         group.Admin.Username,
+		AcademicGroupID:       group.AcademicGroupID,
+		AcademicGroup:         group.AcademicGroup.Name,
+		Visibility:            group.Visibility,
+		JoinPolicy:            group.JoinPolicy,
+		SameAcademicGroupOnly: group.SameAcademicGroupOnly,
 	}
 }
 
+// CreateGroupRequest: visibility is public, unlisted or private (default public); join_policy
+// is open, application or invite_only (default application)
 type CreateGroupRequest struct {
-	Name            string `json:"name" binding:"required"`
-	AcademicGroupID int32  `json:"academic_group_id" binding:"required"`
+	Name                  string `json:"name" binding:"required"`
+	AcademicGroupID       int32  `json:"academic_group_id" binding:"required"`
+	Visibility            string `json:"visibility,omitempty" example:"public"`
+	JoinPolicy            string `json:"join_policy,omitempty" example:"application"`
+	SameAcademicGroupOnly bool   `json:"same_academic_group_only,omitempty"`
 }
 
 type UpdateGroupRequest struct {
 	Name string `json:"name,omitempty"`
 	// AcademicGroupID int32  `json:"academic_group_id,omitempty"`
+	Visibility            *string `json:"visibility,omitempty" example:"unlisted"`
+	JoinPolicy            *string `json:"join_policy,omitempty" example:"invite_only"`
+	SameAcademicGroupOnly *bool   `json:"same_academic_group_only,omitempty"`
 }
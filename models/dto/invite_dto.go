@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+// CreateInviteRequest: the invite expires after expires_in_hours or at expires_at (never when
+// both are omitted); max_uses limits how many users can redeem it
+type CreateInviteRequest struct {
+	ExpiresInHours *int       `json:"expires_in_hours,omitempty" binding:"omitempty,min=1,max=8760" example:"2"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MaxUses        *int32     `json:"max_uses,omitempty" binding:"omitempty,min=1,max=10000" example:"30"`
+	AutoApprove    bool       `json:"auto_approve"`
+}
+
+type AcceptInviteRequest struct {
+	Message string `json:"message" binding:"max=1000"`
+}
+
+// InviteDTO is an invite as seen by the group's admins and moderators. Status is active,
+// expired, exhausted or revoked.
+type InviteDTO struct {
+	ID          int32      `json:"id"`
+	GroupID     int32      `json:"group_id"`
+	GroupName   string     `json:"group_name"`
+	Code        string     `json:"code" example:"K7QW3MZP"`
+	URL         string     `json:"url"`
+	AutoApprove bool       `json:"auto_approve"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	MaxUses     *int32     `json:"max_uses,omitempty"`
+	UseCount    int32      `json:"use_count"`
+	Status      string     `json:"status"`
+	CreatedBy   int32      `json:"created_by"`
+	CreatorName string     `json:"creator_name"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// InvitePreviewDTO is what a user sees before accepting an invite
+type InvitePreviewDTO struct {
+	GroupID     int32      `json:"group_id"`
+	GroupName   string     `json:"group_name"`
+	AutoApprove bool       `json:"auto_approve"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Status      string     `json:"status"`
+	IsMember    bool       `json:"is_member"`
+}
+
+type InviteUseDTO struct {
+	UserID        int32     `json:"user_id"`
+	Username      string    `json:"username"`
+	Result        string    `json:"result" example:"joined"`
+	ApplicationID *int32    `json:"application_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AcceptInviteResponse: Result is joined when the user became a member, applied when a
+// pending application was created
+type AcceptInviteResponse struct {
+	GroupID       int32  `json:"group_id"`
+	Result        string `json:"result" example:"joined"`
+	ApplicationID *int32 `json:"application_id,omitempty"`
+}
+
+func ToInviteDTO(invite *models.GroupInvite, url, status string) InviteDTO {
+	return InviteDTO{
+		ID:          invite.ID,
+		GroupID:     invite.GroupID,
+		GroupName:   invite.Group.Name,
+		Code:        invite.Code,
+		URL:         url,
+		AutoApprove: invite.AutoApprove,
+		ExpiresAt:   invite.ExpiresAt,
+		MaxUses:     invite.MaxUses,
+		UseCount:    invite.UseCount,
+		Status:      status,
+		CreatedBy:   invite.CreatedBy,
+		CreatorName: invite.Creator.Username,
+		CreatedAt:   invite.CreatedAt,
+		RevokedAt:   invite.RevokedAt,
+	}
+}
+
+func ToInviteUseDTO(use *models.GroupInviteUse) InviteUseDTO {
+	return InviteUseDTO{
+		UserID:        use.UserID,
+		Username:      use.User.Username,
+		Result:        use.Result,
+		ApplicationID: use.ApplicationID,
+		CreatedAt:     use.CreatedAt,
+	}
+}
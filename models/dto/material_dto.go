@@ -0,0 +1,129 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+type MaterialAttachmentDTO struct {
+	ID          int32     `json:"id"`
+	FileName    string    `json:"file_name" example:"lecture-1.pdf"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MaterialDTO is a study material; Content is Markdown
+type MaterialDTO struct {
+	ID          int32                   `json:"id"`
+	SubjectID   int32                   `json:"subject_id"`
+	SubjectName string                  `json:"subject_name"`
+	GroupID     *int32                  `json:"group_id,omitempty"`
+	Title       string                  `json:"title"`
+	Content     string                  `json:"content"`
+	Position    int32                   `json:"position"`
+	Version     int32                   `json:"version"`
+	IsActive    bool                    `json:"is_active"`
+	CreatedBy   int32                   `json:"created_by"`
+	CreatorName string                  `json:"creator_name"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	Attachments []MaterialAttachmentDTO `json:"attachments"`
+}
+
+type CreateMaterialRequest struct {
+	Title    string `json:"title" binding:"required,max=255" example:"Lecture 1. Introduction"`
+	Content  string `json:"content" example:"# Introduction\n\nSee the attached slides."`
+	GroupID  *int32 `json:"group_id,omitempty"`
+	Position *int32 `json:"position,omitempty" binding:"omitempty,min=0"`
+}
+
+// UpdateMaterialRequest: BaseVersion is the version the edit was made on; when set and the
+// material has changed since, the update is rejected instead of overwriting the other edit
+type UpdateMaterialRequest struct {
+	Title       *string `json:"title,omitempty" binding:"omitempty,max=255"`
+	Content     *string `json:"content,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+	Comment     string  `json:"comment" binding:"max=255" example:"Fixed the formula in section 2"`
+	BaseVersion *int32  `json:"base_version,omitempty"`
+}
+
+type ReorderMaterialsRequest struct {
+	MaterialIDs []int32 `json:"material_ids" binding:"required,min=1"`
+}
+
+func ToMaterialAttachmentDTO(attachment *models.MaterialAttachment) MaterialAttachmentDTO {
+	return MaterialAttachmentDTO{
+		ID:          attachment.ID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func ToMaterialDTO(material *models.Material) MaterialDTO {
+	attachments := make([]MaterialAttachmentDTO, len(material.Attachments))
+	for i := range material.Attachments {
+		attachments[i] = ToMaterialAttachmentDTO(&material.Attachments[i])
+	}
+	return MaterialDTO{
+		ID:          material.MaterialID,
+		SubjectID:   material.SubjectID,
+		SubjectName: material.Subject.Name,
+		GroupID:     material.GroupID,
+		Title:       material.Title,
+		Content:     material.Content,
+		Position:    material.Position,
+		Version:     material.Version,
+		IsActive:    material.IsActive,
+		CreatedBy:   material.CreatedBy,
+		CreatorName: material.Creator.Username,
+		CreatedAt:   material.CreatedAt,
+		UpdatedAt:   material.UpdatedAt,
+		Attachments: attachments,
+	}
+}
+
+type MaterialRevisionDTO struct {
+	Version      int32     `json:"version"`
+	Title        string    `json:"title"`
+	EditedBy     int32     `json:"edited_by"`
+	EditorName   string    `json:"editor_name"`
+	Comment      string    `json:"comment,omitempty"`
+	RestoredFrom *int32    `json:"restored_from,omitempty"`
+	IsCurrent    bool      `json:"is_current"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type MaterialRevisionDetailDTO struct {
+	MaterialRevisionDTO
+	Content string `json:"content"`
+}
+
+// MaterialDiffDTO holds a unified diff between two revisions; the title is diffed as a
+// leading "# Title" line of the content
+type MaterialDiffDTO struct {
+	MaterialID int32  `json:"material_id"`
+	From       int32  `json:"from"`
+	To         int32  `json:"to"`
+	Identical  bool   `json:"identical"`
+	Diff       string `json:"diff"`
+}
+
+type RestoreRevisionRequest struct {
+	Comment string `json:"comment" binding:"max=255"`
+}
+
+func ToMaterialRevisionDTO(revision *models.MaterialRevision, currentVersion int32) MaterialRevisionDTO {
+	return MaterialRevisionDTO{
+		Version:      revision.Version,
+		Title:        revision.Title,
+		EditedBy:     revision.EditedBy,
+		EditorName:   revision.Editor.Username,
+		Comment:      revision.Comment,
+		RestoredFrom: revision.RestoredFrom,
+		IsCurrent:    revision.Version == currentVersion,
+		CreatedAt:    revision.CreatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+type KickRequest struct {
+	Reason string `json:"reason" binding:"max=1000" example:"Spam in the group chat"`
+}
+
+type BanRequest struct {
+	UserID int32  `json:"user_id" binding:"required"`
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+type UnbanRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+type AddModeratorRequest struct {
+	UserID int32 `json:"user_id" binding:"required"`
+}
+
+// SetRoleRequest: the owner role is handed over through an ownership transfer instead
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=member moderator" example:"moderator"`
+}
+
+type MemberRoleDTO struct {
+	GroupID  int32  `json:"group_id"`
+	UserID   int32  `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role" example:"moderator"`
+	Changed  bool   `json:"changed"`
+}
+
+type GroupBanDTO struct {
+	GroupID          int32      `json:"group_id"`
+	UserID           int32      `json:"user_id"`
+	Username         string     `json:"username"`
+	Reason           string     `json:"reason,omitempty"`
+	BannedBy         int32      `json:"banned_by"`
+	BannedByUsername string     `json:"banned_by_username"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LiftedAt         *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy         *int32     `json:"lifted_by,omitempty"`
+}
+
+// MembershipEventDTO: Action is left, kicked, banned, unbanned, promoted or demoted
+type MembershipEventDTO struct {
+	ID            int32     `json:"id"`
+	UserID        int32     `json:"user_id"`
+	Username      string    `json:"username"`
+	ActorID       int32     `json:"actor_id"`
+	ActorUsername string    `json:"actor_username"`
+	Action        string    `json:"action" example:"kicked"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type MembershipEventsResponse struct {
+	Events     []MembershipEventDTO `json:"events"`
+	Pagination PaginationMeta       `json:"pagination"`
+}
+
+func ToGroupBanDTO(ban *models.GroupBan) GroupBanDTO {
+	return GroupBanDTO{
+		GroupID:          ban.GroupID,
+		UserID:           ban.UserID,
+		Username:         ban.User.Username,
+		Reason:           ban.Reason,
+		BannedBy:         ban.BannedBy,
+		BannedByUsername: ban.Moderator.Username,
+		CreatedAt:        ban.CreatedAt,
+		LiftedAt:         ban.LiftedAt,
+		LiftedBy:         ban.LiftedBy,
+	}
+}
+
+func ToMembershipEventDTO(event *models.GroupMembershipEvent) MembershipEventDTO {
+	return MembershipEventDTO{
+		ID:            event.ID,
+		UserID:        event.UserID,
+		Username:      event.User.Username,
+		ActorID:       event.ActorID,
+		ActorUsername: event.Actor.Username,
+		Action:        event.Action,
+		Reason:        event.Reason,
+		CreatedAt:     event.CreatedAt,
+	}
+}
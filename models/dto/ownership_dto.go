@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+type ProposeTransferRequest struct {
+	Username string `json:"username" binding:"required" example:"ivanov"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// OwnershipTransferDTO: Reason is transfer for transfers proposed by the owner and succession
+// for automatic ones after the owner deleted their account
+type OwnershipTransferDTO struct {
+	ID           int32      `json:"id"`
+	GroupID      int32      `json:"group_id"`
+	GroupName    string     `json:"group_name"`
+	FromUserID   int32      `json:"from_user_id"`
+	FromUsername string     `json:"from_username"`
+	ToUserID     int32      `json:"to_user_id"`
+	ToUsername   string     `json:"to_username"`
+	Reason       string     `json:"reason" example:"transfer"`
+	Status       string     `json:"status" example:"pending"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+}
+
+// SuccessionDTO tells what happened to a group owned by a deleted account
+type SuccessionDTO struct {
+	GroupID    int32 `json:"group_id"`
+	NewOwnerID int32 `json:"new_owner_id,omitempty"`
+	Deleted    bool  `json:"deleted"`
+}
+
+type DeleteAccountResponse struct {
+	Groups []SuccessionDTO `json:"groups"`
+}
+
+func ToOwnershipTransferDTO(transfer *models.GroupOwnershipTransfer) OwnershipTransferDTO {
+	return OwnershipTransferDTO{
+		ID:           transfer.ID,
+		GroupID:      transfer.GroupID,
+		GroupName:    transfer.Group.Name,
+		FromUserID:   transfer.FromUserID,
+		FromUsername: transfer.FromUser.Username,
+		ToUserID:     transfer.ToUserID,
+		ToUsername:   transfer.ToUser.Username,
+		Reason:       transfer.Reason,
+		Status:       transfer.Status,
+		CreatedAt:    transfer.CreatedAt,
+		RespondedAt:  transfer.RespondedAt,
+	}
+}
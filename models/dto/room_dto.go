@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"space/models"
+	"space/utils"
+)
+
+type RoomDTO struct {
+	ID        int32    `json:"id"`
+	Building  string   `json:"building" example:"А"`
+	Number    string   `json:"number" example:"101"`
+	Label     string   `json:"label" example:"А-101"`
+	Capacity  int32    `json:"capacity" example:"30"`
+	Equipment []string `json:"equipment" example:"projector,whiteboard"`
+}
+
+type RoomRequest struct {
+	Building  string   `json:"building" example:"А"`
+	Number    string   `json:"number" binding:"required" example:"101"`
+	Capacity  int32    `json:"capacity" binding:"min=0" example:"30"`
+	Equipment []string `json:"equipment"`
+}
+
+type UpdateRoomRequest struct {
+	Building  *string   `json:"building,omitempty"`
+	Number    *string   `json:"number,omitempty"`
+	Capacity  *int32    `json:"capacity,omitempty" binding:"omitempty,min=0"`
+	Equipment *[]string `json:"equipment,omitempty"`
+}
+
+type FreeRoomsResponse struct {
+	Date      string        `json:"date" example:"2025-09-01"`
+	TimeSlots []TimeSlotDTO `json:"time_slots"`
+	Rooms     []RoomDTO     `json:"rooms"`
+}
+
+func ToRoomDTO(room *models.Room) RoomDTO {
+	equipment := make([]string, len(room.Equipment))
+	for i, e := range room.Equipment {
+		equipment[i] = e.Tag
+	}
+	return RoomDTO{
+		ID:        room.ID,
+		Building:  room.Building,
+		Number:    room.Number,
+		Label:     utils.RoomLabel(room.Building, room.Number),
+		Capacity:  room.Capacity,
+		Equipment: equipment,
+	}
+}
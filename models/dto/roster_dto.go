@@ -0,0 +1,37 @@
+package dto
+
+// RosterImportItemDTO describes what the import does with one roster row. Action is created
+// (a new account), matched (an existing account found by student ID or email) or rejected.
+// Matched accounts join only if they had a pending application (Joined); otherwise Reason
+// says they need an invite. TemporaryPassword is only returned for accounts actually created,
+// so it can be handed to the student.
+type RosterImportItemDTO struct {
+	Source            string `json:"source"`
+	Action            string `json:"action" example:"created"`
+	FullName          string `json:"full_name"`
+	Email             string `json:"email"`
+	StudentID         string `json:"student_id,omitempty"`
+	UserID            int32  `json:"user_id,omitempty"`
+	Username          string `json:"username,omitempty"`
+	AlreadyMember     bool   `json:"already_member,omitempty"`
+	Joined            bool   `json:"joined,omitempty"`
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// RosterImportSummary: Joined counts the users added to the group: created accounts and
+// matched users whose pending application was approved
+type RosterImportSummary struct {
+	Created  int `json:"created"`
+	Matched  int `json:"matched"`
+	Rejected int `json:"rejected"`
+	Joined   int `json:"joined"`
+}
+
+type RosterImportReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Format  string                `json:"format"`
+	GroupID int32                 `json:"group_id"`
+	Summary RosterImportSummary   `json:"summary"`
+	Items   []RosterImportItemDTO `json:"items"`
+}
@@ -0,0 +1,255 @@
+package dto
+
+import (
+	"space/models"
+)
+
+const DateLayout = "2006-01-02"
+
+type TimeSlotDTO struct {
+	ID         int32  `json:"id"`
+	SlotNumber int32  `json:"slot_number" example:"1"`
+	StartTime  string `json:"start_time" example:"09:00"`
+	EndTime    string `json:"end_time" example:"10:30"`
+}
+
+type TimeSlotRequest struct {
+	SlotNumber int32  `json:"slot_number" binding:"required,min=1,max=9"`
+	StartTime  string `json:"start_time" binding:"required" example:"09:00"`
+	EndTime    string `json:"end_time" binding:"required" example:"10:30"`
+}
+
+type ScheduleEntryDTO struct {
+	ID              int32       `json:"id"`
+	Date            string      `json:"date" example:"2025-09-01"`
+	GroupID         int32       `json:"group_id"`
+	GroupName       string      `json:"group_name"`
+	SubjectID       int32       `json:"subject_id"`
+	SubjectName     string      `json:"subject_name"`
+	TeacherInitials string      `json:"teacher_initials"`
+	TeacherID       *int32      `json:"teacher_id,omitempty"`
+	Classroom       string      `json:"classroom"`
+	RoomID          *int32      `json:"room_id,omitempty"`
+	TimeSlot        TimeSlotDTO `json:"time_slot"`
+	RuleID          *int32      `json:"rule_id,omitempty"`
+}
+
+type ScheduleDayDTO struct {
+	Date    string             `json:"date" example:"2025-09-01"`
+	Entries []ScheduleEntryDTO `json:"entries"`
+}
+
+type ScheduleResponse struct {
+	From string           `json:"from" example:"2025-09-01"`
+	To   string           `json:"to" example:"2025-09-07"`
+	Days []ScheduleDayDTO `json:"days"`
+}
+
+type CreateScheduleRequest struct {
+	SubjectID       int32  `json:"subject_id" binding:"required"`
+	TimeSlotID      int32  `json:"time_slot_id" binding:"required"`
+	Date            string `json:"date" binding:"required" example:"2025-09-01"`
+	TeacherInitials string `json:"teacher_initials" example:"Иванов И.И."`
+	TeacherID       *int32 `json:"teacher_id,omitempty"`
+	Classroom       string `json:"classroom" example:"А-101"`
+	RoomID          *int32 `json:"room_id,omitempty"`
+}
+
+type UpdateScheduleRequest struct {
+	SubjectID       *int32  `json:"subject_id,omitempty"`
+	TimeSlotID      *int32  `json:"time_slot_id,omitempty"`
+	Date            *string `json:"date,omitempty" example:"2025-09-01"`
+	TeacherInitials *string `json:"teacher_initials,omitempty"`
+	TeacherID       *int32  `json:"teacher_id,omitempty"`
+	Classroom       *string `json:"classroom,omitempty"`
+	RoomID          *int32  `json:"room_id,omitempty"`
+}
+
+// trimSeconds turns postgres "09:00:00" into "09:00"
+func trimSeconds(t string) string {
+	if len(t) > 5 {
+		return t[:5]
+	}
+	return t
+}
+
+func ToTimeSlotDTO(slot *models.TimeSlot) TimeSlotDTO {
+	return TimeSlotDTO{
+		ID:         slot.SlotID,
+		SlotNumber: slot.SlotNumber,
+		StartTime:  trimSeconds(slot.StartTime),
+		EndTime:    trimSeconds(slot.EndTime),
+	}
+}
+
+func ToScheduleEntryDTO(schedule *models.Schedule) ScheduleEntryDTO {
+	return ScheduleEntryDTO{
+		ID:              schedule.ScheduleID,
+		Date:            schedule.Date.Format(DateLayout),
+		GroupID:         schedule.GroupID,
+		GroupName:       schedule.Group.Name,
+		SubjectID:       schedule.SubjectID,
+		SubjectName:     schedule.Subject.Name,
+		TeacherInitials: schedule.TeacherInitials,
+		TeacherID:       schedule.TeacherID,
+		Classroom:       schedule.Classroom,
+		RoomID:          schedule.RoomID,
+		TimeSlot:        ToTimeSlotDTO(&schedule.TimeSlot),
+		RuleID:          schedule.RuleID,
+	}
+}
+
+type ScheduleRuleRequest struct {
+	SubjectID       int32  `json:"subject_id" binding:"required"`
+	TimeSlotID      int32  `json:"time_slot_id" binding:"required"`
+	Weekday         int32  `json:"weekday" binding:"required,min=1,max=7" example:"1"`
+	WeekParity      string `json:"week_parity" binding:"omitempty,oneof=all odd even" example:"odd"`
+	SemesterStart   string `json:"semester_start" binding:"required" example:"2025-09-01"`
+	SemesterEnd     string `json:"semester_end" binding:"required" example:"2025-12-28"`
+	TeacherInitials string `json:"teacher_initials" example:"Иванов И.И."`
+	TeacherID       *int32 `json:"teacher_id,omitempty"`
+	Classroom       string `json:"classroom" example:"А-101"`
+	RoomID          *int32 `json:"room_id,omitempty"`
+}
+
+type UpdateScheduleRuleRequest struct {
+	SubjectID       *int32  `json:"subject_id,omitempty"`
+	TimeSlotID      *int32  `json:"time_slot_id,omitempty"`
+	Weekday         *int32  `json:"weekday,omitempty" binding:"omitempty,min=1,max=7"`
+	WeekParity      *string `json:"week_parity,omitempty" binding:"omitempty,oneof=all odd even"`
+	SemesterStart   *string `json:"semester_start,omitempty"`
+	SemesterEnd     *string `json:"semester_end,omitempty"`
+	TeacherInitials *string `json:"teacher_initials,omitempty"`
+	TeacherID       *int32  `json:"teacher_id,omitempty"`
+	Classroom       *string `json:"classroom,omitempty"`
+	RoomID          *int32  `json:"room_id,omitempty"`
+}
+
+type RuleExceptionRequest struct {
+	Date   string `json:"date" binding:"required" example:"2025-11-04"`
+	Reason string `json:"reason" example:"Public holiday"`
+}
+
+type RuleExceptionDTO struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+type ScheduleRuleDTO struct {
+	ID              int32              `json:"id"`
+	GroupID         int32              `json:"group_id"`
+	SubjectID       int32              `json:"subject_id"`
+	SubjectName     string             `json:"subject_name"`
+	TimeSlot        TimeSlotDTO        `json:"time_slot"`
+	Weekday         int32              `json:"weekday"`
+	WeekParity      string             `json:"week_parity"`
+	SemesterStart   string             `json:"semester_start"`
+	SemesterEnd     string             `json:"semester_end"`
+	TeacherInitials string             `json:"teacher_initials"`
+	TeacherID       *int32             `json:"teacher_id,omitempty"`
+	Classroom       string             `json:"classroom"`
+	RoomID          *int32             `json:"room_id,omitempty"`
+	Exceptions      []RuleExceptionDTO `json:"exceptions"`
+	LessonDates     []string           `json:"lesson_dates"`
+}
+
+func ToScheduleRuleDTO(rule *models.ScheduleRule, lessonDates []string) ScheduleRuleDTO {
+	exceptions := make([]RuleExceptionDTO, len(rule.Exceptions))
+	for i, e := range rule.Exceptions {
+		exceptions[i] = RuleExceptionDTO{Date: e.Date.Format(DateLayout), Reason: e.Reason}
+	}
+	return ScheduleRuleDTO{
+		ID:              rule.ID,
+		GroupID:         rule.GroupID,
+		SubjectID:       rule.SubjectID,
+		SubjectName:     rule.Subject.Name,
+		TimeSlot:        ToTimeSlotDTO(&rule.TimeSlot),
+		Weekday:         rule.Weekday,
+		WeekParity:      rule.WeekParity,
+		SemesterStart:   rule.SemesterStart.Format(DateLayout),
+		SemesterEnd:     rule.SemesterEnd.Format(DateLayout),
+		TeacherInitials: rule.TeacherInitials,
+		TeacherID:       rule.TeacherID,
+		Classroom:       rule.Classroom,
+		RoomID:          rule.RoomID,
+		Exceptions:      exceptions,
+		LessonDates:     lessonDates,
+	}
+}
+
+// TimetableImportItemDTO describes what the import does with one lesson.
+// Action is create, update, unchanged or conflict.
+type TimetableImportItemDTO struct {
+	Source     string `json:"source"`
+	Action     string `json:"action"`
+	Date       string `json:"date"`
+	SlotNumber int32  `json:"slot_number"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	Subject    string `json:"subject"`
+	Teacher    string `json:"teacher_initials"`
+	Classroom  string `json:"classroom"`
+	Reason     string `json:"reason,omitempty"`
+
+	Conflicts []ScheduleConflictDTO `json:"conflicts,omitempty"`
+}
+
+type TimetableImportErrorDTO struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+type TimetableImportSummary struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Conflicts int `json:"conflicts"`
+	Errors    int `json:"errors"`
+}
+
+type TimetableImportReport struct {
+	DryRun           bool                      `json:"dry_run"`
+	Format           string                    `json:"format"`
+	Summary          TimetableImportSummary    `json:"summary"`
+	TimeSlotsCreated []TimeSlotDTO             `json:"time_slots_created"`
+	SubjectsCreated  []string                  `json:"subjects_created"`
+	Items            []TimetableImportItemDTO  `json:"items"`
+	Errors           []TimetableImportErrorDTO `json:"errors"`
+}
+
+// ConflictLessonDTO is one side of a schedule conflict; ScheduleID is 0 for a lesson that isn't saved yet
+type ConflictLessonDTO struct {
+	ScheduleID      int32  `json:"schedule_id"`
+	GroupID         int32  `json:"group_id"`
+	GroupName       string `json:"group_name"`
+	AcademicGroupID int32  `json:"academic_group_id"`
+	SubjectName     string `json:"subject_name"`
+	TeacherInitials string `json:"teacher_initials"`
+	TeacherID       *int32 `json:"teacher_id,omitempty"`
+	Classroom       string `json:"classroom"`
+	RoomID          *int32 `json:"room_id,omitempty"`
+}
+
+// ScheduleConflictDTO describes two lessons in the same time slot on the same date.
+// Type is room, teacher or academic_group; Value is the shared room or teacher.
+type ScheduleConflictDTO struct {
+	Type       string              `json:"type"`
+	Date       string              `json:"date"`
+	TimeSlotID int32               `json:"time_slot_id"`
+	Value      string              `json:"value,omitempty"`
+	Lessons    []ConflictLessonDTO `json:"lessons"`
+}
+
+func ToConflictLessonDTO(schedule *models.Schedule) ConflictLessonDTO {
+	return ConflictLessonDTO{
+		ScheduleID:      schedule.ScheduleID,
+		GroupID:         schedule.GroupID,
+		GroupName:       schedule.Group.Name,
+		AcademicGroupID: schedule.Group.AcademicGroupID,
+		SubjectName:     schedule.Subject.Name,
+		TeacherInitials: schedule.TeacherInitials,
+		TeacherID:       schedule.TeacherID,
+		Classroom:       schedule.Classroom,
+		RoomID:          schedule.RoomID,
+	}
+}
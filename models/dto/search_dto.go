@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// SearchResultDTO is one search hit. Snippet is HTML: the text is escaped and matches are
+// wrapped in <mark> tags.
+type SearchResultDTO struct {
+	Type      string    `json:"type" example:"task"`
+	ID        int32     `json:"id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet" example:"Solve <mark>integrals</mark> 1-10"`
+	GroupID   *int32    `json:"group_id,omitempty"`
+	SubjectID *int32    `json:"subject_id,omitempty"`
+	Path      string    `json:"path" example:"/api/tasks/12"`
+	Rank      float64   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchResponse struct {
+	Query      string            `json:"query"`
+	Results    []SearchResultDTO `json:"results"`
+	Facets     map[string]int64  `json:"facets"` // hits per type, ignoring the types filter
+	Pagination PaginationMeta    `json:"pagination"`
+}
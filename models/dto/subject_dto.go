@@ -18,17 +18,19 @@ type SubjectDetailDTO struct {
 }
 
 type TaskDetailDTO struct {
-	ID            int32            `json:"id"`
-	Title         string           `json:"title"`
-	Description   string           `json:"description"`
-	IsVerified    bool             `json:"is_verified"`
-	Deadline      *time.Time       `json:"deadline,omitempty"`
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
-	User          UserDTO          `json:"user"`
-	Group         GroupDTO         `json:"group"`
-	Subject       *SubjectDTO      `json:"subject,omitempty"`
-	AcademicGroup AcademicGroupDTO `json:"academic_group"`
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsVerified  bool   `json:"is_verified"`
+	// VerificationStatus is pending, verified or rejected
+	VerificationStatus string           `json:"verification_status" example:"pending"`
+	Deadline           *time.Time       `json:"deadline,omitempty"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	User               UserDTO          `json:"user"`
+	Group              GroupDTO         `json:"group"`
+	Subject            *SubjectDTO      `json:"subject,omitempty"`
+	AcademicGroup      AcademicGroupDTO `json:"academic_group"`
 }
 
 type SubjectsResponse struct {
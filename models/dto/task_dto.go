@@ -6,30 +6,33 @@ import (
 )
 
 type TaskDTO struct {
-	ID          int32      `json:"id"`
-	GroupID     int32      `json:"group_id"`
-	UserID      int32      `json:"user_id"`
-	Username    string     `json:"username"`
-	SubjectID   *int32     `json:"subject_id,omitempty"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	IsVerified  bool       `json:"is_verified"`
-	Deadline    *time.Time `json:"deadline,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID          int32  `json:"id"`
+	GroupID     int32  `json:"group_id"`
+	UserID      int32  `json:"user_id"`
+	Username    string `json:"username"`
+	SubjectID   *int32 `json:"subject_id,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsVerified  bool   `json:"is_verified"`
+	// VerificationStatus is pending, verified or rejected
+	VerificationStatus string     `json:"verification_status" example:"pending"`
+	Deadline           *time.Time `json:"deadline,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 func ToTaskDTO(task *models.Task) TaskDTO {
 	return TaskDTO{
-		ID:          task.ID,
-		GroupID:     task.GroupID,
-		UserID:      task.UserID,
-		Username:    task.User.Username,
-		SubjectID:   task.SubjectID,
-		Title:       task.Title,
-		Description: task.Description,
-		IsVerified:  task.IsVerified,
-		Deadline:    task.Deadline,
-		CreatedAt:   task.CreatedAt,
+		ID:                 task.ID,
+		GroupID:            task.GroupID,
+		UserID:             task.UserID,
+		Username:           task.User.Username,
+		SubjectID:          task.SubjectID,
+		Title:              task.Title,
+		Description:        task.Description,
+		IsVerified:         task.IsVerified,
+		VerificationStatus: task.VerificationStatus,
+		Deadline:           task.Deadline,
+		CreatedAt:          task.CreatedAt,
 	}
 }
 
@@ -0,0 +1,45 @@
+package dto
+
+import "space/models"
+
+type TeacherDTO struct {
+	ID         int32  `json:"id"`
+	FullName   string `json:"full_name" example:"Иванов Иван Иванович"`
+	Initials   string `json:"initials" example:"Иванов И.И."`
+	Email      string `json:"email"`
+	Department string `json:"department"`
+}
+
+type TeacherDetailDTO struct {
+	TeacherDTO
+	Subjects []SubjectDTO `json:"subjects"`
+}
+
+type TeacherRequest struct {
+	FullName   string `json:"full_name"`
+	Initials   string `json:"initials" binding:"required"`
+	Email      string `json:"email" binding:"omitempty,email"`
+	Department string `json:"department"`
+}
+
+type UpdateTeacherRequest struct {
+	FullName   *string `json:"full_name,omitempty"`
+	Initials   *string `json:"initials,omitempty"`
+	Email      *string `json:"email,omitempty" binding:"omitempty,email"`
+	Department *string `json:"department,omitempty"`
+}
+
+type TeacherScheduleResponse struct {
+	Teacher TeacherDTO `json:"teacher"`
+	ScheduleResponse
+}
+
+func ToTeacherDTO(teacher *models.Teacher) TeacherDTO {
+	return TeacherDTO{
+		ID:         teacher.ID,
+		FullName:   teacher.FullName,
+		Initials:   teacher.Initials,
+		Email:      teacher.Email,
+		Department: teacher.Department,
+	}
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+type TelegramLinkCodeDTO struct {
+	Code      string    `json:"code" example:"K7M2QX9P"`
+	DeepLink  string    `json:"deep_link,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TelegramStatusDTO struct {
+	Linked           bool       `json:"linked"`
+	LinkedAt         *time.Time `json:"linked_at,omitempty"`
+	DigestEnabled    bool       `json:"digest_enabled"`
+	RemindersEnabled bool       `json:"reminders_enabled"`
+}
+
+type TelegramSettingsRequest struct {
+	DigestEnabled    *bool `json:"digest_enabled"`
+	RemindersEnabled *bool `json:"reminders_enabled"`
+}
+
+func ToTelegramStatusDTO(link *models.TelegramLink) TelegramStatusDTO {
+	return TelegramStatusDTO{
+		Linked:           link.ChatID != nil,
+		LinkedAt:         link.LinkedAt,
+		DigestEnabled:    link.DigestEnabled,
+		RemindersEnabled: link.RemindersEnabled,
+	}
+}
@@ -12,10 +12,12 @@ type UserResponseDTO struct {
 	// Excludes Email, HashPassword
 }
 
+// UserDTO: Reputation is only set where the user is listed within a group
 type UserDTO struct {
-	UserID    int32     `json:"user_id"`
-	Username  string    `json:"username"`
-	CreatedAt time.Time `json:"created_at"`
+	UserID     int32     `json:"user_id"`
+	Username   string    `json:"username"`
+	CreatedAt  time.Time `json:"created_at"`
+	Reputation *int      `json:"reputation,omitempty"`
 }
 
 type ModeratorsResponse struct {
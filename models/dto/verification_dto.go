@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"space/models"
+	"time"
+)
+
+// VerificationPolicyDTO: mode moderator lets any admin or moderator decide alone; mode vote
+// decides once quorum votes are in, verifying when at least threshold percent say legit.
+// Voters is moderators or members. New tasks from authors with at least
+// auto_verify_reputation are verified right away; 0 turns that off.
+type VerificationPolicyDTO struct {
+	GroupID              int32  `json:"group_id"`
+	Mode                 string `json:"mode" example:"vote"`
+	Voters               string `json:"voters" example:"members"`
+	Quorum               int    `json:"quorum" example:"3"`
+	Threshold            int    `json:"threshold" example:"60"`
+	AutoVerifyReputation int    `json:"auto_verify_reputation" example:"100"`
+}
+
+type UpdateVerificationPolicyRequest struct {
+	Mode                 *string `json:"mode,omitempty" binding:"omitempty,oneof=moderator vote" example:"vote"`
+	Voters               *string `json:"voters,omitempty" binding:"omitempty,oneof=moderators members" example:"members"`
+	Quorum               *int    `json:"quorum,omitempty" binding:"omitempty,min=1,max=100" example:"3"`
+	Threshold            *int    `json:"threshold,omitempty" binding:"omitempty,min=1,max=100" example:"60"`
+	AutoVerifyReputation *int    `json:"auto_verify_reputation,omitempty" binding:"omitempty,min=0" example:"100"`
+}
+
+type VoteRequest struct {
+	Vote   string `json:"vote" binding:"required,oneof=legit fake" example:"fake"`
+	Reason string `json:"reason" binding:"max=1000" example:"The deadline is wrong, it was moved to Friday"`
+}
+
+type TaskVoteDTO struct {
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	Vote      string    `json:"vote"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VerificationDecisionDTO: Method is moderator for a direct decision, vote when the quorum
+// settled it and reputation when the author's reputation verified it; Legit and Fake are the
+// tally at that moment
+type VerificationDecisionDTO struct {
+	PreviousStatus    string    `json:"previous_status"`
+	Status            string    `json:"status"`
+	Method            string    `json:"method"`
+	DecidedBy         int32     `json:"decided_by"`
+	DecidedByUsername string    `json:"decided_by_username"`
+	Legit             int       `json:"legit"`
+	Fake              int       `json:"fake"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type TaskVerificationDTO struct {
+	TaskID  int32                     `json:"task_id"`
+	Status  string                    `json:"status" example:"pending"`
+	Policy  VerificationPolicyDTO     `json:"policy"`
+	Legit   int                       `json:"legit"`
+	Fake    int                       `json:"fake"`
+	MyVote  string                    `json:"my_vote,omitempty"`
+	Votes   []TaskVoteDTO             `json:"votes"`
+	History []VerificationDecisionDTO `json:"history"`
+}
+
+// LeaderboardEntryDTO: reputation is 10 points per verified task minus 15 per rejected one
+type LeaderboardEntryDTO struct {
+	Rank       int    `json:"rank" example:"1"`
+	UserID     int32  `json:"user_id"`
+	Username   string `json:"username"`
+	Reputation int    `json:"reputation" example:"120"`
+	Verified   int    `json:"verified_tasks" example:"13"`
+	Rejected   int    `json:"rejected_tasks" example:"1"`
+	Pending    int    `json:"pending_tasks" example:"2"`
+}
+
+func ToVerificationPolicyDTO(policy *models.GroupVerificationPolicy) VerificationPolicyDTO {
+	return VerificationPolicyDTO{
+		GroupID:   policy.GroupID,
+		Mode:      policy.Mode,
+		Voters:    policy.Voters,
+		Quorum:    policy.Quorum,
+		Threshold: policy.Threshold,
+
+		AutoVerifyReputation: policy.AutoVerifyReputation,
+	}
+}
+
+func ToTaskVoteDTO(vote *models.TaskVote) TaskVoteDTO {
+	return TaskVoteDTO{
+		UserID:    vote.UserID,
+		Username:  vote.User.Username,
+		Vote:      vote.Vote,
+		Reason:    vote.Reason,
+		UpdatedAt: vote.UpdatedAt,
+	}
+}
+
+func ToVerificationDecisionDTO(decision *models.TaskVerificationDecision) VerificationDecisionDTO {
+	return VerificationDecisionDTO{
+		PreviousStatus:    decision.PreviousStatus,
+		Status:            decision.Status,
+		Method:            decision.Method,
+		DecidedBy:         decision.DecidedBy,
+		DecidedByUsername: decision.Decider.Username,
+		Legit:             decision.Legit,
+		Fake:              decision.Fake,
+		CreatedAt:         decision.CreatedAt,
+	}
+}
@@ -154,11 +154,29 @@ type Group struct {
 	AcademicGroupID int32     `gorm:"index" json:"academic_group_id"` // Foreign key to AcademicGroup
 	AdminID         int32     `gorm:"index"`                          // Foreign key to User (admin)
 
+	// Who can find and join the group; invites issued by its admins and moderators bypass these
+	Visibility            string `gorm:"type:varchar(20);not null;default:'public'" json:"visibility"`       // public, unlisted, private
+	JoinPolicy            string `gorm:"type:varchar(20);not null;default:'application'" json:"join_policy"` // open, application, invite_only
+	SameAcademicGroupOnly bool   `gorm:"not null;default:false" json:"same_academic_group_only"`             // only users verified in the same academic group (User.AcademicGroupID) may join
+
 	AcademicGroup AcademicGroup
 
 	Admin User
 }
 
+const (
+	// GroupPublic groups are listed among available groups
+	GroupPublic = "public"
+	// GroupUnlisted groups aren't listed but accept applications from anyone who knows them
+	GroupUnlisted = "unlisted"
+	// GroupPrivate groups aren't listed and can only be joined by invite
+	GroupPrivate = "private"
+
+	JoinOpen        = "open"
+	JoinApplication = "application"
+	JoinInviteOnly  = "invite_only"
+)
+
 // GroupModer
 type OldGroupModer struct {
 	GroupID int32 `gorm:"primaryKey;foreignKey:GroupID;references:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
@@ -166,20 +184,28 @@ type OldGroupModer struct {
 }
 
 type GroupModer struct {
-	GroupID   int32 `gorm:"primaryKey;foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	UserID    int32 `gorm:"primaryKey;foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Group     Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	User      User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	GroupID   int32  `gorm:"primaryKey;foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserID    int32  `gorm:"primaryKey;foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	GrantedBy *int32 // nil for moderators appointed before grants were recorded
+	Group     Group  `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User      User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	CreatedAt time.Time
 }
 
 // Users
 type User struct {
-	UserID       int32     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username     string    `gorm:"type:varchar(255);not null"`
-	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
-	Email        string    `gorm:"type:varchar(255);not null"`
-	HashPassword string    `gorm:"type:varchar(255);not null"`
+	UserID       int32      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username     string     `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	Email        string     `gorm:"type:varchar(255);not null"`
+	HashPassword string     `gorm:"type:varchar(255);not null"`
+	FullName     string     `gorm:"type:varchar(255);not null;default:''"`
+	StudentID    *string    `gorm:"type:varchar(64);uniqueIndex"`    // set by roster imports
+	IsAdmin      bool       `gorm:"not null;default:false" json:"-"` // site administrator, granted with the create-admin command
+	DeletedAt    *time.Time `json:"-"`                               // set when the account was deleted; the row is kept anonymized for authored content
+	// AcademicGroupID is the user's verified academic group, recorded by a site administrator's
+	// roster import. Group membership doesn't prove it, anyone can create a group.
+	AcademicGroupID *int32 `gorm:"index" json:"-"`
 }
 
 // GroupUsers
@@ -197,6 +223,7 @@ type Subject struct {
 	SubjectID       int32     `gorm:"primaryKey"`
 	AcademicGroupID int32     `gorm:"foreignKey:AcademicGroupID;references:AcademicGroupID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	Name            string    `gorm:"type:varchar(255);not null"`
+	TeacherID       *int32    `gorm:"index"` // lead teacher
 	CreatedAt       time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 	AcademicGroup   AcademicGroup
 }
@@ -227,41 +254,80 @@ type OldTask struct {
 	User User `gorm:"foreignKey:CreatedBy;references:user_id" json:"-"`
 }
 type Task struct {
-	ID          int32      `gorm:"primaryKey;autoIncrement" json:"id"`
-	GroupID     int32      `gorm:"not null" json:"group_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	UserID      int32      `gorm:"not null" json:"user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Title       string     `gorm:"not null" json:"title"`
-	Description string     `gorm:"type:text" json:"description"`
-	IsVerified  bool       `gorm:"default:false" json:"is_verified"`
-	SubjectID   *int32     `gorm:"" json:"subject_id,omitempty"`
-	Deadline    *time.Time `gorm:"type:timestamp" json:"deadline,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	User        User       `json:"-"`
-	Group       Group      `json:"-"`
-	Subject     Subject    `json:"-"`
+	ID          int32  `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID     int32  `gorm:"not null" json:"group_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserID      int32  `gorm:"not null" json:"user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Title       string `gorm:"not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	IsVerified  bool   `gorm:"default:false" json:"is_verified"`
+	// VerificationStatus is pending, verified or rejected; IsVerified mirrors verified
+	VerificationStatus string     `gorm:"type:varchar(20);not null;default:pending" json:"verification_status"`
+	SubjectID          *int32     `gorm:"" json:"subject_id,omitempty"`
+	Deadline           *time.Time `gorm:"type:timestamp" json:"deadline,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	User               User       `json:"-"`
+	Group              Group      `json:"-"`
+	Subject            Subject    `json:"-"`
 }
 
 // Materials
 type Material struct {
 	MaterialID int32     `gorm:"primaryKey"`
 	SubjectID  int32     `gorm:"foreignKey:SubjectID;references:SubjectID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	GroupID    *int32    `gorm:"index"` // nil: shared with every group of the subject's academic group
 	Title      string    `gorm:"type:varchar(255);not null"`
-	Content    string    `gorm:"type:text"`
+	Content    string    `gorm:"type:text"` // Markdown
+	Position   int32     `gorm:"not null;default:0"`
+	Version    int32     `gorm:"not null;default:1"` // latest MaterialRevision
 	CreatedBy  int32     `gorm:"foreignKey:CreatedBy;references:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	IsActive   bool      `gorm:"default:true"`
 	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time
 	Subject    Subject
 	Creator    User `gorm:"foreignKey:CreatedBy"`
 	// Subject    Subject   `gorm:"foreignKey:SubjectID"`
+
+	Attachments []MaterialAttachment `gorm:"foreignKey:MaterialID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// MaterialRevision is a saved state of a material's title and content. Version 1 is the
+// original text; every edit and rollback adds the next version.
+type MaterialRevision struct {
+	ID           int32     `gorm:"primaryKey;autoIncrement"`
+	MaterialID   int32     `gorm:"not null;uniqueIndex:idx_material_revision"`
+	Version      int32     `gorm:"not null;uniqueIndex:idx_material_revision"`
+	Title        string    `gorm:"type:varchar(255);not null"`
+	Content      string    `gorm:"type:text"`
+	EditedBy     int32     `gorm:"not null"`
+	Comment      string    `gorm:"type:varchar(255)"`
+	RestoredFrom *int32    // version this revision rolled back to
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Editor User `gorm:"foreignKey:EditedBy"`
+}
+
+// MaterialAttachment is a file attached to a material. Data is stored in the database
+// and only loaded for downloads.
+type MaterialAttachment struct {
+	ID          int32  `gorm:"primaryKey;autoIncrement"`
+	MaterialID  int32  `gorm:"index;not null"`
+	FileName    string `gorm:"type:varchar(255);not null"`
+	ContentType string `gorm:"type:varchar(255)"`
+	Size        int64  `gorm:"not null"`
+	Data        []byte `gorm:"type:bytea"`
+	UploadedBy  int32
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 // TimeSlots
+// StartTime/EndTime are "15:04" strings: the pgx driver returns postgres
+// time columns as text, which can't be scanned into time.Time
 type TimeSlot struct {
-	SlotID     int32     `gorm:"primaryKey"`
-	SlotNumber int32     `gorm:"unique;check:slot_number BETWEEN 1 AND 9"`
-	StartTime  time.Time `gorm:"type:time;not null"`
-	EndTime    time.Time `gorm:"type:time;not null"`
+	SlotID     int32  `gorm:"primaryKey"`
+	SlotNumber int32  `gorm:"unique;check:slot_number BETWEEN 1 AND 9"`
+	StartTime  string `gorm:"type:time without time zone;not null"`
+	EndTime    string `gorm:"type:time without time zone;not null"`
 }
 
 // Schedules
@@ -272,8 +338,11 @@ type Schedule struct {
 	SubjectID int32 `gorm:"foreignKey:SubjectID;references:SubjectID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 
 	TeacherInitials string `gorm:"type:varchar(50)"`
+	TeacherID       *int32 `gorm:"index"` // TeacherInitials is kept as the display text
 	Classroom       string `gorm:"type:varchar(50)"`
+	RoomID          *int32 `gorm:"index"` // Classroom is kept as the display text
 	TimeSlotID      int32
+	RuleID          *int32 `gorm:"index"` // set when the lesson was generated from a ScheduleRule
 
 	Date      time.Time `gorm:"type:date;not null"`
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
@@ -285,14 +354,347 @@ type Schedule struct {
 	// Subject  Subject `gorm:"foreignKey:SubjectID"`
 	TimeSlot TimeSlot
 }
+
+// Application statuses. Only pending applications can be reviewed or withdrawn; a pending
+// application older than the expiry window becomes expired.
+const (
+	ApplicationPending   = "pending"
+	ApplicationApproved  = "approved"
+	ApplicationRejected  = "rejected"
+	ApplicationWithdrawn = "withdrawn"
+	ApplicationExpired   = "expired"
+)
+
 type GroupApplication struct {
-	ApplicationID int32     `gorm:"primaryKey;autoIncrement" json:"application_id"`
-	GroupID       int32     `gorm:"not null" json:"group_id"`
-	UserID        int32     `gorm:"not null" json:"user_id"`
-	Message       string    `gorm:"type:text" json:"message"`
-	Status        string    `gorm:"type:varchar(50);default:'pending'" json:"status"` // pending, approved, rejected
-	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-
-	Group Group
-	User  User
+	ApplicationID int32      `gorm:"primaryKey;autoIncrement" json:"application_id"`
+	GroupID       int32      `gorm:"not null" json:"group_id"`
+	UserID        int32      `gorm:"not null" json:"user_id"`
+	Message       string     `gorm:"type:text" json:"message"`
+	Status        string     `gorm:"type:varchar(50);default:'pending'" json:"status"` // pending, approved, rejected, withdrawn, expired
+	InviteID      *int32     `gorm:"index" json:"invite_id,omitempty"`                 // set when the user applied through an invite
+	ReviewedBy    *int32     `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote    string     `gorm:"type:text" json:"review_note,omitempty"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"` // when the application left pending
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Group    Group
+	User     User
+	Reviewer *User `gorm:"foreignKey:ReviewedBy"`
+}
+
+// GroupApprovalRules lets a group approve applications without a moderator: an application
+// matching any enabled rule is approved as soon as it is submitted. Groups without a row
+// approve nothing automatically.
+type GroupApprovalRules struct {
+	GroupID int32 `gorm:"primaryKey"`
+	// SameAcademicGroup approves applicants verified in the group's academic group (User.AcademicGroupID)
+	SameAcademicGroup bool `gorm:"not null;default:false"`
+	// InvitedByMember approves applications made through an invite whose creator is still a member
+	InvitedByMember bool `gorm:"not null;default:false"`
+	UpdatedBy       *int32
+	UpdatedAt       time.Time
+
+	Group Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Auto-approval rule names, stored in the review note of applications they approved
+const (
+	RuleSameAcademicGroup = "same_academic_group"
+	RuleInvitedByMember   = "invited_by_member"
+)
+
+// TelegramLink binds a user account to a Telegram chat
+type TelegramLink struct {
+	ID               int32      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int32      `gorm:"uniqueIndex;not null" json:"user_id"`
+	ChatID           *int64     `gorm:"index" json:"chat_id,omitempty"`
+	LinkCode         *string    `gorm:"type:varchar(16);uniqueIndex" json:"-"`
+	CodeExpiresAt    *time.Time `json:"-"`
+	LinkedAt         *time.Time `json:"linked_at,omitempty"`
+	DigestEnabled    bool       `gorm:"default:true" json:"digest_enabled"`
+	RemindersEnabled bool       `gorm:"default:true" json:"reminders_enabled"`
+	LastDigestAt     *time.Time `json:"last_digest_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// TelegramReminder records that a deadline reminder was already sent
+type TelegramReminder struct {
+	TaskID int32     `gorm:"primaryKey"`
+	UserID int32     `gorm:"primaryKey"`
+	SentAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// CalendarFeed is a secret, revocable iCalendar subscription URL.
+// GroupID == nil means the feed covers all of the user's groups.
+type CalendarFeed struct {
+	ID         int32      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int32      `gorm:"index;not null" json:"user_id"`
+	GroupID    *int32     `gorm:"index" json:"group_id,omitempty"`
+	Token      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	User  User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Group Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ScheduleRule is a recurring lesson that expands into Schedule rows for
+// every matching weekday between SemesterStart and SemesterEnd
+type ScheduleRule struct {
+	ID              int32     `gorm:"primaryKey;autoIncrement"`
+	GroupID         int32     `gorm:"index;not null"`
+	SubjectID       int32     `gorm:"not null"`
+	TimeSlotID      int32     `gorm:"not null"`
+	Weekday         int32     `gorm:"not null;check:weekday BETWEEN 1 AND 7"`  // 1 = Monday
+	WeekParity      string    `gorm:"type:varchar(10);not null;default:'all'"` // all, odd (numerator), even (denominator)
+	SemesterStart   time.Time `gorm:"type:date;not null"`
+	SemesterEnd     time.Time `gorm:"type:date;not null"`
+	TeacherInitials string    `gorm:"type:varchar(50)"`
+	TeacherID       *int32    `gorm:"index"`
+	Classroom       string    `gorm:"type:varchar(50)"`
+	RoomID          *int32    `gorm:"index"`
+	CreatedBy       int32
+	CreatedAt       time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Group      Group                   `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Subject    Subject                 `gorm:"foreignKey:SubjectID"`
+	TimeSlot   TimeSlot                `gorm:"foreignKey:TimeSlotID"`
+	Exceptions []ScheduleRuleException `gorm:"foreignKey:RuleID"`
+}
+
+// ScheduleRuleException cancels a single occurrence of a rule
+type ScheduleRuleException struct {
+	ID        int32     `gorm:"primaryKey;autoIncrement"`
+	RuleID    int32     `gorm:"uniqueIndex:idx_rule_exception_date;not null"`
+	Date      time.Time `gorm:"type:date;uniqueIndex:idx_rule_exception_date;not null"`
+	Reason    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Rule ScheduleRule `gorm:"foreignKey:RuleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Teacher replaces the free-text TeacherInitials of schedules and subjects
+type Teacher struct {
+	ID         int32     `gorm:"primaryKey;autoIncrement"`
+	FullName   string    `gorm:"type:varchar(255)"`
+	Initials   string    `gorm:"type:varchar(50);not null;index"` // e.g. "Иванов И.И."
+	Email      string    `gorm:"type:varchar(255)"`
+	Department string    `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// Room is a bookable classroom; Code is the normalized building+number that
+// free-text Schedule.Classroom values are matched against
+type Room struct {
+	ID        int32     `gorm:"primaryKey;autoIncrement"`
+	Building  string    `gorm:"type:varchar(100)"`
+	Number    string    `gorm:"type:varchar(50);not null"`
+	Code      string    `gorm:"type:varchar(150);not null;uniqueIndex"`
+	Capacity  int32     `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Equipment []RoomEquipment `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// RoomEquipment is one equipment tag of a room, e.g. "projector"
+type RoomEquipment struct {
+	RoomID int32  `gorm:"primaryKey"`
+	Tag    string `gorm:"primaryKey;type:varchar(50)"`
+}
+
+// GroupInvite is a join code for a group. With AutoApprove the user becomes a member right
+// away, otherwise a pending GroupApplication is created for moderators to review.
+type GroupInvite struct {
+	ID          int32      `gorm:"primaryKey;autoIncrement"`
+	GroupID     int32      `gorm:"index;not null"`
+	Code        string     `gorm:"type:varchar(32);uniqueIndex;not null"`
+	CreatedBy   int32      `gorm:"not null"`
+	AutoApprove bool       `gorm:"not null;default:false"`
+	ExpiresAt   *time.Time // nil: never expires
+	MaxUses     *int32     // nil: unlimited
+	UseCount    int32      `gorm:"not null;default:0"`
+	RevokedAt   *time.Time
+	RevokedBy   *int32
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Group   Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Creator User  `gorm:"foreignKey:CreatedBy"`
+}
+
+// GroupInviteUse records one redemption of an invite. Result is joined or applied.
+type GroupInviteUse struct {
+	ID            int32     `gorm:"primaryKey;autoIncrement"`
+	InviteID      int32     `gorm:"index;not null"`
+	UserID        int32     `gorm:"index;not null"`
+	Result        string    `gorm:"type:varchar(20);not null"`
+	ApplicationID *int32    // the pending application created for invites without AutoApprove
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Invite GroupInvite `gorm:"foreignKey:InviteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User   User        `gorm:"foreignKey:UserID"`
+}
+
+// GroupOwnershipTransfer records a change of a group's owner (Group.AdminID). Transfers are
+// proposed by the owner and take effect when the target accepts; successions happen
+// automatically when the owner deletes their account.
+type GroupOwnershipTransfer struct {
+	ID          int32     `gorm:"primaryKey;autoIncrement"`
+	GroupID     int32     `gorm:"index;not null"`
+	FromUserID  int32     `gorm:"not null"`
+	ToUserID    int32     `gorm:"index;not null"`
+	Reason      string    `gorm:"type:varchar(20);not null;default:'transfer'"` // transfer, succession
+	Status      string    `gorm:"type:varchar(20);not null;default:'pending'"`  // pending, accepted, declined, cancelled
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	RespondedAt *time.Time
+
+	Group    Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FromUser User  `gorm:"foreignKey:FromUserID"`
+	ToUser   User  `gorm:"foreignKey:ToUserID"`
+}
+
+// GroupBan keeps a user out of a group until it is lifted: they can't apply or redeem invites
+type GroupBan struct {
+	ID        int32     `gorm:"primaryKey;autoIncrement"`
+	GroupID   int32     `gorm:"index;not null"`
+	UserID    int32     `gorm:"index;not null"`
+	Reason    string    `gorm:"type:text"`
+	BannedBy  int32     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	LiftedAt  *time.Time
+	LiftedBy  *int32
+
+	Group     Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User      User  `gorm:"foreignKey:UserID"`
+	Moderator User  `gorm:"foreignKey:BannedBy"`
+}
+
+// GroupMembershipEvent records a member leaving or being removed, bans being issued or lifted
+// and moderator rights being granted or revoked. ActorID is who did it; it equals UserID when
+// a member left on their own.
+type GroupMembershipEvent struct {
+	ID        int32     `gorm:"primaryKey;autoIncrement"`
+	GroupID   int32     `gorm:"index;not null"`
+	UserID    int32     `gorm:"not null"`
+	ActorID   int32     `gorm:"not null"`
+	Action    string    `gorm:"type:varchar(20);not null"` // left, kicked, banned, unbanned, promoted, demoted
+	Reason    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Group Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User  User  `gorm:"foreignKey:UserID"`
+	Actor User  `gorm:"foreignKey:ActorID"`
+}
+
+// AuditEvent is a moderation action taken in a group. GroupID has no foreign key so the trail
+// outlives the group; Before and After hold JSON snapshots of the changed fields.
+type AuditEvent struct {
+	ID         int32     `gorm:"primaryKey;autoIncrement"`
+	GroupID    int32     `gorm:"index:idx_audit_group_created,priority:1;not null"`
+	ActorID    int32     `gorm:"index;not null"`
+	Action     string    `gorm:"type:varchar(50);index;not null"` // one of the Audit* constants
+	TargetType string    `gorm:"type:varchar(30);not null"`       // task, user, group
+	TargetID   int32     `gorm:"not null"`
+	Before     *string   `gorm:"type:jsonb"`
+	After      *string   `gorm:"type:jsonb"`
+	CreatedAt  time.Time `gorm:"index:idx_audit_group_created,priority:2;default:CURRENT_TIMESTAMP"`
+
+	Actor User `gorm:"foreignKey:ActorID"`
+}
+
+const (
+	AuditTaskVerify             = "task.verify"
+	AuditApplicationReview      = "application.review"
+	AuditModeratorGrant         = "moderator.grant"
+	AuditModeratorRevoke        = "moderator.revoke"
+	AuditMemberKick             = "member.kick"
+	AuditMemberBan              = "member.ban"
+	AuditMemberUnban            = "member.unban"
+	AuditGroupDelete            = "group.delete"
+	AuditVerificationPolicy     = "verification.policy"
+	AuditApplicationAutoApprove = "application.auto_approve"
+	AuditApprovalRules          = "approval.rules"
+	AuditRosterImport           = "roster.import"
+)
+
+const (
+	TaskPending  = "pending"
+	TaskVerified = "verified"
+	TaskRejected = "rejected"
+
+	// VerifyByModerator lets any admin or moderator decide alone, VerifyByVote lets the
+	// group vote until the quorum is reached
+	VerifyByModerator = "moderator"
+	VerifyByVote      = "vote"
+	// VerifyByReputation marks tasks verified on creation because their author's reputation
+	// is high enough; votes and moderators can still reject them
+	VerifyByReputation = "reputation"
+)
+
+// GroupVerificationPolicy decides how the group's tasks get verified. Groups without a row
+// use DefaultVerificationPolicy.
+type GroupVerificationPolicy struct {
+	GroupID   int32  `gorm:"primaryKey"`
+	Mode      string `gorm:"type:varchar(20);not null;default:moderator"`
+	Voters    string `gorm:"type:varchar(20);not null;default:moderators"` // moderators or members
+	Quorum    int    `gorm:"not null;default:3"`                           // votes needed before a decision
+	Threshold int    `gorm:"not null;default:60"`                          // percent of legit votes to verify
+	// AutoVerifyReputation verifies new tasks from authors with at least this reputation; 0 is off
+	AutoVerifyReputation int `gorm:"not null;default:0"`
+	UpdatedBy            *int32
+	UpdatedAt            time.Time
+
+	Group Group `gorm:"foreignKey:GroupID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func DefaultVerificationPolicy(groupID int32) GroupVerificationPolicy {
+	return GroupVerificationPolicy{GroupID: groupID, Mode: VerifyByModerator, Voters: "moderators", Quorum: 3, Threshold: 60}
+}
+
+// TaskVote is one user's opinion on a task; voting again replaces it
+type TaskVote struct {
+	TaskID    int32  `gorm:"primaryKey"`
+	UserID    int32  `gorm:"primaryKey"`
+	Vote      string `gorm:"type:varchar(10);not null"` // legit or fake
+	Reason    string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Task Task `gorm:"foreignKey:TaskID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// TaskVerificationDecision is one change of a task's verification status. DecidedBy is the
+// moderator for Method moderator and the voter whose vote settled it for Method vote; Legit
+// and Fake are the tally at that moment.
+type TaskVerificationDecision struct {
+	ID             int32     `gorm:"primaryKey;autoIncrement"`
+	TaskID         int32     `gorm:"index;not null"`
+	PreviousStatus string    `gorm:"type:varchar(20);not null"`
+	Status         string    `gorm:"type:varchar(20);not null"`
+	Method         string    `gorm:"type:varchar(20);not null"`
+	DecidedBy      int32     `gorm:"not null"`
+	Legit          int       `gorm:"not null;default:0"`
+	Fake           int       `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+
+	Task    Task `gorm:"foreignKey:TaskID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Decider User `gorm:"foreignKey:DecidedBy"`
+}
+
+// SeedHistory records each seed file applied to the database. A file is applied again only
+// when its checksum changes.
+type SeedHistory struct {
+	ID        int32     `gorm:"primaryKey;autoIncrement"`
+	Version   int       `gorm:"index;not null"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	Checksum  string    `gorm:"type:varchar(64);not null"`
+	Rows      int       `gorm:"not null;default:0"` // rows created
+	AppliedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (SeedHistory) TableName() string {
+	return "seed_history"
 }
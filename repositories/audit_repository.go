@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows a group's audit trail; zero values match everything. To is exclusive.
+type AuditFilter struct {
+	Action     string
+	ActorID    int32
+	TargetType string
+	TargetID   int32
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db}
+}
+
+func (r *AuditRepository) Create(event *models.AuditEvent) error {
+	return r.db.Omit("Actor").Create(event).Error
+}
+
+// DeletedBy returns who deleted the group, when its group.delete event exists
+func (r *AuditRepository) DeletedBy(groupID int32) (int32, bool, error) {
+	var actorIDs []int32
+	err := r.db.Model(&models.AuditEvent{}).
+		Where("group_id = ? AND action = ?", groupID, models.AuditGroupDelete).
+		Order("created_at DESC").Limit(1).
+		Pluck("actor_id", &actorIDs).Error
+	if err != nil || len(actorIDs) == 0 {
+		return 0, false, err
+	}
+	return actorIDs[0], true, nil
+}
+
+func (r *AuditRepository) filtered(groupID int32, filter AuditFilter) *gorm.DB {
+	query := r.db.Model(&models.AuditEvent{}).Where("group_id = ?", groupID)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// Find returns a page of the group's audit events, newest first; limit <= 0 returns them all
+func (r *AuditRepository) Find(groupID int32, filter AuditFilter, limit, offset int) ([]models.AuditEvent, int64, error) {
+	var events []models.AuditEvent
+	var total int64
+	if err := r.filtered(groupID, filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query := r.filtered(groupID, filter).Preload("Actor").Order("created_at DESC").Order("id DESC")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch audit events")
+		return nil, 0, err
+	}
+	return events, total, nil
+}
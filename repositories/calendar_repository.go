@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"space/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db}
+}
+
+func (r *CalendarRepository) Create(feed *models.CalendarFeed) error {
+	return r.db.Create(feed).Error
+}
+
+func (r *CalendarRepository) GetByToken(token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.Preload("User").First(&feed, "token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *CalendarRepository) FindByUserID(userID int32) ([]models.CalendarFeed, error) {
+	var feeds []models.CalendarFeed
+	err := r.db.Preload("Group").Where("user_id = ?", userID).Order("created_at").Find(&feeds).Error
+	return feeds, err
+}
+
+// Delete removes a feed owned by the user, returning gorm.ErrRecordNotFound if there is none
+func (r *CalendarRepository) Delete(id, userID int32) error {
+	result := r.db.Delete(&models.CalendarFeed{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *CalendarRepository) TouchLastUsed(id int32) error {
+	return r.db.Model(&models.CalendarFeed{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
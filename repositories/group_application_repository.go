@@ -1,13 +1,18 @@
 package repositories
 
 import (
+	"errors"
 	"space/models"
 	"space/utils"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrApplicationNotPending = errors.New("application is not pending")
+
 type GroupApplicationRepository struct {
 	db *gorm.DB
 }
@@ -20,11 +25,14 @@ func (r *GroupApplicationRepository) Create(app *models.GroupApplication) error
 	return r.db.Create(app).Error
 }
 
-func (r *GroupApplicationRepository) GetPendingByGroup(groupID int32) ([]models.GroupApplication, error) {
+// GetPendingByGroup lists the group's pending applications created after the expiry cutoff
+func (r *GroupApplicationRepository) GetPendingByGroup(groupID int32, cutoff time.Time) ([]models.GroupApplication, error) {
 	var apps []models.GroupApplication
 	err := r.db.
+		Preload("Group").
 		Preload("User").
-		Where("group_id = ? AND status = ?", groupID, "pending").
+		Where("group_id = ? AND status = ? AND created_at >= ?", groupID, models.ApplicationPending, cutoff).
+		Order("created_at").
 		Find(&apps).Error
 	return apps, err
 }
@@ -38,23 +46,221 @@ func (r *GroupApplicationRepository) UpdateStatus(appID int32, status string) er
 func (r *GroupApplicationRepository) ExistsPending(groupID, userID int32) (bool, error) {
 	var count int64
 	err := r.db.Model(&models.GroupApplication{}).
-		Where("group_id = ? AND user_id = ? AND status = ?", groupID, userID, "pending").
+		Where("group_id = ? AND user_id = ? AND status = ?", groupID, userID, models.ApplicationPending).
 		Count(&count).Error
 	return count > 0, err
 }
-func (r *GroupApplicationRepository) GetByGroupAndUser(groupID, userID int32) (*models.GroupApplication, error) {
+func (r *GroupApplicationRepository) GetByID(appID int32) (*models.GroupApplication, error) {
 	var app models.GroupApplication
-	err := r.db.First(&app, "group_id = ? AND user_id = ?", groupID, userID).Error
+	err := r.db.Preload("Group").Preload("User").Preload("Reviewer").
+		First(&app, "application_id = ?", appID).Error
 	if err != nil {
 		return nil, err
 	}
 	return &app, nil
 }
 
-func (r *GroupApplicationRepository) UpdateStatusByGroupAndUser(groupID, userID int32, status string) error {
-	return r.db.Model(&models.GroupApplication{}).
-		Where("group_id = ? AND user_id = ?", groupID, userID).
-		Update("status", status).Error
+// FindByUser lists every application the user has submitted, newest first
+func (r *GroupApplicationRepository) FindByUser(userID int32) ([]models.GroupApplication, error) {
+	var apps []models.GroupApplication
+	err := r.db.
+		Preload("Group").
+		Preload("User").
+		Preload("Reviewer").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Order("application_id DESC").
+		Find(&apps).Error
+	return apps, err
+}
+
+func (r *GroupApplicationRepository) FindByIDs(appIDs []int32) ([]models.GroupApplication, error) {
+	var apps []models.GroupApplication
+	err := r.db.Preload("Group").Preload("User").
+		Where("application_id IN ?", appIDs).
+		Find(&apps).Error
+	return apps, err
+}
+
+// review closes a pending application in the caller's transaction and, on approval, adds the
+// applicant to the group. reviewerID is nil when an auto-approval rule decided.
+func review(tx *gorm.DB, app *models.GroupApplication, status string, reviewerID *int32, note string) error {
+	var current models.GroupApplication
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&current, "application_id = ?", app.ApplicationID).Error; err != nil {
+		return err
+	}
+	if current.Status != models.ApplicationPending {
+		return ErrApplicationNotPending
+	}
+	now := time.Now()
+	if err := tx.Model(&current).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewerID,
+		"reviewed_at": now,
+		"review_note": note,
+		"closed_at":   now,
+	}).Error; err != nil {
+		return err
+	}
+	if status == models.ApplicationApproved {
+		if err := tx.Omit("Group", "User").Clauses(clause.OnConflict{DoNothing: true}).Create(&models.GroupUser{
+			GroupID: current.GroupID,
+			UserID:  current.UserID,
+			Role:    "member",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	app.Status = status
+	app.ReviewedBy = reviewerID
+	app.ReviewedAt = &now
+	app.ReviewNote = note
+	app.ClosedAt = &now
+	return nil
+}
+
+// closePending closes the pending applications matching the query in the caller's transaction
+// for paths that decide outside review(). reviewerID is who acted, nil when nobody did.
+func closePending(tx *gorm.DB, status string, reviewerID *int32, note string, query string, args ...interface{}) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"review_note": note,
+		"closed_at":   now,
+	}
+	if reviewerID != nil {
+		updates["reviewed_by"] = *reviewerID
+		updates["reviewed_at"] = now
+	}
+	return tx.Model(&models.GroupApplication{}).
+		Where("status = ?", models.ApplicationPending).
+		Where(query, args...).
+		Updates(updates).Error
+}
+
+// Review closes a pending application with the reviewer's decision and, on approval, adds
+// the applicant to the group in the same transaction
+func (r *GroupApplicationRepository) Review(app *models.GroupApplication, status string, reviewerID int32, note string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return review(tx, app, status, &reviewerID, note)
+	})
+}
+
+// BulkReview applies one decision to several applications in a single transaction. Each
+// application is reviewed under its own savepoint, so one that fails (e.g. is no longer
+// pending) is reported in the returned map without undoing the others.
+func (r *GroupApplicationRepository) BulkReview(apps []*models.GroupApplication, status string, reviewerID int32, note string) (map[int32]error, error) {
+	failed := make(map[int32]error)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, app := range apps {
+			if err := tx.Transaction(func(item *gorm.DB) error {
+				return review(item, app, status, &reviewerID, note)
+			}); err != nil {
+				failed[app.ApplicationID] = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"reviewer_id": reviewerID,
+			"count":       len(apps),
+		}).Error("Failed to review applications in bulk")
+		return nil, err
+	}
+	return failed, nil
+}
+
+// AutoApprove approves a pending application on behalf of the group's rule; the rule is kept
+// as the review note
+func (r *GroupApplicationRepository) AutoApprove(app *models.GroupApplication, rule string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return review(tx, app, models.ApplicationApproved, nil, "auto-approved: "+rule)
+	})
+}
+
+// InvitedByMember reports whether the application came through an invite whose creator is
+// still a member of the group
+func (r *GroupApplicationRepository) InvitedByMember(app *models.GroupApplication) (bool, error) {
+	if app.InviteID == nil {
+		return false, nil
+	}
+	var count int64
+	err := r.db.Model(&models.GroupInvite{}).
+		Joins("JOIN group_users ON group_users.group_id = group_invites.group_id AND group_users.user_id = group_invites.created_by").
+		Where("group_invites.id = ? AND group_invites.group_id = ?", *app.InviteID, app.GroupID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetRules returns the group's auto-approval rules; a group without a row has none enabled
+func (r *GroupApplicationRepository) GetRules(groupID int32) (*models.GroupApprovalRules, error) {
+	var rules models.GroupApprovalRules
+	err := r.db.First(&rules, "group_id = ?", groupID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.GroupApprovalRules{GroupID: groupID}, nil
+	}
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch approval rules")
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r *GroupApplicationRepository) SaveRules(rules *models.GroupApprovalRules) error {
+	rules.UpdatedAt = time.Now()
+	return r.db.Omit("Group").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"same_academic_group", "invited_by_member", "updated_by", "updated_at"}),
+	}).Create(rules).Error
+}
+
+// Withdraw lets the applicant take back a pending application
+func (r *GroupApplicationRepository) Withdraw(appID int32) error {
+	result := r.db.Model(&models.GroupApplication{}).
+		Where("application_id = ? AND status = ?", appID, models.ApplicationPending).
+		Updates(map[string]interface{}{
+			"status":    models.ApplicationWithdrawn,
+			"closed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrApplicationNotPending
+	}
+	return nil
+}
+
+// ExpireStale marks pending applications created before the cutoff as expired and reports
+// how many were closed
+func (r *GroupApplicationRepository) ExpireStale(cutoff time.Time) (int64, error) {
+	result := r.db.Model(&models.GroupApplication{}).
+		Where("status = ? AND created_at < ?", models.ApplicationPending, cutoff).
+		Updates(map[string]interface{}{
+			"status":    models.ApplicationExpired,
+			"closed_at": time.Now(),
+		})
+	if result.Error != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":  result.Error,
+			"cutoff": cutoff,
+		}).Error("Failed to expire stale applications")
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ExpireStaleOf expires the user's stale pending application to the group, if any, so a new
+// one can be submitted without waiting for the hourly sweep
+func (r *GroupApplicationRepository) ExpireStaleOf(groupID, userID int32, cutoff time.Time) error {
+	return closePending(r.db, models.ApplicationExpired, nil, "",
+		"group_id = ? AND user_id = ? AND created_at < ?", groupID, userID, cutoff)
 }
 
 func (r *AcademicGroupRepository) FindAll() ([]*models.AcademicGroup, error) {
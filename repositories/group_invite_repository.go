@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"errors"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInviteRevoked   = errors.New("invite has been revoked")
+	ErrInviteExpired   = errors.New("invite has expired")
+	ErrInviteExhausted = errors.New("invite has reached its usage limit")
+	ErrAlreadyMember   = errors.New("user is already a group member")
+	ErrPendingExists   = errors.New("application already submitted and pending")
+)
+
+type GroupInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupInviteRepository(db *gorm.DB) *GroupInviteRepository {
+	return &GroupInviteRepository{db}
+}
+
+func (r *GroupInviteRepository) Create(invite *models.GroupInvite) error {
+	if err := r.db.Omit("Group", "Creator").Create(invite).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": invite.GroupID,
+		}).Error("Failed to create group invite")
+		return err
+	}
+	return nil
+}
+
+func (r *GroupInviteRepository) GetByID(groupID, id int32) (*models.GroupInvite, error) {
+	var invite models.GroupInvite
+	if err := r.db.Preload("Group").Preload("Creator").First(&invite, "id = ? AND group_id = ?", id, groupID).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *GroupInviteRepository) GetByCode(code string) (*models.GroupInvite, error) {
+	var invite models.GroupInvite
+	if err := r.db.Preload("Group").Preload("Creator").First(&invite, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// FindByGroup lists the group's invites, newest first
+func (r *GroupInviteRepository) FindByGroup(groupID int32) ([]models.GroupInvite, error) {
+	var invites []models.GroupInvite
+	err := r.db.Preload("Group").Preload("Creator").
+		Where("group_id = ?", groupID).
+		Order("created_at DESC").Order("id DESC").
+		Find(&invites).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch group invites")
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *GroupInviteRepository) Revoke(invite *models.GroupInvite, revokedBy int32) error {
+	now := time.Now()
+	invite.RevokedAt = &now
+	invite.RevokedBy = &revokedBy
+	return r.db.Model(invite).Select("revoked_at", "revoked_by").Updates(map[string]interface{}{
+		"revoked_at": now,
+		"revoked_by": revokedBy,
+	}).Error
+}
+
+// FindUses lists who redeemed the invite, newest first
+func (r *GroupInviteRepository) FindUses(inviteID int32) ([]models.GroupInviteUse, error) {
+	var uses []models.GroupInviteUse
+	err := r.db.Preload("User").
+		Where("invite_id = ?", inviteID).
+		Order("created_at DESC").Order("id DESC").
+		Find(&uses).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"invite_id": inviteID,
+		}).Error("Failed to fetch invite uses")
+		return nil, err
+	}
+	return uses, nil
+}
+
+// CheckUsable returns why the invite can't be used at the moment, or nil
+func CheckUsable(invite *models.GroupInvite, now time.Time) error {
+	switch {
+	case invite.RevokedAt != nil:
+		return ErrInviteRevoked
+	case invite.ExpiresAt != nil && !now.Before(*invite.ExpiresAt):
+		return ErrInviteExpired
+	case invite.MaxUses != nil && invite.UseCount >= *invite.MaxUses:
+		return ErrInviteExhausted
+	}
+	return nil
+}
+
+// Redeem uses the invite for the user. The invite row is locked, so concurrent redemptions
+// can't exceed MaxUses. With AutoApprove the user joins the group and a pending application of
+// theirs is approved; otherwise a pending application is created. The use is recorded either way.
+func (r *GroupInviteRepository) Redeem(code string, userID int32, message string) (*models.GroupInviteUse, error) {
+	var use models.GroupInviteUse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var invite models.GroupInvite
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invite, "code = ?", code).Error; err != nil {
+			return err
+		}
+		if err := CheckUsable(&invite, time.Now()); err != nil {
+			return err
+		}
+
+		var bans int64
+		if err := activeBan(tx, invite.GroupID, userID).Count(&bans).Error; err != nil {
+			return err
+		}
+		if bans > 0 {
+			return ErrBanned
+		}
+
+		var members int64
+		if err := tx.Model(&models.GroupUser{}).
+			Where("group_id = ? AND user_id = ?", invite.GroupID, userID).
+			Count(&members).Error; err != nil {
+			return err
+		}
+		if members > 0 {
+			return ErrAlreadyMember
+		}
+		var pending models.GroupApplication
+		err := tx.Where("group_id = ? AND user_id = ? AND status = ?", invite.GroupID, userID, models.ApplicationPending).
+			First(&pending).Error
+		hasPending := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		use = models.GroupInviteUse{InviteID: invite.ID, UserID: userID}
+		if invite.AutoApprove {
+			if err := tx.Omit("Group", "User").Create(&models.GroupUser{
+				GroupID: invite.GroupID,
+				UserID:  userID,
+				Role:    "member",
+			}).Error; err != nil {
+				return err
+			}
+			if hasPending {
+				if err := closePending(tx, models.ApplicationApproved, &invite.CreatedBy, "approved by invite",
+					"application_id = ?", pending.ApplicationID); err != nil {
+					return err
+				}
+			}
+			use.Result = "joined"
+		} else {
+			if hasPending {
+				return ErrPendingExists
+			}
+			app := models.GroupApplication{
+				GroupID:  invite.GroupID,
+				UserID:   userID,
+				Message:  message,
+				Status:   models.ApplicationPending,
+				InviteID: &invite.ID,
+			}
+			if err := tx.Omit("Group", "User").Create(&app).Error; err != nil {
+				return err
+			}
+			use.Result = "applied"
+			use.ApplicationID = &app.ApplicationID
+		}
+
+		if err := tx.Model(&invite).Update("use_count", gorm.Expr("use_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Invite", "User").Create(&use).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &use, nil
+}
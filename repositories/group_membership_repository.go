@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"errors"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBanned        = errors.New("user is banned from this group")
+	ErrNotMember     = errors.New("user is not a group member")
+	ErrAlreadyBanned = errors.New("user is already banned")
+)
+
+type GroupMembershipRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupMembershipRepository(db *gorm.DB) *GroupMembershipRepository {
+	return &GroupMembershipRepository{db}
+}
+
+// activeBan selects the user's ban in the group that hasn't been lifted
+func activeBan(db *gorm.DB, groupID, userID int32) *gorm.DB {
+	return db.Model(&models.GroupBan{}).
+		Where("group_id = ? AND user_id = ? AND lifted_at IS NULL", groupID, userID)
+}
+
+func (r *GroupMembershipRepository) IsBanned(groupID, userID int32) (bool, error) {
+	var count int64
+	if err := activeBan(r.db, groupID, userID).Count(&count).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"user_id":  userID,
+		}).Error("Failed to check group ban")
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GroupMembershipRepository) GetActiveBan(groupID, userID int32) (*models.GroupBan, error) {
+	var ban models.GroupBan
+	if err := activeBan(r.db, groupID, userID).First(&ban).Error; err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// FindBans lists the group's bans, newest first; lifted ones only with includeLifted
+func (r *GroupMembershipRepository) FindBans(groupID int32, includeLifted bool) ([]models.GroupBan, error) {
+	var bans []models.GroupBan
+	query := r.db.Preload("User").Preload("Moderator").Where("group_id = ?", groupID)
+	if !includeLifted {
+		query = query.Where("lifted_at IS NULL")
+	}
+	if err := query.Order("created_at DESC").Order("id DESC").Find(&bans).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch group bans")
+		return nil, err
+	}
+	return bans, nil
+}
+
+// FindEvents lists the group's membership events, newest first
+func (r *GroupMembershipRepository) FindEvents(groupID int32, limit, offset int) ([]models.GroupMembershipEvent, int64, error) {
+	var events []models.GroupMembershipEvent
+	var total int64
+	query := r.db.Model(&models.GroupMembershipEvent{}).Where("group_id = ?", groupID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("User").Preload("Actor").
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&events).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch membership events")
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// removeMember drops the user's membership and moderator rights in the caller's transaction
+func removeMember(tx *gorm.DB, groupID, userID int32) (bool, error) {
+	result := tx.Delete(&models.GroupUser{}, "group_id = ? AND user_id = ?", groupID, userID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if err := tx.Delete(&models.GroupModer{}, "group_id = ? AND user_id = ?", groupID, userID).Error; err != nil {
+		return false, err
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func recordEvent(tx *gorm.DB, event *models.GroupMembershipEvent) error {
+	return tx.Omit("Group", "User", "Actor").Create(event).Error
+}
+
+// Remove takes the user out of the group and records why: event.Action is left or kicked
+func (r *GroupMembershipRepository) Remove(event *models.GroupMembershipEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		removed, err := removeMember(tx, event.GroupID, event.UserID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrNotMember
+		}
+		return recordEvent(tx, event)
+	})
+}
+
+// Ban removes the user from the group if they are a member, rejects their pending application
+// and keeps them from joining again until the ban is lifted
+func (r *GroupMembershipRepository) Ban(ban *models.GroupBan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := activeBan(tx, ban.GroupID, ban.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyBanned
+		}
+		if err := tx.Omit("Group", "User", "Moderator").Create(ban).Error; err != nil {
+			return err
+		}
+		if _, err := removeMember(tx, ban.GroupID, ban.UserID); err != nil {
+			return err
+		}
+		if err := closePending(tx, models.ApplicationRejected, &ban.BannedBy, "rejected: user banned",
+			"group_id = ? AND user_id = ?", ban.GroupID, ban.UserID); err != nil {
+			return err
+		}
+		return recordEvent(tx, &models.GroupMembershipEvent{
+			GroupID: ban.GroupID,
+			UserID:  ban.UserID,
+			ActorID: ban.BannedBy,
+			Action:  "banned",
+			Reason:  ban.Reason,
+		})
+	})
+}
+
+// Lift ends the ban; the user may apply again but isn't re-added to the group
+func (r *GroupMembershipRepository) Lift(ban *models.GroupBan, liftedBy int32, reason string) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(ban).Updates(map[string]interface{}{
+			"lifted_at": now,
+			"lifted_by": liftedBy,
+		}).Error; err != nil {
+			return err
+		}
+		ban.LiftedAt = &now
+		ban.LiftedBy = &liftedBy
+		return recordEvent(tx, &models.GroupMembershipEvent{
+			GroupID: ban.GroupID,
+			UserID:  ban.UserID,
+			ActorID: liftedBy,
+			Action:  "unbanned",
+			Reason:  reason,
+		})
+	})
+}
+
+// SetRole makes a member a moderator or a plain member, keeping group_users.role and the
+// group_moders row in step, and records the change. It reports whether the role changed.
+func (r *GroupMembershipRepository) SetRole(groupID, userID int32, role string, actorID int32) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var member models.GroupUser
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&member, "group_id = ? AND user_id = ?", groupID, userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotMember
+		}
+		if err != nil {
+			return err
+		}
+		var moderators int64
+		if err := tx.Model(&models.GroupModer{}).
+			Where("group_id = ? AND user_id = ?", groupID, userID).
+			Count(&moderators).Error; err != nil {
+			return err
+		}
+		if (moderators > 0) == (role == "moderator") && member.Role == role {
+			return nil
+		}
+		changed = true
+
+		if err := tx.Model(&member).Update("role", role).Error; err != nil {
+			return err
+		}
+		action := "demoted"
+		if role == "moderator" {
+			action = "promoted"
+			err = tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Group", "User").
+				Create(&models.GroupModer{GroupID: groupID, UserID: userID, GrantedBy: &actorID}).Error
+		} else {
+			err = tx.Delete(&models.GroupModer{}, "group_id = ? AND user_id = ?", groupID, userID).Error
+		}
+		if err != nil {
+			return err
+		}
+		return recordEvent(tx, &models.GroupMembershipEvent{
+			GroupID: groupID,
+			UserID:  userID,
+			ActorID: actorID,
+			Action:  action,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferNotPending = errors.New("transfer is no longer pending")
+	ErrOwnerChanged       = errors.New("group owner has changed since the transfer was proposed")
+	ErrTargetNotMember    = errors.New("new owner must be a group member")
+)
+
+type GroupOwnershipRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupOwnershipRepository(db *gorm.DB) *GroupOwnershipRepository {
+	return &GroupOwnershipRepository{db}
+}
+
+func withTransferUsers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Group").Preload("FromUser").Preload("ToUser")
+}
+
+// Propose stores a pending transfer, cancelling any earlier pending one of the group
+func (r *GroupOwnershipRepository) Propose(transfer *models.GroupOwnershipTransfer) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GroupOwnershipTransfer{}).
+			Where("group_id = ? AND status = ?", transfer.GroupID, "pending").
+			Updates(map[string]interface{}{"status": "cancelled", "responded_at": now}).Error; err != nil {
+			return err
+		}
+		transfer.Reason = "transfer"
+		transfer.Status = "pending"
+		return tx.Omit("Group", "FromUser", "ToUser").Create(transfer).Error
+	})
+}
+
+func (r *GroupOwnershipRepository) GetPending(groupID int32) (*models.GroupOwnershipTransfer, error) {
+	var transfer models.GroupOwnershipTransfer
+	if err := withTransferUsers(r.db).First(&transfer, "group_id = ? AND status = ?", groupID, "pending").Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FindByGroup lists the group's transfers and successions, newest first
+func (r *GroupOwnershipRepository) FindByGroup(groupID int32) ([]models.GroupOwnershipTransfer, error) {
+	var transfers []models.GroupOwnershipTransfer
+	err := withTransferUsers(r.db).
+		Where("group_id = ?", groupID).
+		Order("created_at DESC").Order("id DESC").
+		Find(&transfers).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch ownership transfers")
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// FindPendingForUser lists transfers waiting for the user to accept them
+func (r *GroupOwnershipRepository) FindPendingForUser(userID int32) ([]models.GroupOwnershipTransfer, error) {
+	var transfers []models.GroupOwnershipTransfer
+	err := withTransferUsers(r.db).
+		Where("to_user_id = ? AND status = ?", userID, "pending").
+		Order("created_at DESC").
+		Find(&transfers).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to fetch incoming ownership transfers")
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// Close marks a pending transfer declined or cancelled
+func (r *GroupOwnershipRepository) Close(transfer *models.GroupOwnershipTransfer, status string) error {
+	now := time.Now()
+	result := r.db.Model(&models.GroupOwnershipTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, "pending").
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTransferNotPending
+	}
+	transfer.Status = status
+	transfer.RespondedAt = &now
+	return nil
+}
+
+// Accept makes the transfer's target the owner. The group row is locked and the transfer only
+// applies while its proposer still owns the group and the target is still a member.
+func (r *GroupOwnershipRepository) Accept(transfer *models.GroupOwnershipTransfer) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, "id = ?", transfer.GroupID).Error; err != nil {
+			return err
+		}
+		if group.AdminID != transfer.FromUserID {
+			return ErrOwnerChanged
+		}
+		result := tx.Model(&models.GroupOwnershipTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, "pending").
+			Updates(map[string]interface{}{"status": "accepted", "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferNotPending
+		}
+		if err := changeOwner(tx, &group, transfer.ToUserID, true); err != nil {
+			return err
+		}
+		transfer.Status = "accepted"
+		transfer.RespondedAt = &now
+		return nil
+	})
+}
+
+// AssignOwner makes the user the group's owner without a transfer, adding them as a member
+// first when needed. The previous owner stays on as a moderator. Used by operators through
+// the create-admin command.
+func (r *GroupOwnershipRepository) AssignOwner(groupID, userID int32) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, "id = ?", groupID).Error; err != nil {
+			return err
+		}
+		if group.AdminID == userID {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Omit("Group", "User").
+			Create(&models.GroupUser{GroupID: groupID, UserID: userID, Role: "member", JoinedAt: time.Now()}).Error; err != nil {
+			return err
+		}
+		return changeOwner(tx, &group, userID, group.AdminID != 0)
+	})
+}
+
+// changeOwner sets Group.AdminID and the matching group_users roles in the caller's transaction.
+// The new owner stops being a moderator since the owner already has every right; with
+// keepPrevious the previous owner stays on as a moderator.
+func changeOwner(tx *gorm.DB, group *models.Group, newOwnerID int32, keepPrevious bool) error {
+	previousID := group.AdminID
+	result := tx.Model(&models.GroupUser{}).
+		Where("group_id = ? AND user_id = ?", group.ID, newOwnerID).
+		Update("role", "admin")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTargetNotMember
+	}
+	if err := tx.Model(group).Update("admin_id", newOwnerID).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.GroupModer{}, "group_id = ? AND user_id = ?", group.ID, newOwnerID).Error; err != nil {
+		return err
+	}
+	if !keepPrevious {
+		return nil
+	}
+	if err := tx.Model(&models.GroupUser{}).
+		Where("group_id = ? AND user_id = ?", group.ID, previousID).
+		Update("role", "moderator").Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Omit("Group", "User").
+		Create(&models.GroupModer{GroupID: group.ID, UserID: previousID, GrantedBy: &previousID, CreatedAt: time.Now()}).Error
+}
+
+// Succession is the outcome for one group owned by a deleted account: the new owner, or
+// Deleted when nobody was left to take it over
+type Succession struct {
+	GroupID    int32
+	NewOwnerID int32
+	Deleted    bool
+}
+
+// successor picks the longest-serving moderator who is still a member, falling back to the
+// longest-standing member
+func successor(tx *gorm.DB, groupID, ownerID int32) (int32, bool, error) {
+	var ids []int32
+	err := tx.Model(&models.GroupModer{}).
+		Joins("JOIN group_users ON group_users.group_id = group_moders.group_id AND group_users.user_id = group_moders.user_id").
+		Where("group_moders.group_id = ? AND group_moders.user_id <> ?", groupID, ownerID).
+		Order("group_moders.created_at").Order("group_users.joined_at").Order("group_moders.user_id").
+		Limit(1).
+		Pluck("group_moders.user_id", &ids).Error
+	if err != nil {
+		return 0, false, err
+	}
+	if len(ids) == 0 {
+		err = tx.Model(&models.GroupUser{}).
+			Where("group_id = ? AND user_id <> ?", groupID, ownerID).
+			Order("joined_at").Order("user_id").
+			Limit(1).
+			Pluck("user_id", &ids).Error
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	if len(ids) == 0 {
+		return 0, false, nil
+	}
+	return ids[0], true, nil
+}
+
+// DeleteAccount hands the user's groups over to their successors, drops their memberships,
+// moderator rights, pending applications and transfers, feeds and telegram link, and
+// anonymizes the user row, all in one transaction. The row itself stays so tasks and
+// materials they authored keep a valid author. Groups left without members are deleted.
+func (r *GroupOwnershipRepository) DeleteAccount(user *models.User) ([]Succession, error) {
+	var successions []Succession
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var groups []models.Group
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("admin_id = ?", user.UserID).
+			Order("id").
+			Find(&groups).Error; err != nil {
+			return err
+		}
+		for i := range groups {
+			group := &groups[i]
+			newOwnerID, found, err := successor(tx, group.ID, user.UserID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				if err := tx.Delete(&models.Group{}, "id = ?", group.ID).Error; err != nil {
+					return err
+				}
+				successions = append(successions, Succession{GroupID: group.ID, Deleted: true})
+				continue
+			}
+			if err := changeOwner(tx, group, newOwnerID, false); err != nil {
+				return err
+			}
+			if err := tx.Omit("Group", "FromUser", "ToUser").Create(&models.GroupOwnershipTransfer{
+				GroupID:     group.ID,
+				FromUserID:  user.UserID,
+				ToUserID:    newOwnerID,
+				Reason:      "succession",
+				Status:      "accepted",
+				RespondedAt: &now,
+			}).Error; err != nil {
+				return err
+			}
+			successions = append(successions, Succession{GroupID: group.ID, NewOwnerID: newOwnerID})
+		}
+
+		if err := tx.Model(&models.GroupOwnershipTransfer{}).
+			Where("status = ? AND (from_user_id = ? OR to_user_id = ?)", "pending", user.UserID, user.UserID).
+			Updates(map[string]interface{}{"status": "cancelled", "responded_at": now}).Error; err != nil {
+			return err
+		}
+		cleanup := []struct {
+			model interface{}
+			query string
+		}{
+			{&models.GroupModer{}, "user_id = ?"},
+			{&models.GroupUser{}, "user_id = ?"},
+			{&models.CalendarFeed{}, "user_id = ?"},
+			{&models.TelegramLink{}, "user_id = ?"},
+			{&models.TelegramReminder{}, "user_id = ?"},
+		}
+		for _, c := range cleanup {
+			if err := tx.Delete(c.model, c.query, user.UserID).Error; err != nil {
+				return err
+			}
+		}
+		if err := closePending(tx, models.ApplicationRejected, nil, "rejected: account deleted",
+			"user_id = ?", user.UserID); err != nil {
+			return err
+		}
+
+		placeholder := fmt.Sprintf("deleted-user-%d", user.UserID)
+		return tx.Model(user).Updates(map[string]interface{}{
+			"username":          placeholder,
+			"email":             placeholder + "@invalid",
+			"hash_password":     "",
+			"full_name":         "",
+			"student_id":        nil,
+			"academic_group_id": nil,
+			"is_admin":          false,
+			"deleted_at":        now,
+		}).Error
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": user.UserID,
+		}).Error("Failed to delete account")
+		return nil, err
+	}
+	return successions, nil
+}
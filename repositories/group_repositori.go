@@ -47,8 +47,20 @@ func (r *GroupRepository) GetAllWithPagination(page, pageSize int32) ([]models.G
 //	func (r *GroupRepository) Create(group *models.Group) error {
 //		return r.db.Create(group).Error
 //	}
+
+// Create stores the group and adds its admin as a member with the admin role in one transaction
 func (r *GroupRepository) Create(group *models.Group) error {
-	if err := r.db.Create(group).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("AcademicGroup", "Admin").Create(group).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Group", "User").Create(&models.GroupUser{
+			GroupID: group.ID,
+			UserID:  group.AdminID,
+			Role:    "admin",
+		}).Error
+	})
+	if err != nil {
 		return err
 	}
 	// Preload Admin and AcademicGroup after creation
@@ -119,7 +131,8 @@ func (r *GroupRepository) IsAdminOrModerator(groupID, userID int32) (bool, error
 	return count > 0, nil
 }
 
-// Where user can apply (not a member)
+// Where user can apply (not a member or banned). Only public groups are listed; groups restricted to
+// their academic group are listed only to users verified in that academic group.
 func (r *GroupRepository) GetAvailable(userID int32, page, pageSize int) ([]models.Group, int64, error) {
 	var groups []models.Group
 	var total int64
@@ -137,6 +150,13 @@ func (r *GroupRepository) GetAvailable(userID int32, page, pageSize int) ([]mode
 			Where("user_id = ?", userID)).
 		Where("id NOT IN (?)", r.db.Model(&models.GroupModer{}).
 			Select("group_id").
+			Where("user_id = ?", userID)).
+		Where("id NOT IN (?)", r.db.Model(&models.GroupBan{}).
+			Select("group_id").
+			Where("user_id = ? AND lifted_at IS NULL", userID)).
+		Where("visibility = ?", models.GroupPublic).
+		Where("NOT same_academic_group_only OR academic_group_id IN (?)", r.db.Model(&models.User{}).
+			Select("academic_group_id").
 			Where("user_id = ?", userID))
 
 	if err := query.Count(&total).Error; err != nil {
@@ -82,3 +82,21 @@ func (r *GroupUserRepository) FindByUserID(userID int32) ([]*models.GroupUser, e
 	}
 	return groupUsers, nil
 }
+
+// FindGroupIDsInAcademicGroup returns the user's groups that belong to the academic group
+func (r *GroupUserRepository) FindGroupIDsInAcademicGroup(userID, academicGroupID int32) ([]int32, error) {
+	var groupIDs []int32
+	err := r.db.Model(&models.GroupUser{}).
+		Joins("JOIN groups ON groups.id = group_users.group_id").
+		Where("group_users.user_id = ? AND groups.academic_group_id = ?", userID, academicGroupID).
+		Pluck("group_users.group_id", &groupIDs).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":             err,
+			"user_id":           userID,
+			"academic_group_id": academicGroupID,
+		}).Error("Failed to find user groups in academic group")
+		return nil, err
+	}
+	return groupIDs, nil
+}
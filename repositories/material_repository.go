@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"errors"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a material was changed since it was loaded
+var ErrVersionConflict = errors.New("material was changed by someone else")
+
+type MaterialRepository struct {
+	db *gorm.DB
+}
+
+func NewMaterialRepository(db *gorm.DB) *MaterialRepository {
+	return &MaterialRepository{db}
+}
+
+// withoutData preloads attachments without their file contents
+func withoutData(db *gorm.DB) *gorm.DB {
+	return db.Omit("data").Order("id")
+}
+
+func (r *MaterialRepository) GetByID(id int32) (*models.Material, error) {
+	var material models.Material
+	err := r.db.Preload("Subject").Preload("Creator").Preload("Attachments", withoutData).
+		First(&material, "material_id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &material, nil
+}
+
+// FindBySubject returns the subject's shared materials and those of the given groups, in display order
+func (r *MaterialRepository) FindBySubject(subjectID int32, groupIDs []int32, includeInactive bool) ([]models.Material, error) {
+	var materials []models.Material
+	query := r.db.Preload("Subject").Preload("Creator").Preload("Attachments", withoutData).
+		Where("subject_id = ?", subjectID)
+	if len(groupIDs) > 0 {
+		query = query.Where("group_id IS NULL OR group_id IN ?", groupIDs)
+	} else {
+		query = query.Where("group_id IS NULL")
+	}
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Order("position").Order("material_id").Find(&materials).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":      err,
+			"subject_id": subjectID,
+		}).Error("Failed to fetch materials")
+		return nil, err
+	}
+	return materials, nil
+}
+
+// NextPosition returns the position after the last material of the subject
+func (r *MaterialRepository) NextPosition(subjectID int32) (int32, error) {
+	var position int32
+	err := r.db.Model(&models.Material{}).
+		Select("COALESCE(MAX(position), -1) + 1").
+		Where("subject_id = ?", subjectID).
+		Scan(&position).Error
+	return position, err
+}
+
+// Create stores the material together with its first revision
+func (r *MaterialRepository) Create(material *models.Material) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		material.Version = 1
+		if err := tx.Omit("Subject", "Creator", "Attachments").Create(material).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.MaterialRevision{
+			MaterialID: material.MaterialID,
+			Version:    1,
+			Title:      material.Title,
+			Content:    material.Content,
+			EditedBy:   material.CreatedBy,
+		}).Error
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":      err,
+			"subject_id": material.SubjectID,
+		}).Error("Failed to create material")
+		return err
+	}
+	return nil
+}
+
+// SetActive shows or hides a material without creating a revision
+func (r *MaterialRepository) SetActive(material *models.Material, active bool) error {
+	material.IsActive = active
+	return r.db.Model(material).Select("is_active", "updated_at").Updates(map[string]interface{}{
+		"is_active":  active,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// SaveRevision saves the material's new title and content as the next revision. The update
+// only applies while the stored version is still material.Version, so concurrent edits can't
+// overwrite each other; ErrVersionConflict is returned otherwise.
+func (r *MaterialRepository) SaveRevision(material *models.Material, revision *models.MaterialRevision) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Material{}).
+			Where("material_id = ? AND version = ?", material.MaterialID, material.Version).
+			Updates(map[string]interface{}{
+				"title":      material.Title,
+				"content":    material.Content,
+				"is_active":  material.IsActive,
+				"version":    material.Version + 1,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		material.Version++
+		material.UpdatedAt = now
+		revision.MaterialID = material.MaterialID
+		revision.Version = material.Version
+		return tx.Create(revision).Error
+	})
+}
+
+// FindRevisions lists the material's revisions, newest first, without their content
+func (r *MaterialRepository) FindRevisions(materialID int32) ([]models.MaterialRevision, error) {
+	var revisions []models.MaterialRevision
+	err := r.db.Omit("content").Preload("Editor").
+		Where("material_id = ?", materialID).
+		Order("version DESC").
+		Find(&revisions).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"material_id": materialID,
+		}).Error("Failed to fetch material revisions")
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *MaterialRepository) GetRevision(materialID, version int32) (*models.MaterialRevision, error) {
+	var revision models.MaterialRevision
+	if err := r.db.Preload("Editor").First(&revision, "material_id = ? AND version = ?", materialID, version).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Reorder sets the position of each material to its index in ids
+func (r *MaterialRepository) Reorder(subjectID int32, ids []int32) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.Material{}).
+				Where("material_id = ? AND subject_id = ?", id, subjectID).
+				Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MaterialRepository) CreateAttachment(attachment *models.MaterialAttachment) error {
+	return r.db.Create(attachment).Error
+}
+
+// GetAttachment loads an attachment of the material including its file contents
+func (r *MaterialRepository) GetAttachment(materialID, id int32) (*models.MaterialAttachment, error) {
+	var attachment models.MaterialAttachment
+	if err := r.db.First(&attachment, "id = ? AND material_id = ?", id, materialID).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *MaterialRepository) DeleteAttachment(materialID, id int32) error {
+	return r.db.Delete(&models.MaterialAttachment{}, "id = ? AND material_id = ?", id, materialID).Error
+}
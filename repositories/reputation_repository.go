@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"fmt"
+	"space/models"
+	"space/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// A verified task earns its author ReputationPerVerified points in the group, a rejected
+// one costs ReputationPerRejected. Only decisions made by a moderator or by vote count: a task
+// verified automatically for its author's reputation still counts as pending, so reputation
+// can't feed on itself.
+const (
+	ReputationPerVerified = 10
+	ReputationPerRejected = 15
+)
+
+// Reputation is a member's standing in one group, computed from the tasks they posted there
+type Reputation struct {
+	UserID   int32
+	Username string
+	Verified int
+	Rejected int
+	Pending  int
+	Score    int
+}
+
+type ReputationRepository struct {
+	db *gorm.DB
+}
+
+func NewReputationRepository(db *gorm.DB) *ReputationRepository {
+	return &ReputationRepository{db}
+}
+
+// decided matches tasks whose current status wasn't set by reputation. Tasks verified before
+// decisions were recorded have none and were always decided by a moderator.
+var decided = fmt.Sprintf("COALESCE(d.method, '') <> '%s'", models.VerifyByReputation)
+
+var reputationScore = fmt.Sprintf(
+	"%d * COUNT(*) FILTER (WHERE t.verification_status = '%s' AND %s) - %d * COUNT(*) FILTER (WHERE t.verification_status = '%s' AND %s)",
+	ReputationPerVerified, models.TaskVerified, decided, ReputationPerRejected, models.TaskRejected, decided)
+
+// members selects every current member of the group with their task counts and score
+func (r *ReputationRepository) members(groupID int32) *gorm.DB {
+	return r.db.Table("group_users gu").
+		Select(fmt.Sprintf(`gu.user_id, u.username,
+			COUNT(*) FILTER (WHERE t.verification_status = '%[1]s' AND %[4]s) AS verified,
+			COUNT(*) FILTER (WHERE t.verification_status = '%[2]s' AND %[4]s) AS rejected,
+			COUNT(*) FILTER (WHERE t.verification_status = '%[3]s' OR (t.id IS NOT NULL AND NOT %[4]s)) AS pending,
+			%[5]s AS score`, models.TaskVerified, models.TaskRejected, models.TaskPending, decided, reputationScore)).
+		Joins("JOIN users u ON u.user_id = gu.user_id").
+		Joins("LEFT JOIN tasks t ON t.group_id = gu.group_id AND t.user_id = gu.user_id").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT method FROM task_verification_decisions
+			WHERE task_id = t.id ORDER BY id DESC LIMIT 1
+		) d ON true`).
+		Where("gu.group_id = ?", groupID).
+		Group("gu.user_id, u.username")
+}
+
+// Scores maps each member of the group to their reputation score
+func (r *ReputationRepository) Scores(groupID int32) (map[int32]int, error) {
+	var rows []Reputation
+	if err := r.members(groupID).Scan(&rows).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to compute reputation")
+		return nil, err
+	}
+	scores := make(map[int32]int, len(rows))
+	for _, row := range rows {
+		scores[row.UserID] = row.Score
+	}
+	return scores, nil
+}
+
+// Get returns one member's reputation; non-members get a zero reputation
+func (r *ReputationRepository) Get(groupID, userID int32) (Reputation, error) {
+	var rows []Reputation
+	if err := r.members(groupID).Where("gu.user_id = ?", userID).Scan(&rows).Error; err != nil {
+		return Reputation{}, err
+	}
+	if len(rows) == 0 {
+		return Reputation{UserID: userID}, nil
+	}
+	return rows[0], nil
+}
+
+// Leaderboard returns the group's members ordered by reputation, highest first
+func (r *ReputationRepository) Leaderboard(groupID int32, limit int) ([]Reputation, error) {
+	var rows []Reputation
+	err := r.members(groupID).
+		Order("score DESC").Order("verified DESC").Order("u.username").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch reputation leaderboard")
+		return nil, err
+	}
+	return rows, nil
+}
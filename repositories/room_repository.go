@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RoomRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomRepository(db *gorm.DB) *RoomRepository {
+	return &RoomRepository{db}
+}
+
+// RoomFilter narrows room listings; zero values are ignored
+type RoomFilter struct {
+	Building    string
+	MinCapacity int32
+	Equipment   []string // rooms must have every tag
+}
+
+func (f RoomFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Building != "" {
+		db = db.Where("rooms.building = ?", f.Building)
+	}
+	if f.MinCapacity > 0 {
+		db = db.Where("rooms.capacity >= ?", f.MinCapacity)
+	}
+	for _, tag := range f.Equipment {
+		db = db.Where("EXISTS (SELECT 1 FROM room_equipments e WHERE e.room_id = rooms.id AND e.tag = ?)", tag)
+	}
+	return db
+}
+
+func (r *RoomRepository) GetByID(id int32) (*models.Room, error) {
+	var room models.Room
+	if err := r.db.Preload("Equipment").First(&room, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepository) GetByCode(code string) (*models.Room, error) {
+	var room models.Room
+	if err := r.db.First(&room, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepository) Find(filter RoomFilter) ([]models.Room, error) {
+	var rooms []models.Room
+	err := filter.apply(r.db.Model(&models.Room{})).
+		Preload("Equipment").
+		Order("rooms.building").Order("rooms.number").
+		Find(&rooms).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to fetch rooms")
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// FindFree returns rooms matching the filter that have no lesson on date in any of the slots.
+// A lesson occupies a room when it is linked to it or its free-text classroom matches the room code.
+func (r *RoomRepository) FindFree(date time.Time, timeSlotIDs []int32, filter RoomFilter) ([]models.Room, error) {
+	var rooms []models.Room
+	err := filter.apply(r.db.Model(&models.Room{})).
+		Preload("Equipment").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM schedules s
+			WHERE s.date = ? AND s.time_slot_id IN ?
+			AND (s.room_id = rooms.id OR (s.room_id IS NULL AND `+utils.ClassroomKeySQL+` = rooms.code))
+		)`, date.Format("2006-01-02"), timeSlotIDs).
+		Order("rooms.capacity").Order("rooms.building").Order("rooms.number").
+		Find(&rooms).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"date":  date,
+		}).Error("Failed to search free rooms")
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// Create stores the room with its equipment and links existing lessons written with its name
+func (r *RoomRepository) Create(room *models.Room) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.Schedule{}, &models.ScheduleRule{}} {
+			if err := tx.Model(model).
+				Where("room_id IS NULL AND "+utils.ClassroomKeySQL+" = ?", room.Code).
+				Update("room_id", room.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update saves the room, replaces its equipment and refreshes the classroom text of linked lessons
+func (r *RoomRepository) Update(room *models.Room) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Equipment").Save(room).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("room_id = ?", room.ID).Delete(&models.RoomEquipment{}).Error; err != nil {
+			return err
+		}
+		if len(room.Equipment) > 0 {
+			for i := range room.Equipment {
+				room.Equipment[i].RoomID = room.ID
+			}
+			if err := tx.Create(&room.Equipment).Error; err != nil {
+				return err
+			}
+		}
+		label := utils.RoomLabel(room.Building, room.Number)
+		for _, model := range []interface{}{&models.Schedule{}, &models.ScheduleRule{}} {
+			if err := tx.Model(model).Where("room_id = ?", room.ID).Update("classroom", label).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the room; linked lessons keep their classroom text
+func (r *RoomRepository) Delete(id int32) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Schedule{}, &models.ScheduleRule{}} {
+			if err := tx.Model(model).Where("room_id = ?", id).Update("room_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("room_id = ?", id).Delete(&models.RoomEquipment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Room{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RosterImport is the planned outcome of a roster import: accounts to create, users to add to
+// the group, and matched accounts whose verified academic group is recorded (site
+// administrators' imports only)
+type RosterImport struct {
+	Create          []*models.User
+	Join            []*models.User
+	Enroll          []*models.User
+	AcademicGroupID int32
+}
+
+type RosterRepository struct {
+	db *gorm.DB
+}
+
+func NewRosterRepository(db *gorm.DB) *RosterRepository {
+	return &RosterRepository{db}
+}
+
+// FindUsersByEmails returns the active accounts with any of the emails, compared
+// case-insensitively
+func (r *RosterRepository) FindUsersByEmails(emails []string) ([]models.User, error) {
+	var users []models.User
+	if len(emails) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("LOWER(email) IN ? AND deleted_at IS NULL", emails).Find(&users).Error
+	return users, err
+}
+
+func (r *RosterRepository) FindUsersByStudentIDs(studentIDs []string) ([]models.User, error) {
+	var users []models.User
+	if len(studentIDs) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("student_id IN ? AND deleted_at IS NULL", studentIDs).Find(&users).Error
+	return users, err
+}
+
+// FindTakenUsernames returns which of the usernames already exist
+func (r *RosterRepository) FindTakenUsernames(usernames []string) (map[string]bool, error) {
+	taken := make(map[string]bool)
+	if len(usernames) == 0 {
+		return taken, nil
+	}
+	var existing []string
+	if err := r.db.Model(&models.User{}).Where("username IN ?", usernames).Pluck("username", &existing).Error; err != nil {
+		return nil, err
+	}
+	for _, username := range existing {
+		taken[username] = true
+	}
+	return taken, nil
+}
+
+func (r *RosterRepository) FindMemberIDs(groupID int32) (map[int32]bool, error) {
+	var ids []int32
+	if err := r.db.Model(&models.GroupUser{}).Where("group_id = ?", groupID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	members := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		members[id] = true
+	}
+	return members, nil
+}
+
+// FindApplicantIDs returns the users with a pending application to the group
+func (r *RosterRepository) FindApplicantIDs(groupID int32) (map[int32]bool, error) {
+	var ids []int32
+	if err := r.db.Model(&models.GroupApplication{}).Where("group_id = ? AND status = ?", groupID, models.ApplicationPending).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	applicants := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		applicants[id] = true
+	}
+	return applicants, nil
+}
+
+func (r *RosterRepository) FindBannedIDs(groupID int32) (map[int32]bool, error) {
+	var ids []int32
+	if err := r.db.Model(&models.GroupBan{}).Where("group_id = ? AND lifted_at IS NULL", groupID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	banned := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		banned[id] = true
+	}
+	return banned, nil
+}
+
+// Apply creates the accounts, records the enrolled users' academic group and adds the users to the group in one transaction.
+// Pending applications of the added users are approved on behalf of actorID.
+func (r *RosterRepository) Apply(groupID int32, plan *RosterImport, actorID int32) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, user := range plan.Create {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+		}
+		for _, user := range plan.Enroll {
+			if err := tx.Model(user).Update("academic_group_id", plan.AcademicGroupID).Error; err != nil {
+				return err
+			}
+		}
+		for _, user := range plan.Join {
+			if err := tx.Omit("Group", "User").Clauses(clause.OnConflict{DoNothing: true}).Create(&models.GroupUser{
+				GroupID: groupID,
+				UserID:  user.UserID,
+				Role:    "member",
+			}).Error; err != nil {
+				return err
+			}
+			if err := closePending(tx, models.ApplicationApproved, &actorID, "approved by roster import",
+				"group_id = ? AND user_id = ?", groupID, user.UserID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"created":  len(plan.Create),
+			"joined":   len(plan.Join),
+		}).Error("Failed to apply roster import")
+	}
+	return err
+}
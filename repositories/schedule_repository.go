@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) *ScheduleRepository {
+	return &ScheduleRepository{db}
+}
+
+func (r *ScheduleRepository) FindTimeSlots() ([]models.TimeSlot, error) {
+	var slots []models.TimeSlot
+	if err := r.db.Order("slot_number").Find(&slots).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to fetch time slots")
+		return nil, err
+	}
+	return slots, nil
+}
+
+func (r *ScheduleRepository) GetTimeSlotByID(id int32) (*models.TimeSlot, error) {
+	var slot models.TimeSlot
+	if err := r.db.First(&slot, "slot_id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (r *ScheduleRepository) CreateTimeSlot(slot *models.TimeSlot) error {
+	return r.db.Create(slot).Error
+}
+
+func (r *ScheduleRepository) UpdateTimeSlot(slot *models.TimeSlot) error {
+	return r.db.Save(slot).Error
+}
+
+func (r *ScheduleRepository) DeleteTimeSlot(id int32) error {
+	return r.db.Delete(&models.TimeSlot{}, "slot_id = ?", id).Error
+}
+
+func (r *ScheduleRepository) IsTimeSlotUsed(id int32) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Schedule{}).Where("time_slot_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ScheduleRepository) GetByID(id int32) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if err := r.db.Preload("Group").Preload("Subject").Preload("TimeSlot").
+		First(&schedule, "schedule_id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *ScheduleRepository) Create(schedule *models.Schedule) error {
+	if err := r.db.Create(schedule).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": schedule.GroupID,
+			"date":     schedule.Date,
+		}).Error("Failed to create schedule entry")
+		return err
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) Update(schedule *models.Schedule) error {
+	return r.db.Omit("Group", "Subject", "TimeSlot").Save(schedule).Error
+}
+
+func (r *ScheduleRepository) Delete(id int32) error {
+	return r.db.Delete(&models.Schedule{}, "schedule_id = ?", id).Error
+}
+
+// FindByGroupIDs returns schedule entries of the groups between from and to (inclusive dates)
+func (r *ScheduleRepository) FindByGroupIDs(groupIDs []int32, from, to time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.Model(&models.Schedule{}).
+		Joins("TimeSlot").
+		Preload("Group").Preload("Subject").
+		Where("schedules.group_id IN ?", groupIDs).
+		Where("schedules.date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("schedules.date").Order("\"TimeSlot\".slot_number").
+		Find(&schedules).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"group_ids": groupIDs,
+			"from":      from,
+			"to":        to,
+		}).Error("Failed to fetch schedule")
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ImportedLesson is a lesson to create whose time slot or subject may not exist yet
+type ImportedLesson struct {
+	Schedule models.Schedule
+	TimeSlot *models.TimeSlot
+	Subject  *models.Subject
+}
+
+// TimetableImport is a validated import plan
+type TimetableImport struct {
+	TimeSlots []*models.TimeSlot
+	Subjects  []*models.Subject
+	Creates   []ImportedLesson
+	Updates   []models.Schedule
+}
+
+// ApplyImport creates the new time slots and subjects first so the lessons can reference them,
+// then writes the lessons; everything is rolled back if any step fails
+func (r *ScheduleRepository) ApplyImport(plan *TimetableImport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, slot := range plan.TimeSlots {
+			if err := tx.Create(slot).Error; err != nil {
+				return err
+			}
+		}
+		for _, subject := range plan.Subjects {
+			if err := tx.Omit("AcademicGroup").Create(subject).Error; err != nil {
+				return err
+			}
+		}
+		if len(plan.Creates) > 0 {
+			schedules := make([]models.Schedule, len(plan.Creates))
+			for i, lesson := range plan.Creates {
+				schedules[i] = lesson.Schedule
+				schedules[i].TimeSlotID = lesson.TimeSlot.SlotID
+				schedules[i].SubjectID = lesson.Subject.SubjectID
+			}
+			if err := tx.Omit("Group", "Subject", "TimeSlot").CreateInBatches(schedules, 100).Error; err != nil {
+				return err
+			}
+		}
+		for i := range plan.Updates {
+			if err := tx.Omit("Group", "Subject", "TimeSlot").Save(&plan.Updates[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindAtDates returns lessons of all groups on the given dates
+func (r *ScheduleRepository) FindAtDates(dates []time.Time) ([]models.Schedule, error) {
+	values := make([]string, len(dates))
+	for i, d := range dates {
+		values[i] = d.Format("2006-01-02")
+	}
+	var schedules []models.Schedule
+	err := r.db.Model(&models.Schedule{}).
+		Joins("TimeSlot").
+		Preload("Group").Preload("Subject").
+		Where("schedules.date IN ?", values).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// FindByDateRange returns lessons of all groups between from and to (inclusive dates)
+func (r *ScheduleRepository) FindByDateRange(from, to time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.Model(&models.Schedule{}).
+		Joins("TimeSlot").
+		Preload("Group").Preload("Subject").
+		Where("schedules.date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("schedules.date").Order("\"TimeSlot\".slot_number").Order("schedules.schedule_id").
+		Find(&schedules).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"from":  from,
+			"to":    to,
+		}).Error("Failed to fetch schedule")
+		return nil, err
+	}
+	return schedules, nil
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"errors"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrExceptionExists = errors.New("lesson is already cancelled on this date")
+
+type ScheduleRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRuleRepository(db *gorm.DB) *ScheduleRuleRepository {
+	return &ScheduleRuleRepository{db}
+}
+
+func (r *ScheduleRuleRepository) GetByID(id int32) (*models.ScheduleRule, error) {
+	var rule models.ScheduleRule
+	if err := r.db.Preload("Group").Preload("Subject").Preload("TimeSlot").Preload("Exceptions").
+		First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *ScheduleRuleRepository) FindByGroupID(groupID int32) ([]models.ScheduleRule, error) {
+	var rules []models.ScheduleRule
+	if err := r.db.Preload("Group").Preload("Subject").Preload("TimeSlot").Preload("Exceptions").
+		Where("group_id = ?", groupID).
+		Order("weekday").Order("time_slot_id").
+		Find(&rules).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch schedule rules")
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateWithLessons stores the rule and its expanded lessons in one transaction
+func (r *ScheduleRuleRepository) CreateWithLessons(rule *models.ScheduleRule, lessons []models.Schedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Group", "Subject", "TimeSlot", "Exceptions").Create(rule).Error; err != nil {
+			return err
+		}
+		return createLessons(tx, rule.ID, lessons)
+	})
+}
+
+// ReplaceLessons updates the rule and regenerates its lessons in one transaction
+func (r *ScheduleRuleRepository) ReplaceLessons(rule *models.ScheduleRule, lessons []models.Schedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Group", "Subject", "TimeSlot", "Exceptions").Save(rule).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.Schedule{}).Error; err != nil {
+			return err
+		}
+		return createLessons(tx, rule.ID, lessons)
+	})
+}
+
+func createLessons(tx *gorm.DB, ruleID int32, lessons []models.Schedule) error {
+	if len(lessons) == 0 {
+		return nil
+	}
+	for i := range lessons {
+		lessons[i].RuleID = &ruleID
+	}
+	return tx.Omit("Group", "Subject", "TimeSlot").CreateInBatches(lessons, 100).Error
+}
+
+// Delete removes the rule together with the lessons generated from it
+func (r *ScheduleRuleRepository) Delete(id int32) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&models.Schedule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_id = ?", id).Delete(&models.ScheduleRuleException{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ScheduleRule{}, "id = ?", id).Error
+	})
+}
+
+// AddException records a cancellation and removes the generated lesson for that date
+func (r *ScheduleRuleRepository) AddException(exception *models.ScheduleRuleException) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Rule").Create(exception)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrExceptionExists
+		}
+		return tx.Where("rule_id = ? AND date = ?", exception.RuleID, exception.Date.Format("2006-01-02")).
+			Delete(&models.Schedule{}).Error
+	})
+}
+
+// RemoveException lifts a cancellation and restores the lesson
+func (r *ScheduleRuleRepository) RemoveException(ruleID int32, date time.Time, lesson *models.Schedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("rule_id = ? AND date = ?", ruleID, date.Format("2006-01-02")).
+			Delete(&models.ScheduleRuleException{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if lesson == nil {
+			return nil
+		}
+		return createLessons(tx, ruleID, []models.Schedule{*lesson})
+	})
+}
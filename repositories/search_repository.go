@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// searchConfig is the text search configuration; "russian" stems Cyrillic words with the
+// Russian and ASCII words with the English snowball stemmer
+const searchConfig = "russian"
+
+// Snippets mark matches with these private-use characters, so the text can be HTML-escaped
+// before they are turned into tags
+const (
+	SnippetMatchStart = "\uE000"
+	SnippetMatchEnd   = "\uE001"
+)
+
+const searchHeadlineOptions = "StartSel=" + SnippetMatchStart + ", StopSel=" + SnippetMatchEnd +
+	`, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`
+
+// SearchDocument describes one searchable table. Document is the indexed text expression;
+// it must stay identical to the GIN index in the search migration so PostgreSQL uses it.
+type SearchDocument struct {
+	Type     string
+	Table    string
+	Document string
+}
+
+var SearchDocuments = []SearchDocument{
+	{"task", "tasks", "coalesce(title, '') || ' ' || coalesce(description, '')"},
+	{"material", "materials", "coalesce(title, '') || ' ' || coalesce(content, '')"},
+	{"subject", "subjects", "coalesce(name, '')"},
+	{"group", "groups", "coalesce(name, '')"},
+}
+
+func searchVector(doc SearchDocument, alias string) string {
+	expr := doc.Document
+	for _, column := range []string{"title", "description", "content", "name"} {
+		expr = strings.ReplaceAll(expr, "("+column+",", "("+alias+"."+column+",")
+	}
+	return "to_tsvector('" + searchConfig + "', " + expr + ")"
+}
+
+// SearchHit is one search result row
+type SearchHit struct {
+	Type      string
+	ID        int32
+	Title     string
+	Snippet   string
+	GroupID   *int32
+	SubjectID *int32
+	Rank      float64
+	CreatedAt time.Time
+}
+
+// SearchFacet counts the results of one type
+type SearchFacet struct {
+	Type  string
+	Count int64
+}
+
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{db}
+}
+
+// searchUnion builds one SELECT per document type, each limited to rows the user can see:
+//   - tasks of the user's groups
+//   - active materials of subjects in the academic groups of the user's groups, shared or
+//     posted to one of the user's groups
+//   - subjects of those academic groups
+//   - groups the user belongs to
+//
+// Body is the text snippets are cut from; they are only computed for the returned page.
+func searchUnion() string {
+	docs := make(map[string]SearchDocument, len(SearchDocuments))
+	for _, doc := range SearchDocuments {
+		docs[doc.Type] = doc
+	}
+	match := func(doc, alias string) string {
+		return searchVector(docs[doc], alias) + " @@ q.query"
+	}
+	rank := func(doc, alias string) string {
+		return "ts_rank(" + searchVector(docs[doc], alias) + ", q.query)"
+	}
+
+	return `
+		SELECT 'task' AS type, t.id AS id, t.title AS title, coalesce(t.description, '') AS body,
+			t.group_id AS group_id, t.subject_id AS subject_id,
+			` + rank("task", "t") + ` AS rank, t.created_at AS created_at
+		FROM tasks t, q
+		WHERE ` + match("task", "t") + `
+			AND t.group_id IN (SELECT group_id FROM my_groups)
+		UNION ALL
+		SELECT 'material', m.material_id, m.title, coalesce(m.content, ''),
+			m.group_id, m.subject_id, ` + rank("material", "m") + `, m.created_at
+		FROM materials m JOIN subjects s ON s.subject_id = m.subject_id, q
+		WHERE ` + match("material", "m") + `
+			AND m.is_active
+			AND s.academic_group_id IN (SELECT academic_group_id FROM my_groups)
+			AND (m.group_id IS NULL OR m.group_id IN (SELECT group_id FROM my_groups))
+		UNION ALL
+		SELECT 'subject', s.subject_id, s.name, s.name,
+			NULL, s.subject_id, ` + rank("subject", "s") + `, s.created_at
+		FROM subjects s, q
+		WHERE ` + match("subject", "s") + `
+			AND s.academic_group_id IN (SELECT academic_group_id FROM my_groups)
+		UNION ALL
+		SELECT 'group', g.id, g.name, g.name,
+			g.id, NULL, ` + rank("group", "g") + `, g.created_at
+		FROM groups g, q
+		WHERE ` + match("group", "g") + `
+			AND g.id IN (SELECT group_id FROM my_groups)`
+}
+
+const searchPrelude = `
+	WITH q AS (SELECT websearch_to_tsquery('` + searchConfig + `', @query) AS query),
+	my_groups AS (
+		SELECT gu.group_id, g.academic_group_id
+		FROM group_users gu JOIN groups g ON g.id = gu.group_id
+		WHERE gu.user_id = @user_id
+	),
+	hits AS (`
+
+// Search runs a full-text query over everything the user can see. Results of the given types
+// (all when empty) are ordered by rank; facets count every type regardless of the filter.
+func (r *SearchRepository) Search(userID int32, query string, types []string, limit, offset int) ([]SearchHit, []SearchFacet, error) {
+	args := map[string]interface{}{
+		"query":   query,
+		"user_id": userID,
+		"types":   types,
+		"limit":   limit,
+		"offset":  offset,
+	}
+
+	var facets []SearchFacet
+	err := r.db.Raw(searchPrelude+searchUnion()+`)
+		SELECT type, COUNT(*) AS count FROM hits GROUP BY type ORDER BY type`, args).
+		Scan(&facets).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to count search results")
+		return nil, nil, err
+	}
+
+	typeFilter := ""
+	if len(types) > 0 {
+		typeFilter = "WHERE type IN @types"
+	}
+	var hits []SearchHit
+	err = r.db.Raw(searchPrelude+searchUnion()+`),
+		page AS (
+			SELECT * FROM hits `+typeFilter+`
+			ORDER BY rank DESC, created_at DESC, type, id
+			LIMIT @limit OFFSET @offset
+		)
+		SELECT page.type, page.id, page.title,
+			ts_headline('`+searchConfig+`', page.body, q.query, '`+searchHeadlineOptions+`') AS snippet,
+			page.group_id, page.subject_id, page.rank, page.created_at
+		FROM page, q
+		ORDER BY page.rank DESC, page.created_at DESC, page.type, page.id`, args).
+		Scan(&hits).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to search")
+		return nil, nil, err
+	}
+	return hits, facets, nil
+}
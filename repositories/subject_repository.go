@@ -18,7 +18,7 @@ func NewSubjectRepository(db *gorm.DB) *SubjectRepository {
 
 func (r *SubjectRepository) GetByID(id int32) (*models.Subject, error) {
 	var subject models.Subject
-	if err := r.db.Preload("Group").First(&subject, "subject_id = ?", id).Error; err != nil {
+	if err := r.db.Preload("AcademicGroup").First(&subject, "subject_id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &subject, nil
@@ -138,3 +138,15 @@ func (r *SubjectRepository) FindByUserGroups(userID int32, page, pageSize int) (
 
 	return subjects, groups, total, nil
 }
+
+func (r *SubjectRepository) FindAllByAcademicGroupID(academicGroupID int32) ([]models.Subject, error) {
+	var subjects []models.Subject
+	if err := r.db.Where("academic_group_id = ?", academicGroupID).Find(&subjects).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":             err,
+			"academic_group_id": academicGroupID,
+		}).Error("Failed to fetch subjects")
+		return nil, err
+	}
+	return subjects, nil
+}
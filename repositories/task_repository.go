@@ -93,18 +93,6 @@ func (r *TaskRepository) FindByGroupID(groupID int32, page, pageSize int) ([]*mo
 	return tasks, total, nil
 }
 
-func (r *TaskRepository) UpdateVerificationStatus(taskID int32, isVerified bool) error {
-	if err := r.db.Model(&models.Task{}).Where("id = ?", taskID).Update("is_verified", isVerified).Error; err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":       err,
-			"task_id":     taskID,
-			"is_verified": isVerified,
-		}).Error("Failed to update task verification status")
-		return err
-	}
-	return nil
-}
-
 func (r *TaskRepository) OldFindByGroupIDs(groupIDs []int32) ([]*models.Task, error) {
 	var tasks []*models.Task
 	if err := r.db.Preload("User").Preload("Subject").Where("group_id IN ?", groupIDs).Find(&tasks).Error; err != nil {
@@ -181,3 +169,18 @@ func (r *TaskRepository) FindBySubjectID(subjectID, groupID int32, page, pageSiz
 
 	return tasks, total, nil
 }
+
+func (r *TaskRepository) FindWithDeadlineByGroupIDs(groupIDs []int32) ([]*models.Task, error) {
+	var tasks []*models.Task
+	if err := r.db.Preload("User").Preload("Subject").Preload("Group").
+		Where("group_id IN ? AND deadline IS NOT NULL", groupIDs).
+		Order("deadline").
+		Find(&tasks).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"group_ids": groupIDs,
+		}).Error("Failed to find tasks with deadlines")
+		return nil, err
+	}
+	return tasks, nil
+}
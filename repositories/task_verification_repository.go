@@ -0,0 +1,275 @@
+package repositories
+
+import (
+	"errors"
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNoVote = errors.New("vote not found")
+
+// Tally counts a task's votes
+type Tally struct {
+	Legit int
+	Fake  int
+}
+
+func (t Tally) Total() int {
+	return t.Legit + t.Fake
+}
+
+// Outcome applies the policy to the tally: pending until the quorum is reached, then verified
+// when the share of legit votes meets the threshold and rejected otherwise
+func (t Tally) Outcome(policy *models.GroupVerificationPolicy) string {
+	if t.Total() < policy.Quorum {
+		return models.TaskPending
+	}
+	if t.Legit*100 >= policy.Threshold*t.Total() {
+		return models.TaskVerified
+	}
+	return models.TaskRejected
+}
+
+type TaskVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskVerificationRepository(db *gorm.DB) *TaskVerificationRepository {
+	return &TaskVerificationRepository{db}
+}
+
+// GetPolicy returns the group's policy, or the default one if the group never set it
+func (r *TaskVerificationRepository) GetPolicy(groupID int32) (*models.GroupVerificationPolicy, error) {
+	var policy models.GroupVerificationPolicy
+	err := r.db.First(&policy, "group_id = ?", groupID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = models.DefaultVerificationPolicy(groupID)
+		return &policy, nil
+	}
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to fetch verification policy")
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *TaskVerificationRepository) SavePolicy(policy *models.GroupVerificationPolicy) error {
+	policy.UpdatedAt = time.Now()
+	return r.db.Omit("Group").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "voters", "quorum", "threshold", "auto_verify_reputation", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func tally(tx *gorm.DB, taskID int32) (Tally, error) {
+	var rows []struct {
+		Vote  string
+		Count int
+	}
+	err := tx.Model(&models.TaskVote{}).
+		Select("vote, COUNT(*) AS count").
+		Where("task_id = ?", taskID).
+		Group("vote").
+		Scan(&rows).Error
+	if err != nil {
+		return Tally{}, err
+	}
+	var t Tally
+	for _, row := range rows {
+		switch row.Vote {
+		case "legit":
+			t.Legit = row.Count
+		case "fake":
+			t.Fake = row.Count
+		}
+	}
+	return t, nil
+}
+
+// decide sets the task's verification status and records the decision, in the caller's
+// transaction. It reports whether the status changed; an unchanged status records nothing
+// unless confirm is set.
+func decide(tx *gorm.DB, task *models.Task, status, method string, decidedBy int32, t Tally, confirm bool) (bool, error) {
+	previous := task.VerificationStatus
+	if previous == status && !confirm {
+		return false, nil
+	}
+	if err := tx.Model(&models.Task{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+		"verification_status": status,
+		"is_verified":         status == models.TaskVerified,
+	}).Error; err != nil {
+		return false, err
+	}
+	task.VerificationStatus = status
+	task.IsVerified = status == models.TaskVerified
+	err := tx.Omit("Task", "Decider").Create(&models.TaskVerificationDecision{
+		TaskID:         task.ID,
+		PreviousStatus: previous,
+		Status:         status,
+		Method:         method,
+		DecidedBy:      decidedBy,
+		Legit:          t.Legit,
+		Fake:           t.Fake,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+	return previous != status, nil
+}
+
+// lockTask reloads the task under a row lock so concurrent votes settle one at a time
+func lockTask(tx *gorm.DB, task *models.Task) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(task, task.ID).Error
+}
+
+// lastMethod returns how the task's current status was decided, or "" if it never was
+func lastMethod(tx *gorm.DB, taskID int32) (string, error) {
+	var last models.TaskVerificationDecision
+	err := tx.Where("task_id = ?", taskID).Order("id DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return last.Method, nil
+}
+
+// settle recounts the votes and applies the policy. A moderator's decision isn't changed by
+// votes, and a task verified by reputation stays verified until the quorum decides otherwise.
+func settle(tx *gorm.DB, task *models.Task, policy *models.GroupVerificationPolicy, voterID int32) (Tally, bool, error) {
+	t, err := tally(tx, task.ID)
+	if err != nil {
+		return Tally{}, false, err
+	}
+	method, err := lastMethod(tx, task.ID)
+	if err != nil || method == models.VerifyByModerator {
+		return t, false, err
+	}
+	outcome := t.Outcome(policy)
+	if outcome == models.TaskPending && method == models.VerifyByReputation {
+		return t, false, nil
+	}
+	changed, err := decide(tx, task, outcome, models.VerifyByVote, voterID, t, false)
+	return t, changed, err
+}
+
+// Vote stores or replaces the user's vote and re-evaluates the task. It reports the new tally
+// and whether the task's status changed.
+func (r *TaskVerificationRepository) Vote(task *models.Task, vote *models.TaskVote, policy *models.GroupVerificationPolicy) (Tally, bool, error) {
+	var t Tally
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task); err != nil {
+			return err
+		}
+		if err := tx.Omit("Task", "User").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"vote", "reason", "updated_at"}),
+		}).Create(vote).Error; err != nil {
+			return err
+		}
+		var err error
+		t, changed, err = settle(tx, task, policy, vote.UserID)
+		return err
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": task.ID,
+			"user_id": vote.UserID,
+		}).Error("Failed to record task vote")
+		return Tally{}, false, err
+	}
+	return t, changed, nil
+}
+
+// Retract deletes the user's vote and re-evaluates the task, which may fall back to pending
+func (r *TaskVerificationRepository) Retract(task *models.Task, userID int32, policy *models.GroupVerificationPolicy) (Tally, bool, error) {
+	var t Tally
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task); err != nil {
+			return err
+		}
+		result := tx.Delete(&models.TaskVote{}, "task_id = ? AND user_id = ?", task.ID, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNoVote
+		}
+		var err error
+		t, changed, err = settle(tx, task, policy, userID)
+		return err
+	})
+	if err != nil {
+		return Tally{}, false, err
+	}
+	return t, changed, nil
+}
+
+// Override records a moderator's direct decision, which votes won't change afterwards. It is
+// recorded even when it confirms the current status so that status is locked too.
+func (r *TaskVerificationRepository) Override(task *models.Task, status string, moderatorID int32) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task); err != nil {
+			return err
+		}
+		t, err := tally(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		changed, err = decide(tx, task, status, models.VerifyByModerator, moderatorID, t, true)
+		return err
+	})
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": task.ID,
+			"status":  status,
+		}).Error("Failed to update task verification status")
+		return false, err
+	}
+	return changed, nil
+}
+
+// AutoVerify marks a new task verified on the strength of its author's reputation
+func (r *TaskVerificationRepository) AutoVerify(task *models.Task) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task); err != nil {
+			return err
+		}
+		var err error
+		changed, err = decide(tx, task, models.TaskVerified, models.VerifyByReputation, task.UserID, Tally{}, false)
+		return err
+	})
+	return changed, err
+}
+
+func (r *TaskVerificationRepository) FindVotes(taskID int32) ([]models.TaskVote, error) {
+	var votes []models.TaskVote
+	if err := r.db.Preload("User").Where("task_id = ?", taskID).Order("updated_at").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// FindDecisions lists the task's status changes, oldest first
+func (r *TaskVerificationRepository) FindDecisions(taskID int32) ([]models.TaskVerificationDecision, error) {
+	var decisions []models.TaskVerificationDecision
+	if err := r.db.Preload("Decider").Where("task_id = ?", taskID).Order("id").Find(&decisions).Error; err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type TeacherRepository struct {
+	db *gorm.DB
+}
+
+func NewTeacherRepository(db *gorm.DB) *TeacherRepository {
+	return &TeacherRepository{db}
+}
+
+func (r *TeacherRepository) GetByID(id int32) (*models.Teacher, error) {
+	var teacher models.Teacher
+	if err := r.db.First(&teacher, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &teacher, nil
+}
+
+// Find lists teachers, optionally filtered by a name/initials search and a department
+func (r *TeacherRepository) Find(query, department string) ([]models.Teacher, error) {
+	var teachers []models.Teacher
+	db := r.db.Model(&models.Teacher{})
+	if query != "" {
+		like := "%" + query + "%"
+		db = db.Where("full_name ILIKE ? OR initials ILIKE ?", like, like)
+	}
+	if department != "" {
+		db = db.Where("department = ?", department)
+	}
+	if err := db.Order("initials").Find(&teachers).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"query": query,
+		}).Error("Failed to fetch teachers")
+		return nil, err
+	}
+	return teachers, nil
+}
+
+// FindByInitials returns teachers whose initials match ignoring case, spaces and dots
+func (r *TeacherRepository) FindByInitials(initials string) ([]models.Teacher, error) {
+	var teachers []models.Teacher
+	err := r.db.Where(utils.InitialsKeySQL+" = ?", utils.InitialsKey(initials)).Find(&teachers).Error
+	return teachers, err
+}
+
+func (r *TeacherRepository) Create(teacher *models.Teacher) error {
+	if err := r.db.Create(teacher).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"initials": teacher.Initials,
+		}).Error("Failed to create teacher")
+		return err
+	}
+	return nil
+}
+
+// Update saves the teacher and refreshes the initials text of linked lessons and rules
+func (r *TeacherRepository) Update(teacher *models.Teacher) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(teacher).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.Schedule{}, &models.ScheduleRule{}} {
+			if err := tx.Model(model).Where("teacher_id = ?", teacher.ID).
+				Update("teacher_initials", teacher.Initials).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the teacher; linked lessons, rules and subjects keep their initials text
+func (r *TeacherRepository) Delete(id int32) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Schedule{}, &models.ScheduleRule{}, &models.Subject{}} {
+			if err := tx.Model(model).Where("teacher_id = ?", id).Update("teacher_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Teacher{}, "id = ?", id).Error
+	})
+}
+
+func (r *TeacherRepository) FindSubjects(teacherID int32) ([]models.Subject, error) {
+	var subjects []models.Subject
+	err := r.db.Where("teacher_id = ?", teacherID).Order("name").Find(&subjects).Error
+	return subjects, err
+}
+
+// FindLessons returns the teacher's lessons in all groups between from and to (inclusive dates)
+func (r *TeacherRepository) FindLessons(teacherID int32, from, to time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.Model(&models.Schedule{}).
+		Joins("TimeSlot").
+		Preload("Group").Preload("Subject").
+		Where("schedules.teacher_id = ?", teacherID).
+		Where("schedules.date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("schedules.date").Order("\"TimeSlot\".slot_number").
+		Find(&schedules).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":      err,
+			"teacher_id": teacherID,
+		}).Error("Failed to fetch teacher schedule")
+		return nil, err
+	}
+	return schedules, nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"space/models"
+	"space/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type TelegramRepository struct {
+	db *gorm.DB
+}
+
+func NewTelegramRepository(db *gorm.DB) *TelegramRepository {
+	return &TelegramRepository{db}
+}
+
+func (r *TelegramRepository) GetByUserID(userID int32) (*models.TelegramLink, error) {
+	var link models.TelegramLink
+	if err := r.db.First(&link, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *TelegramRepository) GetByChatID(chatID int64) (*models.TelegramLink, error) {
+	var link models.TelegramLink
+	if err := r.db.Preload("User").First(&link, "chat_id = ?", chatID).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *TelegramRepository) GetByCode(code string) (*models.TelegramLink, error) {
+	var link models.TelegramLink
+	if err := r.db.First(&link, "link_code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *TelegramRepository) Save(link *models.TelegramLink) error {
+	return r.db.Save(link).Error
+}
+
+func (r *TelegramRepository) UpdateSettings(userID int32, digestEnabled, remindersEnabled bool) error {
+	return r.db.Model(&models.TelegramLink{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"digest_enabled":    digestEnabled,
+			"reminders_enabled": remindersEnabled,
+		}).Error
+}
+
+func (r *TelegramRepository) DeleteByUserID(userID int32) error {
+	return r.db.Delete(&models.TelegramLink{}, "user_id = ?", userID).Error
+}
+
+// FindLinked returns all links that have a chat attached
+func (r *TelegramRepository) FindLinked() ([]models.TelegramLink, error) {
+	var links []models.TelegramLink
+	if err := r.db.Preload("User").Where("chat_id IS NOT NULL").Find(&links).Error; err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to fetch linked telegram chats")
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *TelegramRepository) MarkDigestSent(userID int32, at time.Time) error {
+	return r.db.Model(&models.TelegramLink{}).
+		Where("user_id = ?", userID).
+		Update("last_digest_at", at).Error
+}
+
+// FindUpcomingUnreminded returns tasks in the user's groups with a deadline
+// before `until` for which no reminder has been sent yet
+func (r *TelegramRepository) FindUpcomingUnreminded(userID int32, until time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Model(&models.Task{}).
+		Preload("Group").Preload("Subject").
+		Where("group_id IN (?)", r.db.Model(&models.GroupUser{}).Select("group_id").Where("user_id = ?", userID)).
+		Where("deadline IS NOT NULL AND deadline > ? AND deadline <= ?", time.Now(), until).
+		Where("id NOT IN (?)", r.db.Model(&models.TelegramReminder{}).Select("task_id").Where("user_id = ?", userID)).
+		Order("deadline").
+		Find(&tasks).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to find upcoming tasks for reminders")
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *TelegramRepository) CreateReminder(taskID, userID int32) error {
+	return r.db.Create(&models.TelegramReminder{TaskID: taskID, UserID: userID, SentAt: time.Now()}).Error
+}
+
+// FindDigestTasks returns tasks in the user's groups created since `since`
+// or due before `until`
+func (r *TelegramRepository) FindDigestTasks(userID int32, since, until time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Model(&models.Task{}).
+		Preload("Group").Preload("Subject").
+		Where("group_id IN (?)", r.db.Model(&models.GroupUser{}).Select("group_id").Where("user_id = ?", userID)).
+		Where("created_at >= ? OR (deadline IS NOT NULL AND deadline > ? AND deadline <= ?)", since, time.Now(), until).
+		Order("deadline NULLS LAST").
+		Find(&tasks).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to find tasks for digest")
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindPendingForModerator returns pending tasks in groups the user administers or moderates;
+// rejected ones are decided and stay out of the queue
+func (r *TelegramRepository) FindPendingForModerator(userID int32, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Model(&models.Task{}).
+		Preload("Group").Preload("User").
+		Where("verification_status = ?", models.TaskPending).
+		Where("group_id IN (?) OR group_id IN (?)",
+			r.db.Model(&models.Group{}).Select("id").Where("admin_id = ?", userID),
+			r.db.Model(&models.GroupModer{}).Select("group_id").Where("user_id = ?", userID)).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&tasks).Error
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Failed to find pending tasks for moderator")
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -11,7 +11,10 @@ import (
 type UserRepository interface {
 	GetByUsernameOrEmail(username, email string) (*models.User, error)
 	GetByUsername(username string) (*models.User, error)
+	GetByID(userID int32) (*models.User, error)
 	Create(user *models.User) error
+	UpdatePassword(userID int32, hash string) error
+	SetAdmin(userID int32, isAdmin bool) error
 }
 
 type userRepo struct {
@@ -34,6 +37,14 @@ func (r *userRepo) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+func (r *userRepo) UpdatePassword(userID int32, hash string) error {
+	return r.db.Model(&models.User{}).Where("user_id = ?", userID).Update("hash_password", hash).Error
+}
+
+func (r *userRepo) SetAdmin(userID int32, isAdmin bool) error {
+	return r.db.Model(&models.User{}).Where("user_id = ?", userID).Update("is_admin", isAdmin).Error
+}
+
 // repositories/user_repository.go
 func (r *userRepo) GetByUsername(username string) (*models.User, error) {
 	var user models.User
@@ -52,3 +63,11 @@ func (r *userRepo) GetByUsername(username string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepo) GetByID(userID int32) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,31 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// ParseCSV reads a comma- or semicolon-separated roster with a header row naming the full
+// name, email and (optionally) student ID columns in Russian or English. A UTF-8 byte order
+// mark, as written by Excel, is ignored.
+func ParseCSV(r io.Reader) ([]Entry, []ParseError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, ErrUnsupportedFormat
+	}
+	return parseRows(rows)
+}
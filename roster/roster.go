@@ -0,0 +1,126 @@
+// Package roster parses the student lists sent by the dean's office (CSV or XLSX) into
+// entries that the roster importer matches against user accounts.
+package roster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Entry is one parsed roster row
+type Entry struct {
+	Source    string // row reference for the import report, e.g. "row 12"
+	FullName  string
+	Email     string
+	StudentID string
+}
+
+// ParseError reports a row that couldn't be read; parsing continues with the next row
+type ParseError struct {
+	Source  string
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Source, e.Message)
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported roster format")
+
+// headerAliases maps the column headers used in dean's office exports to entry fields.
+// Headers are matched case-insensitively by prefix, in this order.
+var headerAliases = []struct {
+	field   string
+	aliases []string
+}{
+	{"email", []string{"email", "e-mail", "почта", "эл. почта", "электронная почта"}},
+	{"student_id", []string{"student id", "student_id", "студенческий", "номер зачет", "номер зачёт", "зачетная", "зачётная", "зачетка", "зачётка", "id"}},
+	{"full_name", []string{"фио", "ф.и.о", "full name", "full_name", "name", "студент", "student"}},
+}
+
+const headerSearchRows = 10
+
+func normalizeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// findHeader locates the header row among the first rows and returns its index and the
+// column of each field, or -1 when no row has both a name and an email column
+func findHeader(rows [][]string) (int, map[string]int) {
+	for i := 0; i < len(rows) && i < headerSearchRows; i++ {
+		columns := make(map[string]int)
+		for col, title := range rows[i] {
+			title = strings.ToLower(normalizeSpaces(title))
+			if title == "" {
+				continue
+			}
+			for _, header := range headerAliases {
+				if _, taken := columns[header.field]; taken {
+					continue
+				}
+				if matchesAlias(title, header.aliases) {
+					columns[header.field] = col
+					break
+				}
+			}
+		}
+		_, hasName := columns["full_name"]
+		_, hasEmail := columns["email"]
+		if hasName && hasEmail {
+			return i, columns
+		}
+	}
+	return -1, nil
+}
+
+func matchesAlias(title string, aliases []string) bool {
+	for _, alias := range aliases {
+		if title == alias || (len(alias) > 2 && strings.HasPrefix(title, alias)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRows turns the rows below the header into entries, skipping blank rows
+func parseRows(rows [][]string) ([]Entry, []ParseError, error) {
+	headerRow, columns := findHeader(rows)
+	if headerRow < 0 {
+		return nil, nil, errors.New("roster header row not found")
+	}
+
+	cell := func(row []string, field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return normalizeSpaces(row[idx])
+	}
+
+	var entries []Entry
+	var problems []ParseError
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		source := fmt.Sprintf("row %d", i+1)
+		entry := Entry{
+			Source:    source,
+			FullName:  cell(row, "full_name"),
+			Email:     strings.ToLower(cell(row, "email")),
+			StudentID: cell(row, "student_id"),
+		}
+		if entry.FullName == "" && entry.Email == "" && entry.StudentID == "" {
+			continue
+		}
+		if entry.FullName == "" {
+			problems = append(problems, ParseError{source, "full name is missing"})
+			continue
+		}
+		if entry.Email == "" {
+			problems = append(problems, ParseError{source, "email is missing"})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, problems, nil
+}
@@ -0,0 +1,28 @@
+package roster
+
+import (
+	"errors"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ParseXLSX reads the first sheet of a roster workbook; the header row may be preceded by
+// a few title rows
+func ParseXLSX(r io.Reader) ([]Entry, []ParseError, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, ErrUnsupportedFormat
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, errors.New("workbook has no sheets")
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseRows(rows)
+}
@@ -19,52 +19,47 @@ func NewGroupApplicationHandler(service *services.GroupApplicationService) *Grou
 	return &GroupApplicationHandler{service}
 }
 
-// ReviewStatusRequest represents the request body for reviewing an application
-type ReviewStatusRequest struct {
-	Username string `json:"username" binding:"required"`
-	Status   string `json:"status" binding:"required,oneof=approved rejected"`
+// applicationErrors maps application service errors to HTTP responses
+var applicationErrors = errorResponses{
+	"user not found":                                  {http.StatusNotFound, "User not found"},
+	"application not found":                           {http.StatusNotFound, "Application not found"},
+	"application is not pending":                      {http.StatusConflict, "Application is not pending"},
+	"invalid status":                                  {http.StatusBadRequest, "Invalid status"},
+	"group not found":                                 {http.StatusNotFound, "Group not found"},
+	"access denied: admin or moderator role required": {http.StatusForbidden, "Access denied: admin or moderator role required"},
+	"access denied: group owner role required":        {http.StatusForbidden, "Access denied: group owner role required"},
 }
 
 // ReviewApplication godoc
 // @Summary Review a group application
-// @Description Approve or reject a group application for a user to join a group. Requires admin or moderator privileges for the group.
+// @Description Approve or reject a pending application by its ID, with an optional note for the applicant. The reviewer and note are stored on the application, and approval adds the applicant to the group. Requires admin or moderator privileges for the application's group.
 // @Tags group_applications
 // @Accept json
 // @Produce json
-// @Param group_id path int true "Group ID"
-// @Param body body ReviewStatusRequest true "Review details"
+// @Param id path int true "Application ID"
+// @Param body body dto.ReviewApplicationRequest true "Review details"
 // @Param Authorization header string true "Bearer JWT"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} dto.GroupApplicationDTO
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Router /api/groups/applications//review/{id} [patch]
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/applications/review/{id} [patch]
 func (h *GroupApplicationHandler) ReviewApplication(c *gin.Context) {
-	groupIDStr := c.Param("id")
-
-	utils.Logger.WithFields(logrus.Fields{
-		"group_id": groupIDStr,
-	}).Debug("Received review application request")
-
-	groupID, err := strconv.Atoi(groupIDStr)
+	appID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":    err,
-			"group_id": groupIDStr,
-			"place":    "handler",
-		}).Error("Invalid group ID")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
 		return
 	}
 
-	var req ReviewStatusRequest
+	var req dto.ReviewApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.Logger.WithFields(logrus.Fields{
 			"error": err,
 			"body":  req,
 		}).Error("Invalid request body")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username or status"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status or note"})
 		return
 	}
 
@@ -75,37 +70,75 @@ func (h *GroupApplicationHandler) ReviewApplication(c *gin.Context) {
 		return
 	}
 
-	utils.Logger.WithFields(logrus.Fields{
-		"reviewer": username,
-		"username": req.Username,
-		"group_id": groupID,
-		"status":   req.Status,
-	}).Debug("Reviewing application")
+	application, err := h.service.ReviewApplication(int32(appID), username.(string), req.Status, req.Note)
+	if err != nil {
+		respondError(c, applicationErrors, err, "Failed to review application", logrus.Fields{"reviewer": username, "application_id": appID})
+		return
+	}
+	c.JSON(http.StatusOK, application)
+}
 
-	err = h.service.ReviewApplication(int32(groupID), req.Username, username.(string), req.Status)
+// GetMyApplications godoc
+// @Summary List my group applications
+// @Description Returns every application the user has submitted, newest first, with its status (pending, approved, rejected, withdrawn or expired), the reviewer and their note. Pending applications include when they expire.
+// @Tags group_applications
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.GroupApplicationDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/applications/mine [get]
+func (h *GroupApplicationHandler) GetMyApplications(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	applications, err := h.service.GetMyApplications(username.(string))
 	if err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":    err,
-			"reviewer": username,
-			"username": req.Username,
-			"group_id": groupID,
-		}).Error("Failed to review application")
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		respondError(c, applicationErrors, err, "Failed to fetch applications", logrus.Fields{"username": username})
 		return
 	}
+	c.JSON(http.StatusOK, applications)
+}
 
-	utils.Logger.WithFields(logrus.Fields{
-		"reviewer": username,
-		"username": req.Username,
-		"group_id": groupID,
-		"status":   req.Status,
-	}).Info("Application reviewed successfully")
-	c.JSON(http.StatusOK, gin.H{"message": "Application reviewed successfully"})
+// WithdrawApplication godoc
+// @Summary Withdraw a group application
+// @Description Withdraws one of the user's own pending applications.
+// @Tags group_applications
+// @Produce json
+// @Param id path int true "Application ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.GroupApplicationDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/applications/{id}/withdraw [post]
+func (h *GroupApplicationHandler) WithdrawApplication(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	application, err := h.service.WithdrawApplication(username.(string), int32(appID))
+	if err != nil {
+		respondError(c, applicationErrors, err, "Failed to withdraw application", logrus.Fields{"username": username, "application_id": appID})
+		return
+	}
+	c.JSON(http.StatusOK, application)
 }
 
 // CreateApplication godoc
 // @Summary Apply to a group
-// @Description Submit an application to join a group with an optional message.
+// @Description Submit an application to join a group with an optional message. Groups with the open join policy are joined right away (status "joined"), as are applications matching one of the group's auto-approval rules. Private and invite-only groups reject applications, banned users can't apply until the ban is lifted, and groups restricted to their academic group only accept users whose academic group was verified by a site administrator's roster import.
 // @Tags group_applications
 // @Accept json
 // @Produce json
@@ -114,6 +147,7 @@ func (h *GroupApplicationHandler) ReviewApplication(c *gin.Context) {
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/groups/applications [post]
 func (h *GroupApplicationHandler) CreateApplication(c *gin.Context) {
@@ -138,22 +172,35 @@ func (h *GroupApplicationHandler) CreateApplication(c *gin.Context) {
 		"group_id": req.GroupID,
 	}).Debug("Processing group application")
 
-	err := h.service.ApplyToGroup(c, req.GroupID, req.Message)
+	status, err := h.service.ApplyToGroup(c, req.GroupID, req.Message)
 	if err != nil {
 		utils.Logger.WithFields(logrus.Fields{
 			"error":    err,
 			"username": username,
 			"group_id": req.GroupID,
 		}).Error("Failed to fetch application")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch err.Error() {
+		case "group not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		case "group can only be joined by invite", "group is restricted to members of its academic group",
+			"user is banned from this group":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 	utils.Logger.WithFields(logrus.Fields{
 		// "application_id": application.ApplicationID,
 		"username": username,
 		"group_id": req.GroupID,
+		"status":   status,
 	}).Info("Group application created")
-	c.JSON(http.StatusCreated, gin.H{"message": "Application submitted successfully"})
+	if status == "joined" {
+		c.JSON(http.StatusCreated, gin.H{"message": "Joined group successfully", "status": status})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Application submitted successfully", "status": status})
 }
 
 // GetPendingApplications godoc
@@ -189,13 +236,130 @@ func (h *GroupApplicationHandler) GetPendingApplications(c *gin.Context) {
 		return
 	}
 
-	applicationDTOs := make([]dto.GroupApplicationDTO, len(applications))
-	for i, app := range applications {
-		applicationDTOs[i] = dto.ToGroupApplicationDTO(&app)
-	}
 	utils.Logger.WithFields(logrus.Fields{
 		"username":          username,
 		"application_count": len(applications),
 	}).Info("Retrieved pending applications")
-	c.JSON(http.StatusOK, applicationDTOs)
+	c.JSON(http.StatusOK, applications)
+}
+
+// BulkReviewApplications godoc
+// @Summary Review several group applications
+// @Description Approves or rejects up to 100 applications with one decision and an optional note, in a single transaction. Each application is reported separately: ones that don't exist, belong to a group the reviewer doesn't moderate or are no longer pending are skipped with an error without affecting the rest.
+// @Tags group_applications
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkReviewRequest true "Applications and decision"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.BulkReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/applications/review [post]
+func (h *GroupApplicationHandler) BulkReviewApplications(c *gin.Context) {
+	var req dto.BulkReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"body":  req,
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application IDs, status or note"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.BulkReview(username.(string), req)
+	if err != nil {
+		respondError(c, applicationErrors, err, "Failed to review applications", logrus.Fields{"reviewer": username})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetApprovalRules godoc
+// @Summary Get the group's auto-approval rules
+// @Description Returns which applications the group approves without a moderator. Requires admin or moderator privileges for the group.
+// @Tags group_applications
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ApprovalRulesDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/approval-rules [get]
+func (h *GroupApplicationHandler) GetApprovalRules(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.service.GetApprovalRules(username.(string), int32(groupID))
+	if err != nil {
+		respondError(c, applicationErrors, err, "Failed to fetch approval rules", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateApprovalRules godoc
+// @Summary Update the group's auto-approval rules
+// @Description Enables or disables rules that approve new applications on submission: applicant verified in the group's academic group by a site administrator's roster import, or application made through an invite created by a current member. Omitted fields keep their value; pending applications aren't re-evaluated. Requires the group owner.
+// @Tags group_applications
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param body body dto.UpdateApprovalRulesRequest true "Rule changes"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ApprovalRulesDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/approval-rules [patch]
+func (h *GroupApplicationHandler) UpdateApprovalRules(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.UpdateApprovalRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"body":  req,
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval rules"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.service.UpdateApprovalRules(username.(string), int32(groupID), req)
+	if err != nil {
+		respondError(c, applicationErrors, err, "Failed to update approval rules", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
 }
@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"space/repositories"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service}
+}
+
+// auditErrors maps audit service errors to HTTP responses
+var auditErrors = errorResponses{
+	"user not found":  {http.StatusNotFound, "User not found"},
+	"group not found": {http.StatusNotFound, "Group not found"},
+	"access denied: group owner role required": {http.StatusForbidden, "Access denied: group owner role required"},
+}
+
+// parseAuditFilter reads the audit query parameters; the returned message is the 400 error
+func parseAuditFilter(c *gin.Context) (repositories.AuditFilter, string) {
+	filter := repositories.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+	if value := c.Query("actor_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, "Invalid actor ID"
+		}
+		filter.ActorID = int32(id)
+	}
+	if value := c.Query("target_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, "Invalid target ID"
+		}
+		filter.TargetID = int32(id)
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := services.ParseDate(value)
+		if err != nil {
+			return filter, "Invalid from date, expected YYYY-MM-DD"
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := services.ParseDate(value)
+		if err != nil {
+			return filter, "Invalid to date, expected YYYY-MM-DD"
+		}
+		// the to date is inclusive
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	return filter, ""
+}
+
+// GetAuditEvents godoc
+// @Summary Get the group's audit log
+// @Description Returns moderation actions taken in the group (task verification, application reviews, moderator grants and revocations, kicks, bans) with the acting user and before/after snapshots, newest first. Filter by action, actor_id, target_type, target_id and a from/to date range. With format=csv every matching event is returned as a CSV file instead of a page. Requires the group owner; once the group is deleted, the owner who deleted it can still read its log.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Param id path int true "Group ID"
+// @Param action query string false "Action, e.g. task.verify"
+// @Param actor_id query int false "Acting user ID"
+// @Param target_type query string false "Target type: task, user, group or application"
+// @Param target_id query int false "Target ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Events per page (max 100)" default(20)
+// @Param format query string false "json or csv" default(json)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.AuditEventsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/audit [get]
+func (h *AuditHandler) GetAuditEvents(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	filter, message := parseAuditFilter(c)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or csv"})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if format == "csv" {
+		data, err := h.service.ExportCSV(username.(string), int32(groupID), filter)
+		if err != nil {
+			respondError(c, auditErrors, err, "Failed to export audit log", logrus.Fields{"username": username, "group_id": groupID})
+			return
+		}
+		filename := fmt.Sprintf("group-%d-audit-%s.csv", groupID, time.Now().Format("20060102"))
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+		return
+	}
+
+	events, err := h.service.GetEvents(username.(string), int32(groupID), filter, page, pageSize)
+	if err != nil {
+		respondError(c, auditErrors, err, "Failed to fetch audit log", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,208 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type CalendarHandler struct {
+	service *services.CalendarService
+}
+
+func NewCalendarHandler(service *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service}
+}
+
+// requestBaseURL reconstructs the public base URL, honouring the nginx proxy headers
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// CreateFeed godoc
+// @Summary Create a calendar feed
+// @Description Creates a secret iCalendar URL with task deadlines for all of the user's groups, or for one group when group_id is set. Calendar apps can't send bearer tokens, so the URL itself is the credential.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param feed body dto.CreateCalendarFeedRequest false "Feed scope"
+// @Success 201 {object} dto.CalendarFeedDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/calendar/feeds [post]
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	var req dto.CreateCalendarFeedRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	feed, err := h.service.CreateFeed(username.(string), req.GroupID, requestBaseURL(c))
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "group not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		case "access denied: group membership required":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: group membership required"})
+		default:
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"username": username,
+			}).Error("Failed to create calendar feed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// ListFeeds godoc
+// @Summary List calendar feeds
+// @Description Lists the calendar feed URLs of the authenticated user.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.CalendarFeedDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/calendar/feeds [get]
+func (h *CalendarHandler) ListFeeds(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	feeds, err := h.service.ListFeeds(username.(string), requestBaseURL(c))
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"username": username,
+		}).Error("Failed to fetch calendar feeds")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feeds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// RevokeFeed godoc
+// @Summary Revoke a calendar feed
+// @Description Deletes a calendar feed; its URL stops working immediately.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Feed ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/calendar/feeds/{id} [delete]
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	feedIDStr := c.Param("id")
+	feedID, err := strconv.Atoi(feedIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.RevokeFeed(username.(string), int32(feedID)); err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "feed not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		default:
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"username": username,
+				"feed_id":  feedID,
+			}).Error("Failed to revoke calendar feed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar feed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// GetFeed godoc
+// @Summary Download a calendar feed
+// @Description Public, token-authenticated iCalendar feed of task deadlines. Use kind=todo to get VTODO entries instead of VEVENT.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token with .ics suffix"
+// @Param kind query string false "event or todo" default(event)
+// @Success 200 {string} string
+// @Failure 404 {object} map[string]string
+// @Router /calendar/{token} [get]
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	kind := c.DefaultQuery("kind", "event")
+	if kind != "event" && kind != "todo" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind"})
+		return
+	}
+
+	body, err := h.service.RenderFeed(token, kind)
+	if err != nil {
+		if err.Error() == "feed not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+			return
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to render calendar feed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render calendar feed"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+	"space/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// errorResponses maps service error messages to HTTP responses
+type errorResponses map[string]struct {
+	status  int
+	message string
+}
+
+// respondError answers with the mapped response for a known service error, or logs it and
+// answers 500 with logMessage
+func respondError(c *gin.Context, responses errorResponses, err error, logMessage string, fields logrus.Fields) {
+	if mapped, ok := responses[err.Error()]; ok {
+		c.JSON(mapped.status, gin.H{"error": mapped.message})
+		return
+	}
+	fields["error"] = err
+	utils.Logger.WithFields(fields).Error(logMessage)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": logMessage})
+}
@@ -107,7 +107,7 @@ func (h *GroupHandler) GetAllGroups(c *gin.Context) {
 
 // GetAvailableGroups godoc
 // @Summary Get groups available to apply to
-// @Description Retrieves a paginated list of groups where the authenticated user is not a member, admin, or moderator.
+// @Description Retrieves a paginated list of public groups where the authenticated user is not a member, admin, or moderator. Groups restricted to their academic group are only listed to users verified in that academic group by a site administrator's roster import.
 // @Tags groups
 // @Accept json
 // @Produce json
@@ -186,7 +186,7 @@ func (h *GroupHandler) GetAvailableGroups(c *gin.Context) {
 
 // CreateGroup godoc
 // @Summary Create a new group
-// @Description Creates a group with the provided details, setting the authenticated user as admin.
+// @Description Creates a group with the provided details, setting the authenticated user as admin. Visibility (public, unlisted, private) defaults to public and join_policy (open, application, invite_only) to application.
 // @Tags groups
 // @Accept json
 // @Produce json
@@ -207,19 +207,12 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	groupDTO := dto.GroupDTO{
-		ID:              group.ID,
-		Name:            group.Name,
-		AdminUsername:   group.Admin.Username,
-		AcademicGroupID: group.AcademicGroupID,
-		AcademicGroup:   group.AcademicGroup.Name,
-	}
-	c.JSON(http.StatusCreated, groupDTO)
+	c.JSON(http.StatusCreated, dto.ToGroupDTO(&group))
 }
 
 // UpdateGroup godoc
 // @Summary Update a group
-// @Description Updates a group's name, restricted to the group admin.
+// @Description Updates a group's name, visibility and join policy, restricted to the group admin.
 // @Tags groups
 // @Accept json
 // @Produce json
@@ -239,7 +232,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
 		return
 	}
-	var input models.Group
+	var input dto.UpdateGroupRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
@@ -252,12 +245,21 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 	if input.Name != "" {
 		group.Name = input.Name
 	}
+	if input.Visibility != nil {
+		group.Visibility = *input.Visibility
+	}
+	if input.JoinPolicy != nil {
+		group.JoinPolicy = *input.JoinPolicy
+	}
+	if input.SameAcademicGroupOnly != nil {
+		group.SameAcademicGroupOnly = *input.SameAcademicGroupOnly
+	}
 
 	if err := h.service.UpdateGroup(c, group); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, group)
+	c.JSON(http.StatusOK, dto.ToGroupDTO(group))
 }
 
 // DeleteGroup godoc
@@ -376,7 +378,7 @@ func (h *SubjectHandler) CreateSubject(c *gin.Context) {
 
 // UpdateSubject godoc
 // @Summary Update a subject
-// @Description Updates a subject's name, description, or group_id
+// @Description Updates a subject's name, description, group_id or lead TeacherID
 // @Tags subjects
 // @Accept json
 // @Produce json
@@ -412,6 +414,9 @@ func (h *SubjectHandler) UpdateSubject(c *gin.Context) {
 	if input.AcademicGroupID != 0 {
 		subject.AcademicGroupID = input.AcademicGroupID
 	}
+	if input.TeacherID != nil {
+		subject.TeacherID = input.TeacherID
+	}
 	if err := h.service.UpdateSubject(subject); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -71,109 +71,6 @@ func (h *GroupUserHandler) GetGroupUser(c *gin.Context) {
 	c.JSON(http.StatusOK, groupUser)
 }
 
-// CreateGroupUser godoc
-// @Summary Add a user to a group
-// @Description Creates a group-user relationship with a role
-// @Tags group-users
-// @Accept json
-// @Produce json
-// @Param Authorization header string true "Bearer JWT"
-// @Param group_user body models.GroupUser true "GroupUser data"
-// @Success 201 {object} models.GroupUser
-// @Failure 400 {object} map[string]string "Invalid request body or missing required fields"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Router /api/group-users [post]
-func (h *GroupUserHandler) CreateGroupUser(c *gin.Context) {
-	var groupUser models.GroupUser
-	if err := c.ShouldBindJSON(&groupUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-	if err := h.service.CreateGroupUser(&groupUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusCreated, groupUser)
-}
-
-// UpdateGroupUser godoc
-// @Summary Update a group-user role
-// @Description Updates the role of a group-user relationship
-// @Tags group-users
-// @Accept json
-// @Produce json
-// @Param group_id path int true "Group ID"
-// @Param user_id path int true "User ID"
-// @Param Authorization header string true "Bearer JWT"
-// @Param group_user body models.GroupUser true "GroupUser data (role only)"
-// @Success 200 {object} models.GroupUser
-// @Failure 400 {object} map[string]string "Invalid group ID, user ID, or request body"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Group-user not found"
-// @Router /api/group-users/{group_id}/{user_id} [patch]
-func (h *GroupUserHandler) UpdateGroupUser(c *gin.Context) {
-	groupID, err := strconv.Atoi(c.Param("group_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
-		return
-	}
-	userID, err := strconv.Atoi(c.Param("user_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-	var input models.GroupUser
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-	groupUser, err := h.service.GetGroupUser(int32(groupID), int32(userID))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Group-user not found"})
-		return
-	}
-	if input.Role != "" {
-		groupUser.Role = input.Role
-	}
-	if err := h.service.UpdateGroupUser(groupUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, groupUser)
-}
-
-// DeleteGroupUser godoc
-// @Summary Remove a user from a group
-// @Description Deletes a group-user relationship
-// @Tags group-users
-// @Accept json
-// @Produce json
-// @Param group_id path int true "Group ID"
-// @Param user_id path int true "User ID"
-// @Param Authorization header string true "Bearer JWT"
-// @Success 200 {object} map[string]string "Group-user deleted"
-// @Failure 400 {object} map[string]string "Invalid group or user ID"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Group-user not found"
-// @Router /api/group-users/{group_id}/{user_id} [delete]
-func (h *GroupUserHandler) DeleteGroupUser(c *gin.Context) {
-	groupID, err := strconv.Atoi(c.Param("group_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
-		return
-	}
-	userID, err := strconv.Atoi(c.Param("user_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-	if err := h.service.DeleteGroupUser(int32(groupID), int32(userID)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Group-user not found"})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"message": "Group-user deleted"})
-}
-
 // GetAcademicGroup godoc
 // @Summary Get an academic group by ID
 // @Description Retrieves an academic group
@@ -325,63 +222,6 @@ func (h *GroupModerHandler) GetGroupModer(c *gin.Context) {
 	c.JSON(http.StatusOK, groupModer)
 }
 
-// CreateGroupModer godoc
-// @Summary Add a moderator to a group
-// @Description Creates a group-moderator relationship
-// @Tags group-moders
-// @Accept json
-// @Produce json
-// @Param Authorization header string true "Bearer JWT"
-// @Param group_moder body models.GroupModer true "GroupModer data"
-// @Success 201 {object} models.GroupModer
-// @Failure 400 {object} map[string]string "Invalid request body or moderator already exists"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Router /api/group-moders [post]
-func (h *GroupModerHandler) CreateGroupModer(c *gin.Context) {
-	var groupModer models.GroupModer
-	if err := c.ShouldBindJSON(&groupModer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-	if err := h.service.CreateGroupModer(&groupModer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusCreated, groupModer)
-}
-
-// DeleteGroupModer godoc
-// @Summary Remove a moderator from a group
-// @Description Deletes a group-moderator relationship
-// @Tags group-moders
-// @Accept json
-// @Produce json
-// @Param group_id path int true "Group ID"
-// @Param user_id path int true "User ID"
-// @Param Authorization header string true "Bearer JWT"
-// @Success 200 {object} map[string]string "Group-moderator deleted"
-// @Failure 400 {object} map[string]string "Invalid group or user ID"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Group-moderator not found"
-// @Router /api/group-moders/{group_id}/{user_id} [delete]
-func (h *GroupModerHandler) DeleteGroupModer(c *gin.Context) {
-	groupID, err := strconv.Atoi(c.Param("group_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
-		return
-	}
-	userID, err := strconv.Atoi(c.Param("user_id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-	if err := h.service.DeleteGroupModer(int32(groupID), int32(userID)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Group-moderator not found"})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"message": "Group-moderator deleted"})
-}
-
 // GetAllAcademicGroups godoc
 // @Summary Get all academic groups
 // @Description Retrieve a list of all academic groups
@@ -0,0 +1,275 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupInviteHandler struct {
+	service *services.GroupInviteService
+}
+
+func NewGroupInviteHandler(service *services.GroupInviteService) *GroupInviteHandler {
+	return &GroupInviteHandler{service}
+}
+
+// inviteErrors maps invite service errors to HTTP responses
+var inviteErrors = errorResponses{
+	"user not found":                                  {http.StatusNotFound, "User not found"},
+	"group not found":                                 {http.StatusNotFound, "Group not found"},
+	"invite not found":                                {http.StatusNotFound, "Invite not found"},
+	"invite has been revoked":                         {http.StatusGone, "Invite has been revoked"},
+	"invite has expired":                              {http.StatusGone, "Invite has expired"},
+	"invite has reached its usage limit":              {http.StatusGone, "Invite has reached its usage limit"},
+	"user is already a group member":                  {http.StatusConflict, "You are already a member of this group"},
+	"user is banned from this group":                  {http.StatusForbidden, "You are banned from this group"},
+	"application already submitted and pending":       {http.StatusConflict, "Application already submitted and pending"},
+	"set either expires_in_hours or expires_at":       {http.StatusBadRequest, "Set either expires_in_hours or expires_at, not both"},
+	"expiry must be in the future":                    {http.StatusBadRequest, "Expiry must be in the future"},
+	"access denied: admin or moderator role required": {http.StatusForbidden, "Access denied: admin or moderator role required"},
+}
+
+// CreateInvite godoc
+// @Summary Create a group invite
+// @Description Generates an invite code and link for the group. With auto_approve users who accept it join right away, otherwise a pending application is created for review. The invite can expire after expires_in_hours or at expires_at and be limited to max_uses redemptions. Requires admin or moderator role.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param invite body dto.CreateInviteRequest true "Invite settings"
+// @Success 201 {object} dto.InviteDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/invites [post]
+func (h *GroupInviteHandler) CreateInvite(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.CreateInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invite, err := h.service.CreateInvite(username.(string), int32(groupID), req, requestBaseURL(c))
+	if err != nil {
+		respondError(c, inviteErrors, err, "Failed to create invite", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusCreated, invite)
+}
+
+// ListInvites godoc
+// @Summary List group invites
+// @Description Returns all invites of the group, including expired and revoked ones, with their usage counts. Requires admin or moderator role.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.InviteDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/invites [get]
+func (h *GroupInviteHandler) ListInvites(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invites, err := h.service.ListInvites(username.(string), int32(groupID), requestBaseURL(c))
+	if err != nil {
+		respondError(c, inviteErrors, err, "Failed to fetch invites", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite godoc
+// @Summary Revoke a group invite
+// @Description Disables the invite. Users who already joined through it stay in the group. Requires admin or moderator role.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param invite_id path int true "Invite ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /api/groups/{id}/invites/{invite_id} [delete]
+func (h *GroupInviteHandler) RevokeInvite(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	inviteID, err := strconv.Atoi(c.Param("invite_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.RevokeInvite(username.(string), int32(groupID), int32(inviteID)); err != nil {
+		respondError(c, inviteErrors, err, "Failed to revoke invite", logrus.Fields{"username": username, "invite_id": inviteID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// GetInviteUses godoc
+// @Summary List invite redemptions
+// @Description Returns who used the invite and whether they joined or applied. Requires admin or moderator role.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param invite_id path int true "Invite ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.InviteUseDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/invites/{invite_id}/uses [get]
+func (h *GroupInviteHandler) GetInviteUses(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	inviteID, err := strconv.Atoi(c.Param("invite_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	uses, err := h.service.GetInviteUses(username.(string), int32(groupID), int32(inviteID))
+	if err != nil {
+		respondError(c, inviteErrors, err, "Failed to fetch invite uses", logrus.Fields{"username": username, "invite_id": inviteID})
+		return
+	}
+	c.JSON(http.StatusOK, uses)
+}
+
+// PreviewInvite godoc
+// @Summary Preview an invite
+// @Description Shows which group the invite code leads to and whether it can still be used.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param code path string true "Invite code"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.InvitePreviewDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/invites/{code} [get]
+func (h *GroupInviteHandler) PreviewInvite(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	preview, err := h.service.PreviewInvite(username.(string), c.Param("code"))
+	if err != nil {
+		respondError(c, inviteErrors, err, "Failed to fetch invite", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// AcceptInvite godoc
+// @Summary Accept an invite
+// @Description Joins the group through the invite code. Auto-approving invites add the user to the group right away; others create a pending application with the optional message, which the group's auto-approval rules may approve immediately (result joined).
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param code path string true "Invite code"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.AcceptInviteRequest false "Application message"
+// @Success 200 {object} dto.AcceptInviteResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /api/invites/{code}/accept [post]
+func (h *GroupInviteHandler) AcceptInvite(c *gin.Context) {
+	var req dto.AcceptInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.AcceptInvite(username.(string), c.Param("code"), req.Message)
+	if err != nil {
+		respondError(c, inviteErrors, err, "Failed to accept invite", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
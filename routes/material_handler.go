@@ -0,0 +1,657 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MaterialHandler struct {
+	service *services.MaterialService
+}
+
+func NewMaterialHandler(service *services.MaterialService) *MaterialHandler {
+	return &MaterialHandler{service}
+}
+
+// materialErrors maps material service errors to HTTP responses
+var materialErrors = errorResponses{
+	"user not found":                                        {http.StatusNotFound, "User not found"},
+	"subject not found":                                     {http.StatusNotFound, "Subject not found"},
+	"group not found":                                       {http.StatusNotFound, "Group not found"},
+	"material not found":                                    {http.StatusNotFound, "Material not found"},
+	"attachment not found":                                  {http.StatusNotFound, "Attachment not found"},
+	"revision not found":                                    {http.StatusNotFound, "Revision not found"},
+	"revision is already current":                           {http.StatusBadRequest, "Revision is already the current version"},
+	"material was changed by someone else":                  {http.StatusConflict, "Material was changed by someone else, reload it and reapply your edit"},
+	"material title is required":                            {http.StatusBadRequest, "Material title is required"},
+	"material content is too large":                         {http.StatusBadRequest, "Material content is too large"},
+	"attachment is too large":                               {http.StatusBadRequest, "Attachment is too large"},
+	"too many attachments":                                  {http.StatusBadRequest, "Material has too many attachments"},
+	"invalid material order":                                {http.StatusBadRequest, "Material order must list distinct materials of the subject"},
+	"group does not belong to the subject's academic group": {http.StatusBadRequest, "Group does not belong to the subject's academic group"},
+	"access denied: group membership required":              {http.StatusForbidden, "Access denied: group membership required"},
+	"access denied: admin or moderator role required":       {http.StatusForbidden, "Access denied: admin or moderator role required"},
+	"access denied: administrator role required":            {http.StatusForbidden, "Access denied: administrator role required"},
+	"access denied: only the author, admins and moderators can edit materials": {http.StatusForbidden, "Access denied: only the author, admins and moderators can edit materials"},
+}
+
+// ListMaterials godoc
+// @Summary List study materials of a subject
+// @Description Returns the subject's materials in display order: those shared with the whole academic group plus those of the user's groups (or of group_id only). Only members of groups in the subject's academic group have access. include_inactive=true also lists deactivated materials and requires admin or moderator role in group_id, or a site administrator without it.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Param group_id query int false "Only this group's materials besides the shared ones"
+// @Param include_inactive query bool false "Include deactivated materials"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/subjects/{id}/materials [get]
+func (h *MaterialHandler) ListMaterials(c *gin.Context) {
+	subjectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID"})
+		return
+	}
+	var groupID *int32
+	if value := c.Query("group_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+		groupID32 := int32(id)
+		groupID = &groupID32
+	}
+	includeInactive, _ := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	materials, err := h.service.ListMaterials(username.(string), int32(subjectID), groupID, includeInactive)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to fetch materials", logrus.Fields{"username": username, "subject_id": subjectID})
+		return
+	}
+	c.JSON(http.StatusOK, materials)
+}
+
+// CreateMaterial godoc
+// @Summary Create a study material
+// @Description Adds a Markdown material to the subject. With group_id the material is visible to that group only and any of its members may post it; without it the material is shared with every group of the subject's academic group and requires a site administrator. New materials go to the end of the list unless position is set.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param material body dto.CreateMaterialRequest true "Material"
+// @Success 201 {object} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/subjects/{id}/materials [post]
+func (h *MaterialHandler) CreateMaterial(c *gin.Context) {
+	subjectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID"})
+		return
+	}
+
+	var req dto.CreateMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	material, err := h.service.CreateMaterial(username.(string), int32(subjectID), req)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to create material", logrus.Fields{"username": username, "subject_id": subjectID})
+		return
+	}
+	c.JSON(http.StatusCreated, material)
+}
+
+// ReorderMaterials godoc
+// @Summary Reorder study materials
+// @Description Moves the listed materials to the top in the given order; the other materials keep their relative order after them. Requires a site administrator, since the order of shared materials is changed too.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param order body dto.ReorderMaterialsRequest true "Material IDs in display order"
+// @Success 200 {array} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/subjects/{id}/materials/order [put]
+func (h *MaterialHandler) ReorderMaterials(c *gin.Context) {
+	subjectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID"})
+		return
+	}
+
+	var req dto.ReorderMaterialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	materials, err := h.service.ReorderMaterials(username.(string), int32(subjectID), req.MaterialIDs)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to reorder materials", logrus.Fields{"username": username, "subject_id": subjectID})
+		return
+	}
+	c.JSON(http.StatusOK, materials)
+}
+
+// GetMaterial godoc
+// @Summary Get a study material
+// @Description Returns a material with its Markdown content and attachment list.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id} [get]
+func (h *MaterialHandler) GetMaterial(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	material, err := h.service.GetMaterial(username.(string), int32(id))
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to fetch material", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, material)
+}
+
+// UpdateMaterial godoc
+// @Summary Update a study material
+// @Description Updates the title or Markdown content, saving the result as a new revision; is_active=false hides the material and true restores it. Pass base_version (the version the edit started from) to get 409 instead of overwriting someone else's newer edit. Allowed for the author and the admins and moderators of the material's group; shared materials for the author and site administrators.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param material body dto.UpdateMaterialRequest true "Fields to update"
+// @Success 200 {object} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/materials/{id} [patch]
+func (h *MaterialHandler) UpdateMaterial(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	var req dto.UpdateMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	material, err := h.service.UpdateMaterial(username.(string), int32(id), req)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to update material", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, material)
+}
+
+// DeactivateMaterial godoc
+// @Summary Deactivate a study material
+// @Description Hides the material from members without deleting it. Restore it with PATCH is_active=true.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id} [delete]
+func (h *MaterialHandler) DeactivateMaterial(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeactivateMaterial(username.(string), int32(id)); err != nil {
+		respondError(c, materialErrors, err, "Failed to deactivate material", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Material deactivated"})
+}
+
+// attachmentIDs parses the material and attachment IDs from the path
+func attachmentIDs(c *gin.Context) (int32, int32, error) {
+	materialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("Invalid material ID")
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		return 0, 0, errors.New("Invalid attachment ID")
+	}
+	return int32(materialID), int32(attachmentID), nil
+}
+
+// UploadAttachment godoc
+// @Summary Attach a file to a study material
+// @Description Uploads a file (up to 10 MB) to the material. Allowed for the author and the admins and moderators of the material's group; shared materials for the author and site administrators.
+// @Tags materials
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param file formData file true "File"
+// @Success 201 {object} dto.MaterialAttachmentDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/attachments [post]
+func (h *MaterialHandler) UploadAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	if fileHeader.Size > services.MaxAttachmentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attachment is too large"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File can't be read"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File can't be read"})
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(data)
+	}
+	attachment, err := h.service.AddAttachment(username.(string), int32(id), filepath.Base(fileHeader.Filename), contentType, data)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to upload attachment", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment godoc
+// @Summary Download a material attachment
+// @Description Returns the attached file.
+// @Tags materials
+// @Produce octet-stream
+// @Param id path int true "Material ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/attachments/{attachment_id} [get]
+func (h *MaterialHandler) DownloadAttachment(c *gin.Context) {
+	materialID, attachmentID, err := attachmentIDs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	attachment, err := h.service.GetAttachment(username.(string), materialID, attachmentID)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to fetch attachment", logrus.Fields{"username": username, "material_id": materialID, "attachment_id": attachmentID})
+		return
+	}
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Data(http.StatusOK, contentType, attachment.Data)
+}
+
+// DeleteAttachment godoc
+// @Summary Delete a material attachment
+// @Description Removes a file from the material. Allowed for the author and the admins and moderators of the material's group; shared materials for the author and site administrators.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/attachments/{attachment_id} [delete]
+func (h *MaterialHandler) DeleteAttachment(c *gin.Context) {
+	materialID, attachmentID, err := attachmentIDs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteAttachment(username.(string), materialID, attachmentID); err != nil {
+		respondError(c, materialErrors, err, "Failed to delete attachment", logrus.Fields{"username": username, "material_id": materialID, "attachment_id": attachmentID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// revisionVersion parses the material ID and revision version from the path
+func revisionVersion(c *gin.Context) (int32, int32, error) {
+	materialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("Invalid material ID")
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return 0, 0, errors.New("Invalid revision version")
+	}
+	return int32(materialID), int32(version), nil
+}
+
+// GetRevisions godoc
+// @Summary List revisions of a study material
+// @Description Returns every saved revision of the material, newest first, with the editor and comment.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.MaterialRevisionDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/revisions [get]
+func (h *MaterialHandler) GetRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	revisions, err := h.service.GetRevisions(username.(string), int32(id))
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to fetch material revisions", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision godoc
+// @Summary Get a revision of a study material
+// @Description Returns the title and Markdown content of the material as of the given version.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param version path int true "Revision version"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.MaterialRevisionDetailDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/revisions/{version} [get]
+func (h *MaterialHandler) GetRevision(c *gin.Context) {
+	id, version, err := revisionVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	revision, err := h.service.GetRevision(username.(string), id, version)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to fetch material revision", logrus.Fields{"username": username, "material_id": id, "version": version})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary Compare two revisions of a study material
+// @Description Returns a unified diff between two versions of the material; the title is compared as a leading "# Title" line. to defaults to the current version. With format=raw the diff is returned as text/x-diff.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param from query int true "Old version"
+// @Param to query int false "New version, defaults to the current one"
+// @Param format query string false "json or raw" default(json)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.MaterialDiffDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/materials/{id}/diff [get]
+func (h *MaterialHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from version"})
+		return
+	}
+	var to *int32
+	if value := c.Query("to"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to version"})
+			return
+		}
+		to32 := int32(parsed)
+		to = &to32
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "raw" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or raw"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	diff, err := h.service.DiffRevisions(username.(string), int32(id), int32(from), to)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to compare material revisions", logrus.Fields{"username": username, "material_id": id})
+		return
+	}
+	if format == "raw" {
+		c.Data(http.StatusOK, "text/x-diff; charset=utf-8", []byte(diff.Diff))
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision godoc
+// @Summary Roll a study material back to a revision
+// @Description Creates a new revision with the title and content of the given version; later revisions stay in the history. Allowed for the author and the admins and moderators of the material's group; shared materials for the author and site administrators.
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param id path int true "Material ID"
+// @Param version path int true "Version to restore"
+// @Param Authorization header string true "Bearer JWT"
+// @Param restore body dto.RestoreRevisionRequest false "Revision comment"
+// @Success 200 {object} dto.MaterialDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/materials/{id}/revisions/{version}/restore [post]
+func (h *MaterialHandler) RestoreRevision(c *gin.Context) {
+	id, version, err := revisionVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.RestoreRevisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	material, err := h.service.RestoreRevision(username.(string), id, version, req.Comment)
+	if err != nil {
+		respondError(c, materialErrors, err, "Failed to restore material revision", logrus.Fields{"username": username, "material_id": id, "version": version})
+		return
+	}
+	c.JSON(http.StatusOK, material)
+}
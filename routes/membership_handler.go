@@ -0,0 +1,495 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupMembershipHandler struct {
+	service *services.GroupMembershipService
+}
+
+func NewGroupMembershipHandler(service *services.GroupMembershipService) *GroupMembershipHandler {
+	return &GroupMembershipHandler{service}
+}
+
+// membershipErrors maps membership service errors to HTTP responses
+var membershipErrors = errorResponses{
+	"user not found":             {http.StatusNotFound, "User not found"},
+	"target user not found":      {http.StatusNotFound, "Target user not found"},
+	"group not found":            {http.StatusNotFound, "Group not found"},
+	"ban not found":              {http.StatusNotFound, "User is not banned"},
+	"user is not a group member": {http.StatusNotFound, "User is not a group member"},
+	"user is already banned":     {http.StatusConflict, "User is already banned"},
+	"group owner must transfer ownership before leaving":       {http.StatusConflict, "Group owner must transfer ownership before leaving"},
+	"group owner cannot be removed":                            {http.StatusForbidden, "Group owner cannot be removed"},
+	"only the group owner can remove moderators":               {http.StatusForbidden, "Only the group owner can remove moderators"},
+	"cannot remove yourself, leave the group instead":          {http.StatusBadRequest, "Cannot remove yourself, leave the group instead"},
+	"access denied: admin or moderator role required":          {http.StatusForbidden, "Access denied: admin or moderator role required"},
+	"access denied: group owner role required":                 {http.StatusForbidden, "Access denied: group owner role required"},
+	"owner role can only be changed by transferring ownership": {http.StatusConflict, "Owner role can only be changed by transferring ownership"},
+	"user is not a moderator":                                  {http.StatusNotFound, "User is not a moderator"},
+}
+
+// LeaveGroup godoc
+// @Summary Leave a group
+// @Description Removes the authenticated user from the group. The owner has to transfer ownership first.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/leave [post]
+func (h *GroupMembershipHandler) LeaveGroup(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Leave(username.(string), int32(groupID)); err != nil {
+		respondError(c, membershipErrors, err, "Failed to leave group", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left group"})
+}
+
+// KickMember godoc
+// @Summary Kick a member
+// @Description Removes a member from the group with an optional reason; they may apply again. Moderators can't kick the owner or other moderators. Requires admin or moderator role.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.KickRequest false "Reason"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/members/{user_id}/kick [post]
+func (h *GroupMembershipHandler) KickMember(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.KickRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Kick(username.(string), int32(groupID), int32(userID), req.Reason); err != nil {
+		respondError(c, membershipErrors, err, "Failed to kick member", logrus.Fields{"username": username, "group_id": groupID, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// RemoveGroupUser godoc
+// @Summary Remove a user from a group
+// @Description Removing yourself leaves the group; removing someone else kicks them and requires admin or moderator role.
+// @Tags group-users
+// @Accept json
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string "Group-user deleted"
+// @Failure 400 {object} map[string]string "Invalid group or user ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Group-user not found"
+// @Router /api/group-users/{group_id}/{user_id} [delete]
+func (h *GroupMembershipHandler) RemoveGroupUser(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.RemoveMember(username.(string), int32(groupID), int32(userID)); err != nil {
+		respondError(c, membershipErrors, err, "Failed to remove group user", logrus.Fields{"username": username, "group_id": groupID, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Group-user deleted"})
+}
+
+// GetBans godoc
+// @Summary List group bans
+// @Description Returns the group's active bans, or all bans with include_lifted=true. Requires admin or moderator role.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param include_lifted query bool false "Include lifted bans"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.GroupBanDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/bans [get]
+func (h *GroupMembershipHandler) GetBans(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	includeLifted, _ := strconv.ParseBool(c.DefaultQuery("include_lifted", "false"))
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	bans, err := h.service.GetBans(username.(string), int32(groupID), includeLifted)
+	if err != nil {
+		respondError(c, membershipErrors, err, "Failed to fetch bans", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, bans)
+}
+
+// BanUser godoc
+// @Summary Ban a user from a group
+// @Description Removes the user from the group if they are a member, rejects their pending application and blocks new applications and invites until the ban is lifted. Moderators can't ban the owner or other moderators. Requires admin or moderator role.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.BanRequest true "User and reason"
+// @Success 201 {object} dto.GroupBanDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/bans [post]
+func (h *GroupMembershipHandler) BanUser(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.BanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ban, err := h.service.Ban(username.(string), int32(groupID), req)
+	if err != nil {
+		respondError(c, membershipErrors, err, "Failed to ban user", logrus.Fields{"username": username, "group_id": groupID, "user_id": req.UserID})
+		return
+	}
+	c.JSON(http.StatusCreated, ban)
+}
+
+// UnbanUser godoc
+// @Summary Lift a ban
+// @Description Lets the user apply to the group again. They are not re-added automatically. Requires admin or moderator role.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.UnbanRequest false "Reason"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/bans/{user_id} [delete]
+func (h *GroupMembershipHandler) UnbanUser(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.UnbanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Unban(username.(string), int32(groupID), int32(userID), req.Reason); err != nil {
+		respondError(c, membershipErrors, err, "Failed to lift ban", logrus.Fields{"username": username, "group_id": groupID, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ban lifted"})
+}
+
+// GetMembershipEvents godoc
+// @Summary Group membership log
+// @Description Lists who left the group, was kicked, banned or unbanned, by whom and why, newest first. Requires admin or moderator role.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(20)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.MembershipEventsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/membership-log [get]
+func (h *GroupMembershipHandler) GetMembershipEvents(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	events, err := h.service.GetEvents(username.(string), int32(groupID), page, pageSize)
+	if err != nil {
+		respondError(c, membershipErrors, err, "Failed to fetch membership log", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// AddModerator godoc
+// @Summary Appoint a moderator
+// @Description Grants moderator rights to a member of the group and records who granted them. Requires the group owner.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.AddModeratorRequest true "Member to appoint"
+// @Success 200 {object} dto.MemberRoleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/moderators [post]
+func (h *GroupMembershipHandler) AddModerator(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.AddModeratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.SetRole(username.(string), int32(groupID), req.UserID, "moderator")
+	if err != nil {
+		respondError(c, membershipErrors, err, "Failed to appoint moderator", logrus.Fields{"username": username, "group_id": groupID, "user_id": req.UserID})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveModerator godoc
+// @Summary Revoke moderator rights
+// @Description Demotes a moderator to a plain member; they stay in the group. Requires the group owner.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/moderators/{user_id} [delete]
+func (h *GroupMembershipHandler) RemoveModerator(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.RemoveModerator(username.(string), int32(groupID), int32(userID)); err != nil {
+		respondError(c, membershipErrors, err, "Failed to revoke moderator", logrus.Fields{"username": username, "group_id": groupID, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Moderator rights revoked"})
+}
+
+// SetMemberRole godoc
+// @Summary Change a member's role
+// @Description Sets a member's role to member or moderator and records who changed it. The owner role is handed over through an ownership transfer. Requires the group owner.
+// @Tags group-members
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param user_id path int true "User ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.SetRoleRequest true "New role"
+// @Success 200 {object} dto.MemberRoleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/members/{user_id}/role [patch]
+func (h *GroupMembershipHandler) SetMemberRole(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req dto.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.SetRole(username.(string), int32(groupID), int32(userID), req.Role)
+	if err != nil {
+		respondError(c, membershipErrors, err, "Failed to change member role", logrus.Fields{"username": username, "group_id": groupID, "user_id": userID})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,278 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupOwnershipHandler struct {
+	service *services.GroupOwnershipService
+}
+
+func NewGroupOwnershipHandler(service *services.GroupOwnershipService) *GroupOwnershipHandler {
+	return &GroupOwnershipHandler{service}
+}
+
+// ownershipErrors maps ownership service errors to HTTP responses
+var ownershipErrors = errorResponses{
+	"user not found":                                          {http.StatusNotFound, "User not found"},
+	"target user not found":                                   {http.StatusNotFound, "Target user not found"},
+	"group not found":                                         {http.StatusNotFound, "Group not found"},
+	"no pending transfer":                                     {http.StatusNotFound, "No pending ownership transfer"},
+	"invalid password":                                        {http.StatusForbidden, "Invalid password"},
+	"cannot transfer ownership to yourself":                   {http.StatusBadRequest, "Cannot transfer ownership to yourself"},
+	"new owner must be a group member":                        {http.StatusBadRequest, "New owner must be a group member"},
+	"only the group owner can transfer ownership":             {http.StatusForbidden, "Only the group owner can transfer ownership"},
+	"only the group owner can cancel the transfer":            {http.StatusForbidden, "Only the group owner can cancel the transfer"},
+	"transfer is addressed to another user":                   {http.StatusForbidden, "Transfer is addressed to another user"},
+	"transfer is no longer pending":                           {http.StatusConflict, "Transfer is no longer pending"},
+	"group owner has changed since the transfer was proposed": {http.StatusConflict, "Group owner has changed since the transfer was proposed"},
+	"access denied: admin or moderator role required":         {http.StatusForbidden, "Access denied: admin or moderator role required"},
+}
+
+// ProposeTransfer godoc
+// @Summary Propose a group ownership transfer
+// @Description Offers ownership of the group to another member. Nothing changes until they accept; the current owner then stays on as a moderator. A new proposal replaces a pending one. Only the owner can propose.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.ProposeTransferRequest true "New owner"
+// @Success 201 {object} dto.OwnershipTransferDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/transfer [post]
+func (h *GroupOwnershipHandler) ProposeTransfer(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.ProposeTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transfer, err := h.service.ProposeTransfer(username.(string), int32(groupID), req)
+	if err != nil {
+		respondError(c, ownershipErrors, err, "Failed to propose ownership transfer", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransfers godoc
+// @Summary List group ownership transfers
+// @Description Returns the group's pending and past ownership transfers, including automatic successions. Requires admin or moderator role.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.OwnershipTransferDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/transfer [get]
+func (h *GroupOwnershipHandler) GetTransfers(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transfers, err := h.service.GetTransfers(username.(string), int32(groupID))
+	if err != nil {
+		respondError(c, ownershipErrors, err, "Failed to fetch ownership transfers", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// CancelTransfer godoc
+// @Summary Cancel a pending ownership transfer
+// @Description Withdraws the owner's pending proposal.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/transfer [delete]
+func (h *GroupOwnershipHandler) CancelTransfer(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.CancelTransfer(username.(string), int32(groupID)); err != nil {
+		respondError(c, ownershipErrors, err, "Failed to cancel ownership transfer", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transfer cancelled"})
+}
+
+// AcceptTransfer godoc
+// @Summary Accept group ownership
+// @Description Accepts the pending transfer addressed to the authenticated user and makes them the group owner.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.OwnershipTransferDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/groups/{id}/transfer/accept [post]
+func (h *GroupOwnershipHandler) AcceptTransfer(c *gin.Context) {
+	h.respondToTransfer(c, true)
+}
+
+// DeclineTransfer godoc
+// @Summary Decline group ownership
+// @Description Declines the pending transfer addressed to the authenticated user.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.OwnershipTransferDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/transfer/decline [post]
+func (h *GroupOwnershipHandler) DeclineTransfer(c *gin.Context) {
+	h.respondToTransfer(c, false)
+}
+
+func (h *GroupOwnershipHandler) respondToTransfer(c *gin.Context, accept bool) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transfer, err := h.service.RespondToTransfer(username.(string), int32(groupID), accept)
+	if err != nil {
+		respondError(c, ownershipErrors, err, "Failed to respond to ownership transfer", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+// GetIncomingTransfers godoc
+// @Summary List ownership offers
+// @Description Returns the pending ownership transfers addressed to the authenticated user.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.OwnershipTransferDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/transfers/incoming [get]
+func (h *GroupOwnershipHandler) GetIncomingTransfers(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transfers, err := h.service.GetIncomingTransfers(username.(string))
+	if err != nil {
+		respondError(c, ownershipErrors, err, "Failed to fetch ownership transfers", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// DeleteAccount godoc
+// @Summary Delete the authenticated account
+// @Description Deletes the account after confirming the password. Each group the user owns passes to its longest-serving moderator, or its longest-standing member when it has no moderators; groups with no other members are deleted. Tasks and materials the user authored are kept under an anonymized name.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.DeleteAccountRequest true "Password confirmation"
+// @Success 200 {object} dto.DeleteAccountResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/account [delete]
+func (h *GroupOwnershipHandler) DeleteAccount(c *gin.Context) {
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.DeleteAccount(username.(string), req.Password)
+	if err != nil {
+		respondError(c, ownershipErrors, err, "Failed to delete account", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -35,7 +35,7 @@ func RegisterHandler(authService *services.AuthService) gin.HandlerFunc {
 		err := authService.RegisterUser(input)
 		if err != nil {
 			status := http.StatusInternalServerError
-			if err.Error() == "user already exists" {
+			if err.Error() == "user already exists" || err.Error() == "username is reserved" {
 				status = http.StatusBadRequest
 			}
 			c.JSON(status, gin.H{"error": err.Error()})
@@ -0,0 +1,267 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"space/models/dto"
+	"space/repositories"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RoomHandler struct {
+	service *services.RoomService
+}
+
+func NewRoomHandler(service *services.RoomService) *RoomHandler {
+	return &RoomHandler{service}
+}
+
+// roomFilter reads the building, min_capacity and comma-separated equipment query parameters
+func roomFilter(c *gin.Context) (repositories.RoomFilter, error) {
+	var minCapacity int
+	if value := c.Query("min_capacity"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return repositories.RoomFilter{}, errors.New("invalid min_capacity")
+		}
+		minCapacity = parsed
+	}
+	var equipment []string
+	if value := c.Query("equipment"); value != "" {
+		equipment = strings.Split(value, ",")
+	}
+	return services.NewRoomFilter(c.Query("building"), int32(minCapacity), equipment), nil
+}
+
+// ListRooms godoc
+// @Summary List rooms
+// @Description Lists registered rooms, optionally filtered by building, minimum capacity and equipment tags (a room must have every listed tag).
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param building query string false "Building"
+// @Param min_capacity query int false "Minimum capacity"
+// @Param equipment query string false "Comma-separated equipment tags" example(projector,whiteboard)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.RoomDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/rooms [get]
+func (h *RoomHandler) ListRooms(c *gin.Context) {
+	filter, err := roomFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_capacity"})
+		return
+	}
+
+	rooms, err := h.service.ListRooms(filter)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch rooms", logrus.Fields{})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// FindFreeRooms godoc
+// @Summary Find free rooms
+// @Description Returns rooms with no lesson on the date during every time slot from from_slot to to_slot (slot numbers, inclusive). Lessons block a room when they are linked to it or their classroom text matches it. Results are ordered by capacity.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param date query string true "Date (YYYY-MM-DD)" example(2025-09-01)
+// @Param from_slot query int true "First slot number"
+// @Param to_slot query int false "Last slot number, defaults to from_slot"
+// @Param building query string false "Building"
+// @Param min_capacity query int false "Minimum capacity"
+// @Param equipment query string false "Comma-separated equipment tags" example(projector)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.FreeRoomsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/rooms/free [get]
+func (h *RoomHandler) FindFreeRooms(c *gin.Context) {
+	date, err := services.ParseDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	fromSlot, err := strconv.Atoi(c.Query("from_slot"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from_slot"})
+		return
+	}
+	toSlot, err := strconv.Atoi(c.DefaultQuery("to_slot", c.Query("from_slot")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to_slot"})
+		return
+	}
+	filter, err := roomFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_capacity"})
+		return
+	}
+
+	rooms, err := h.service.FindFreeRooms(date, int32(fromSlot), int32(toSlot), filter)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to search free rooms", logrus.Fields{"date": c.Query("date")})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// GetRoom godoc
+// @Summary Get a room
+// @Description Returns a room with its capacity and equipment.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param id path int true "Room ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.RoomDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/rooms/{id} [get]
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	room, err := h.service.GetRoom(int32(id))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch room", logrus.Fields{"room_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// CreateRoom godoc
+// @Summary Create a room
+// @Description Registers a room. Existing lessons whose classroom text matches the room (e.g. "А-101" for building А, number 101) are linked to it. Rooms are shared, so only site administrators may manage them.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param room body dto.RoomRequest true "Room"
+// @Success 201 {object} dto.RoomDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/rooms [post]
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var req dto.RoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	room, err := h.service.CreateRoom(username.(string), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create room", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+// UpdateRoom godoc
+// @Summary Update a room
+// @Description Updates a room. equipment replaces the whole tag list. A changed name is copied to the room's lessons. Requires the site administrator role.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param id path int true "Room ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param room body dto.UpdateRoomRequest true "Fields to update"
+// @Success 200 {object} dto.RoomDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/rooms/{id} [patch]
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	var req dto.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	room, err := h.service.UpdateRoom(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update room", logrus.Fields{"username": username, "room_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// DeleteRoom godoc
+// @Summary Delete a room
+// @Description Deletes a room. Lessons are unlinked but keep the classroom text. Requires the site administrator role.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param id path int true "Room ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/rooms/{id} [delete]
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteRoom(username.(string), int32(id)); err != nil {
+		respondScheduleError(c, err, "Failed to delete room", logrus.Fields{"username": username, "room_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Room deleted"})
+}
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"net/http"
+	"path/filepath"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const maxRosterFileSize = 5 << 20
+
+type RosterHandler struct {
+	service *services.RosterImportService
+}
+
+func NewRosterHandler(service *services.RosterImportService) *RosterHandler {
+	return &RosterHandler{service}
+}
+
+// rosterErrors maps roster import service errors to HTTP responses
+var rosterErrors = errorResponses{
+	"user not found":                           {http.StatusNotFound, "User not found"},
+	"group not found":                          {http.StatusNotFound, "Group not found"},
+	"unsupported roster format":                {http.StatusBadRequest, "Unsupported roster format, expected csv or xlsx"},
+	"invalid roster file":                      {http.StatusBadRequest, "Invalid roster file"},
+	"roster is too large":                      {http.StatusBadRequest, "Roster is too large"},
+	"access denied: group owner role required": {http.StatusForbidden, "Access denied: group owner role required"},
+}
+
+// ImportRoster godoc
+// @Summary Import a student roster
+// @Description Imports a CSV or XLSX student list from the dean's office (full name, email and optionally student ID columns, Russian or English headers) into the group. Rows are matched to existing accounts by student ID, then by email. Only site administrators can create accounts: for them other rows get a new account whose temporary password is returned in the report, for the group owner those rows are rejected. Created students join the group right away. A site administrator's import records the group's academic group as verified on created and matched accounts; nothing else about existing accounts is modified. A matched student joins only if they have a pending application to the group, which is approved; other matched students are reported so the owner can invite them. Rows that can't be read, repeat an earlier row, conflict with existing accounts or belong to banned users are rejected. With dry_run=true the report is returned without saving anything. Requires the group owner or a site administrator.
+// @Tags groups
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param file formData file true "Roster file (.csv or .xlsx)"
+// @Param format formData string false "csv or xlsx, detected from the file name when omitted"
+// @Param dry_run formData bool false "Only preview the changes"
+// @Success 200 {object} dto.RosterImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/roster/import [post]
+func (h *RosterHandler) ImportRoster(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Roster file is required"})
+		return
+	}
+	if fileHeader.Size > maxRosterFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Roster file is too large"})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Roster file can't be read"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.ImportRoster(username.(string), int32(groupID), format, file, dryRun)
+	if err != nil {
+		respondError(c, rosterErrors, err, "Failed to import roster", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,526 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ScheduleHandler struct {
+	service *services.ScheduleService
+}
+
+func NewScheduleHandler(service *services.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service}
+}
+
+// scheduleErrors maps service errors to HTTP responses
+var scheduleErrors = errorResponses{
+	"user not found":                                        {http.StatusNotFound, "User not found"},
+	"group not found":                                       {http.StatusNotFound, "Group not found"},
+	"subject not found":                                     {http.StatusNotFound, "Subject not found"},
+	"time slot not found":                                   {http.StatusNotFound, "Time slot not found"},
+	"schedule entry not found":                              {http.StatusNotFound, "Schedule entry not found"},
+	"invalid date":                                          {http.StatusBadRequest, "Invalid date, expected YYYY-MM-DD"},
+	"invalid date range":                                    {http.StatusBadRequest, "Invalid date range"},
+	"invalid time range":                                    {http.StatusBadRequest, "Invalid time range, expected HH:MM with end after start"},
+	"time slot is in use":                                   {http.StatusConflict, "Time slot is in use"},
+	"teacher not found":                                     {http.StatusNotFound, "Teacher not found"},
+	"initials are required":                                 {http.StatusBadRequest, "Teacher initials are required"},
+	"room not found":                                        {http.StatusNotFound, "Room not found"},
+	"room already exists":                                   {http.StatusConflict, "Room already exists"},
+	"room number is required":                               {http.StatusBadRequest, "Room number is required"},
+	"invalid time slot range":                               {http.StatusBadRequest, "Invalid time slot range"},
+	"schedule rule not found":                               {http.StatusNotFound, "Schedule rule not found"},
+	"exception not found":                                   {http.StatusNotFound, "Exception not found"},
+	"exception already exists":                              {http.StatusConflict, "Lesson is already cancelled on this date"},
+	"invalid weekday":                                       {http.StatusBadRequest, "Invalid weekday, expected 1 (Monday) to 7 (Sunday)"},
+	"invalid week parity":                                   {http.StatusBadRequest, "Invalid week parity, expected all, odd or even"},
+	"invalid semester range":                                {http.StatusBadRequest, "Semester end must not be before its start"},
+	"semester is too long":                                  {http.StatusBadRequest, "Semester can't be longer than a year"},
+	"rule has no lesson on this date":                       {http.StatusBadRequest, "Rule has no lesson on this date"},
+	"unsupported timetable format":                          {http.StatusBadRequest, "Unsupported timetable format, expected xlsx or ics"},
+	"invalid timetable file":                                {http.StatusBadRequest, "Timetable file can't be parsed"},
+	"timetable is too large":                                {http.StatusBadRequest, "Timetable has too many lessons"},
+	"semester dates are required for XLSX imports":          {http.StatusBadRequest, "semester_start and semester_end are required for XLSX imports"},
+	"subject does not belong to the group's academic group": {http.StatusBadRequest, "Subject does not belong to the group's academic group"},
+	"access denied: group membership required":              {http.StatusForbidden, "Access denied: group membership required"},
+	"access denied: admin or moderator role required":       {http.StatusForbidden, "Access denied: admin or moderator role required"},
+	"access denied: administrator role required":            {http.StatusForbidden, "Access denied: administrator role required"},
+}
+
+func respondScheduleError(c *gin.Context, err error, logMessage string, fields logrus.Fields) {
+	var conflict *services.ScheduleConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Schedule conflict", "conflicts": conflict.Conflicts})
+		return
+	}
+	respondError(c, scheduleErrors, err, logMessage, fields)
+}
+
+// GetTimeSlots godoc
+// @Summary List time slots
+// @Description Returns all lesson time slots ordered by slot number.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.TimeSlotDTO
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/time-slots [get]
+func (h *ScheduleHandler) GetTimeSlots(c *gin.Context) {
+	slots, err := h.service.GetTimeSlots()
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch time slots", logrus.Fields{})
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// CreateTimeSlot godoc
+// @Summary Create a time slot
+// @Description Creates a lesson time slot. Time slots are shared, so only site administrators may edit them.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param slot body dto.TimeSlotRequest true "Time slot"
+// @Success 201 {object} dto.TimeSlotDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/time-slots [post]
+func (h *ScheduleHandler) CreateTimeSlot(c *gin.Context) {
+	var req dto.TimeSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	slot, err := h.service.CreateTimeSlot(username.(string), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create time slot", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusCreated, slot)
+}
+
+// UpdateTimeSlot godoc
+// @Summary Update a time slot
+// @Description Updates a lesson time slot. Requires the site administrator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Time slot ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param slot body dto.TimeSlotRequest true "Time slot"
+// @Success 200 {object} dto.TimeSlotDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/time-slots/{id} [patch]
+func (h *ScheduleHandler) UpdateTimeSlot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time slot ID"})
+		return
+	}
+
+	var req dto.TimeSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	slot, err := h.service.UpdateTimeSlot(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update time slot", logrus.Fields{"username": username, "slot_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, slot)
+}
+
+// DeleteTimeSlot godoc
+// @Summary Delete a time slot
+// @Description Deletes a time slot that is not used by any schedule entry. Requires the site administrator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Time slot ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/time-slots/{id} [delete]
+func (h *ScheduleHandler) DeleteTimeSlot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time slot ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteTimeSlot(username.(string), int32(id)); err != nil {
+		respondScheduleError(c, err, "Failed to delete time slot", logrus.Fields{"username": username, "slot_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Time slot deleted"})
+}
+
+// GetMySchedule godoc
+// @Summary Get my schedule
+// @Description Returns lessons for a day or for the Monday–Sunday week containing the date, across all groups the user belongs to.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today" example(2025-09-01)
+// @Param period query string false "day or week" default(day)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/schedule/my [get]
+func (h *ScheduleHandler) GetMySchedule(c *gin.Context) {
+	date, err := services.ParseDate(c.DefaultQuery("date", time.Now().Format(dto.DateLayout)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	period := c.DefaultQuery("period", "day")
+	if period != "day" && period != "week" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period, expected day or week"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	schedule, err := h.service.GetUserSchedule(username.(string), date, period)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch schedule", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetGroupSchedule godoc
+// @Summary Get a group's schedule
+// @Description Returns lessons of the group between from and to (inclusive, at most 62 days). Defaults to the current week. Accessible to group members.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/schedule [get]
+func (h *ScheduleHandler) GetGroupSchedule(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	from, to := services.WeekBounds(time.Now())
+	if value := c.Query("from"); value != "" {
+		if from, err = services.ParseDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = services.ParseDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	from, _ = services.ParseDate(from.Format(dto.DateLayout))
+	to, _ = services.ParseDate(to.Format(dto.DateLayout))
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	schedule, err := h.service.GetGroupSchedule(username.(string), int32(groupID), from, to)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch group schedule", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateScheduleEntry godoc
+// @Summary Add a lesson to a group's schedule
+// @Description Creates a schedule entry for the group. Requires admin or moderator role in the group. Returns 409 with conflict details when the room, teacher or academic group is already booked in that slot.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param entry body dto.CreateScheduleRequest true "Schedule entry"
+// @Success 201 {object} dto.ScheduleEntryDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/groups/{id}/schedule [post]
+func (h *ScheduleHandler) CreateScheduleEntry(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	entry, err := h.service.CreateEntry(username.(string), int32(groupID), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create schedule entry", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetScheduleEntry godoc
+// @Summary Get a schedule entry
+// @Description Returns a single lesson. Accessible to members of the lesson's group.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule entry ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ScheduleEntryDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/schedule/{id} [get]
+func (h *ScheduleHandler) GetScheduleEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule entry ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	entry, err := h.service.GetEntry(username.(string), int32(id))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch schedule entry", logrus.Fields{"username": username, "schedule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateScheduleEntry godoc
+// @Summary Update a schedule entry
+// @Description Updates a lesson. Requires admin or moderator role in the lesson's group.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule entry ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param entry body dto.UpdateScheduleRequest true "Fields to update"
+// @Success 200 {object} dto.ScheduleEntryDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/schedule/{id} [patch]
+func (h *ScheduleHandler) UpdateScheduleEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule entry ID"})
+		return
+	}
+
+	var req dto.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	entry, err := h.service.UpdateEntry(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update schedule entry", logrus.Fields{"username": username, "schedule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteScheduleEntry godoc
+// @Summary Delete a schedule entry
+// @Description Deletes a lesson. Requires admin or moderator role in the lesson's group.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule entry ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/schedule/{id} [delete]
+func (h *ScheduleHandler) DeleteScheduleEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule entry ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteEntry(username.(string), int32(id)); err != nil {
+		respondScheduleError(c, err, "Failed to delete schedule entry", logrus.Fields{"username": username, "schedule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule entry deleted"})
+}
+
+// GetScheduleConflicts godoc
+// @Summary List schedule conflicts
+// @Description Lists lessons that double-book a room, a teacher or an academic group between from and to (inclusive, at most 62 days; defaults to the current week). Only conflicts involving the user's groups are returned, or those of one group when group_id is set. The same subject with the same teacher in the same room is treated as one joint lesson.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param group_id query int false "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.ScheduleConflictDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/schedule/conflicts [get]
+func (h *ScheduleHandler) GetScheduleConflicts(c *gin.Context) {
+	var err error
+	from, to := services.WeekBounds(time.Now())
+	if value := c.Query("from"); value != "" {
+		if from, err = services.ParseDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = services.ParseDate(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	from, _ = services.ParseDate(from.Format(dto.DateLayout))
+	to, _ = services.ParseDate(to.Format(dto.DateLayout))
+
+	var groupID *int32
+	if value := c.Query("group_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			return
+		}
+		groupIDValue := int32(id)
+		groupID = &groupIDValue
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conflicts, err := h.service.GetConflicts(username.(string), from, to, groupID)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch schedule conflicts", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusOK, conflicts)
+}
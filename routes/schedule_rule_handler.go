@@ -0,0 +1,270 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ScheduleRuleHandler struct {
+	service *services.ScheduleRuleService
+}
+
+func NewScheduleRuleHandler(service *services.ScheduleRuleService) *ScheduleRuleHandler {
+	return &ScheduleRuleHandler{service}
+}
+
+// GetGroupRules godoc
+// @Summary List recurring lessons of a group
+// @Description Returns the group's timetable rules with their exceptions and the dates they currently produce lessons on.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.ScheduleRuleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/schedule-rules [get]
+func (h *ScheduleRuleHandler) GetGroupRules(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.service.GetGroupRules(username.(string), int32(groupID))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch schedule rules", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule godoc
+// @Summary Create a recurring lesson
+// @Description Creates a weekly lesson for the semester and generates schedule entries for it. week_parity is all, odd (numerator) or even (denominator); weeks are counted from the start of the current semester (SEMESTER_STARTS), or from the rule's semester_start when none is configured for that date. Requires admin or moderator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param rule body dto.ScheduleRuleRequest true "Rule"
+// @Success 201 {object} dto.ScheduleRuleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/groups/{id}/schedule-rules [post]
+func (h *ScheduleRuleHandler) CreateRule(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rule, err := h.service.CreateRule(username.(string), int32(groupID), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create schedule rule", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule godoc
+// @Summary Update a recurring lesson
+// @Description Updates a rule and regenerates its schedule entries. Manual edits to generated entries are overwritten. Requires admin or moderator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param rule body dto.UpdateScheduleRuleRequest true "Fields to update"
+// @Success 200 {object} dto.ScheduleRuleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/schedule-rules/{id} [patch]
+func (h *ScheduleRuleHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	var req dto.UpdateScheduleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update schedule rule", logrus.Fields{"username": username, "rule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete a recurring lesson
+// @Description Deletes a rule together with all schedule entries generated from it. Requires admin or moderator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/schedule-rules/{id} [delete]
+func (h *ScheduleRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteRule(username.(string), int32(id)); err != nil {
+		respondScheduleError(c, err, "Failed to delete schedule rule", logrus.Fields{"username": username, "rule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule rule deleted"})
+}
+
+// AddException godoc
+// @Summary Cancel one occurrence of a recurring lesson
+// @Description Records an exception date (holiday, cancellation) and removes the generated lesson for it. Requires admin or moderator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param exception body dto.RuleExceptionRequest true "Exception"
+// @Success 200 {object} dto.ScheduleRuleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/schedule-rules/{id}/exceptions [post]
+func (h *ScheduleRuleHandler) AddException(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	var req dto.RuleExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rule, err := h.service.AddException(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to add schedule rule exception", logrus.Fields{"username": username, "rule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// RemoveException godoc
+// @Summary Restore a cancelled occurrence
+// @Description Removes an exception date and regenerates the lesson for it. Requires admin or moderator role.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param date path string true "Exception date (YYYY-MM-DD)"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.ScheduleRuleDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/schedule-rules/{id}/exceptions/{date} [delete]
+func (h *ScheduleRuleHandler) RemoveException(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rule, err := h.service.RemoveException(username.(string), int32(id), c.Param("date"))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to remove schedule rule exception", logrus.Fields{"username": username, "rule_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
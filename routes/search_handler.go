@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{service}
+}
+
+// Search godoc
+// @Summary Search tasks, materials, subjects and groups
+// @Description Full-text search (PostgreSQL, Russian and English stemming) over tasks of the user's groups, active materials and subjects of their academic groups, and their groups. Supports web search syntax: "quoted phrases", -excluded words and OR. Results are ranked by relevance; snippets are HTML-escaped with matches wrapped in <mark>. facets counts the hits of every type regardless of the types filter.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param types query string false "Comma-separated result types: task, material, subject, group"
+// @Param page query int false "Page number" default(1) example(1)
+// @Param page_size query int false "Items per page" default(20) example(20)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.SearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+		return
+	}
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	response, err := h.service.Search(username.(string), c.Query("q"), types, page, pageSize)
+	if err != nil {
+		switch err.Error() {
+		case "search query is too short":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query must be at least 2 characters"})
+		case "search query is too long":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query must be at most 200 characters"})
+		case "invalid search type":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid types, expected task, material, subject or group"})
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"username": username,
+			}).Error("Search failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -194,7 +194,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 // VerifyTask godoc
 // @Summary Verify a task
-// @Description Verify or deny a task's legitimacy
+// @Description Verify or deny a task's legitimacy as an admin or moderator. The decision sets the task verified or rejected and overrides any vote, also in groups that verify by vote.
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -265,7 +265,13 @@ func (h *TaskHandler) VerifyTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.taskService.VerifyTask(int32(taskID), req.VerificationStatus); err != nil {
+	user, err := h.groupService.GetUserByUsername(username.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.taskService.VerifyTask(int32(taskID), req.VerificationStatus, user.UserID); err != nil {
 		utils.Logger.WithFields(logrus.Fields{
 			"error":       err,
 			"task_id":     taskID,
@@ -0,0 +1,230 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type TeacherHandler struct {
+	service *services.TeacherService
+}
+
+func NewTeacherHandler(service *services.TeacherService) *TeacherHandler {
+	return &TeacherHandler{service}
+}
+
+// ListTeachers godoc
+// @Summary List teachers
+// @Description Lists teachers, optionally searching by name or initials and filtering by department.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param q query string false "Name or initials search"
+// @Param department query string false "Department"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.TeacherDTO
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/teachers [get]
+func (h *TeacherHandler) ListTeachers(c *gin.Context) {
+	teachers, err := h.service.ListTeachers(c.Query("q"), c.Query("department"))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch teachers", logrus.Fields{})
+		return
+	}
+	c.JSON(http.StatusOK, teachers)
+}
+
+// GetTeacher godoc
+// @Summary Get a teacher
+// @Description Returns a teacher's contact details and the subjects they lead.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param id path int true "Teacher ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.TeacherDetailDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/teachers/{id} [get]
+func (h *TeacherHandler) GetTeacher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID"})
+		return
+	}
+
+	teacher, err := h.service.GetTeacher(int32(id))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch teacher", logrus.Fields{"teacher_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, teacher)
+}
+
+// CreateTeacher godoc
+// @Summary Create a teacher
+// @Description Creates a teacher. Teachers are shared, so only site administrators may manage them.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param teacher body dto.TeacherRequest true "Teacher"
+// @Success 201 {object} dto.TeacherDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/teachers [post]
+func (h *TeacherHandler) CreateTeacher(c *gin.Context) {
+	var req dto.TeacherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	teacher, err := h.service.CreateTeacher(username.(string), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create teacher", logrus.Fields{"username": username})
+		return
+	}
+	c.JSON(http.StatusCreated, teacher)
+}
+
+// UpdateTeacher godoc
+// @Summary Update a teacher
+// @Description Updates a teacher. Changed initials are copied to the teacher's lessons. Requires the site administrator role.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param id path int true "Teacher ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param teacher body dto.UpdateTeacherRequest true "Fields to update"
+// @Success 200 {object} dto.TeacherDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/teachers/{id} [patch]
+func (h *TeacherHandler) UpdateTeacher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID"})
+		return
+	}
+
+	var req dto.UpdateTeacherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	teacher, err := h.service.UpdateTeacher(username.(string), int32(id), req)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update teacher", logrus.Fields{"username": username, "teacher_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, teacher)
+}
+
+// DeleteTeacher godoc
+// @Summary Delete a teacher
+// @Description Deletes a teacher. Lessons and subjects are unlinked but keep the initials text. Requires the site administrator role.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param id path int true "Teacher ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/teachers/{id} [delete]
+func (h *TeacherHandler) DeleteTeacher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteTeacher(username.(string), int32(id)); err != nil {
+		respondScheduleError(c, err, "Failed to delete teacher", logrus.Fields{"username": username, "teacher_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Teacher deleted"})
+}
+
+// GetTeacherSchedule godoc
+// @Summary Get a teacher's timetable
+// @Description Returns the teacher's lessons in all groups for the Monday–Sunday week containing the date, or for that day only.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Param id path int true "Teacher ID"
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today" example(2025-09-01)
+// @Param period query string false "day or week" default(week)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.TeacherScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/teachers/{id}/schedule [get]
+func (h *TeacherHandler) GetTeacherSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID"})
+		return
+	}
+	date, err := services.ParseDate(c.DefaultQuery("date", time.Now().Format(dto.DateLayout)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	period := c.DefaultQuery("period", "week")
+	if period != "day" && period != "week" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period, expected day or week"})
+		return
+	}
+
+	schedule, err := h.service.GetTeacherSchedule(int32(id), date, period)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to fetch teacher schedule", logrus.Fields{"teacher_id": id})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,179 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type TelegramHandler struct {
+	service *services.TelegramService
+}
+
+func NewTelegramHandler(service *services.TelegramService) *TelegramHandler {
+	return &TelegramHandler{service}
+}
+
+// CreateLinkCode godoc
+// @Summary Get a Telegram link code
+// @Description Issues a one-time code (valid for 15 minutes) to send to the bot as /link CODE, linking the chat to the authenticated user.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 201 {object} dto.TelegramLinkCodeDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/telegram/link-code [post]
+func (h *TelegramHandler) CreateLinkCode(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	code, err := h.service.CreateLinkCode(username.(string))
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"username": username,
+		}).Error("Failed to create telegram link code")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create link code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, code)
+}
+
+// GetStatus godoc
+// @Summary Get Telegram link status
+// @Description Returns whether the authenticated user has a linked Telegram chat and their notification settings.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.TelegramStatusDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/telegram [get]
+func (h *TelegramHandler) GetStatus(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := h.service.GetStatus(username.(string))
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"username": username,
+		}).Error("Failed to fetch telegram status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch telegram status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// UpdateSettings godoc
+// @Summary Update Telegram notification settings
+// @Description Enables or disables the daily digest and deadline reminders for the linked chat.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Param settings body dto.TelegramSettingsRequest true "Notification settings"
+// @Success 200 {object} dto.TelegramStatusDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/telegram [patch]
+func (h *TelegramHandler) UpdateSettings(c *gin.Context) {
+	var req dto.TelegramSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := h.service.UpdateSettings(username.(string), req)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "telegram is not linked":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Telegram is not linked"})
+		default:
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"username": username,
+			}).Error("Failed to update telegram settings")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Unlink godoc
+// @Summary Unlink Telegram
+// @Description Removes the Telegram chat link of the authenticated user.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/telegram [delete]
+func (h *TelegramHandler) Unlink(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Unlink(username.(string)); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"username": username,
+		}).Error("Failed to unlink telegram")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink telegram"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Telegram unlinked"})
+}
@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"net/http"
+	"path/filepath"
+	"space/services"
+	"space/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const maxTimetableFileSize = 10 << 20
+
+type TimetableImportHandler struct {
+	service *services.TimetableImportService
+}
+
+func NewTimetableImportHandler(service *services.TimetableImportService) *TimetableImportHandler {
+	return &TimetableImportHandler{service}
+}
+
+// optionalDate parses a YYYY-MM-DD form value, returning nil when it is empty
+func optionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := services.ParseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// ImportTimetable godoc
+// @Summary Import a timetable
+// @Description Imports the university XLSX timetable (weekly layout with Day, Pair, Time, Subject, Teacher, Room and Week columns) or an iCalendar file into the group's schedule. Missing subjects of the group's academic group are created. Missing time slots are created only for site administrators; for everyone else lessons in unknown pairs are reported as conflicts. XLSX imports need semester_start and semester_end; for iCalendar they optionally limit the imported range. With dry_run=true the report is returned without saving anything. Requires admin or moderator role.
+// @Tags schedule
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param file formData file true "Timetable file (.xlsx or .ics)"
+// @Param format formData string false "xlsx or ics, detected from the file name when omitted"
+// @Param semester_start formData string false "Semester start (YYYY-MM-DD)"
+// @Param semester_end formData string false "Semester end (YYYY-MM-DD)"
+// @Param dry_run formData bool false "Only preview the changes"
+// @Success 200 {object} dto.TimetableImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/schedule/import [post]
+func (h *TimetableImportHandler) ImportTimetable(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Timetable file is required"})
+		return
+	}
+	if fileHeader.Size > maxTimetableFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Timetable file is too large"})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	from, err := optionalDate(c.PostForm("semester_start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid semester_start, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := optionalDate(c.PostForm("semester_end"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid semester_end, expected YYYY-MM-DD"})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Timetable file can't be read"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.ImportTimetable(username.(string), int32(groupID), format, file, from, to, dryRun)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to import timetable", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,276 @@
+package routes
+
+import (
+	"net/http"
+	"space/models/dto"
+	"space/services"
+	"space/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type TaskVerificationHandler struct {
+	service *services.TaskVerificationService
+}
+
+func NewTaskVerificationHandler(service *services.TaskVerificationService) *TaskVerificationHandler {
+	return &TaskVerificationHandler{service}
+}
+
+// verificationErrors maps task verification service errors to HTTP responses
+var verificationErrors = errorResponses{
+	"user not found":  {http.StatusNotFound, "User not found"},
+	"task not found":  {http.StatusNotFound, "Task not found"},
+	"group not found": {http.StatusNotFound, "Group not found"},
+	"vote not found":  {http.StatusNotFound, "You haven't voted on this task"},
+	"access denied: group membership required":        {http.StatusForbidden, "Access denied: group membership required"},
+	"access denied: group owner role required":        {http.StatusForbidden, "Access denied: group owner role required"},
+	"only moderators can vote on tasks in this group": {http.StatusForbidden, "Only moderators can vote on tasks in this group"},
+	"verification voting is disabled for this group":  {http.StatusConflict, "Verification voting is disabled for this group"},
+	"cannot vote on your own task":                    {http.StatusForbidden, "You cannot vote on your own task"},
+}
+
+// GetTaskVerification godoc
+// @Summary Get a task's verification
+// @Description Returns the task's verification status, the group's policy, the legit/fake tally, every vote and the history of status changes. Requires group membership.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.TaskVerificationDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/tasks/{id}/verification [get]
+func (h *TaskVerificationHandler) GetTaskVerification(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.GetVerification(username.(string), int32(taskID))
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to fetch task verification", logrus.Fields{"username": username, "task_id": taskID})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// VoteOnTask godoc
+// @Summary Vote on a task
+// @Description Votes legit or fake on the task with an optional reason; voting again replaces the earlier vote. Once the group's quorum is reached the task becomes verified when the share of legit votes meets the threshold and rejected otherwise. A moderator's direct decision isn't changed by votes. Only available in groups whose verification mode is vote; authors can't vote on their own tasks.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.VoteRequest true "Vote"
+// @Success 200 {object} dto.TaskVerificationDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/tasks/{id}/votes [post]
+func (h *TaskVerificationHandler) VoteOnTask(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req dto.VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.Vote(username.(string), int32(taskID), req)
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to record vote", logrus.Fields{"username": username, "task_id": taskID})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RetractVote godoc
+// @Summary Retract a vote
+// @Description Deletes the user's vote on the task. A task decided by votes goes back to pending if the remaining votes fall below the quorum.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.TaskVerificationDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/tasks/{id}/votes [delete]
+func (h *TaskVerificationHandler) RetractVote(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := h.service.RetractVote(username.(string), int32(taskID))
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to retract vote", logrus.Fields{"username": username, "task_id": taskID})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetVerificationPolicy godoc
+// @Summary Get the group's verification policy
+// @Description Returns how the group's tasks are verified: by a single moderator or by a vote with quorum and threshold. Requires group membership.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {object} dto.VerificationPolicyDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/verification-policy [get]
+func (h *TaskVerificationHandler) GetVerificationPolicy(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	policy, err := h.service.GetPolicy(username.(string), int32(groupID))
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to fetch verification policy", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateVerificationPolicy godoc
+// @Summary Update the group's verification policy
+// @Description Sets the verification mode (moderator or vote), who may vote (moderators or members), the quorum, the percentage of legit votes needed to verify and the reputation from which new tasks are verified right away (0 turns auto-verification off). Omitted fields keep their value. Tasks already decided keep their status. Requires the group owner.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param Authorization header string true "Bearer JWT"
+// @Param body body dto.UpdateVerificationPolicyRequest true "Policy changes"
+// @Success 200 {object} dto.VerificationPolicyDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/verification-policy [patch]
+func (h *TaskVerificationHandler) UpdateVerificationPolicy(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dto.UpdateVerificationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(username.(string), int32(groupID), req)
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to update verification policy", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetLeaderboard godoc
+// @Summary Get the group's reputation leaderboard
+// @Description Ranks the group's members by reputation: 10 points per verified task they posted minus 15 per rejected one, counting only decisions made by a moderator or by vote. Tasks verified automatically for their author's reputation count as pending. Requires group membership.
+// @Tags task-verification
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param limit query int false "Number of entries (max 100)" default(20)
+// @Param Authorization header string true "Bearer JWT"
+// @Success 200 {array} dto.LeaderboardEntryDTO
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/groups/{id}/leaderboard [get]
+func (h *TaskVerificationHandler) GetLeaderboard(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		utils.Logger.Error("Unauthorized: username not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	entries, err := h.service.GetLeaderboard(username.(string), int32(groupID), limit)
+	if err != nil {
+		respondError(c, verificationErrors, err, "Failed to fetch leaderboard", logrus.Fields{"username": username, "group_id": groupID})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
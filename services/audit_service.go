@@ -0,0 +1,157 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuditService struct {
+	repo      *repositories.AuditRepository
+	groupRepo *repositories.GroupRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewAuditService(repo *repositories.AuditRepository, groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository) *AuditService {
+	return &AuditService{repo: repo, groupRepo: groupRepo, userRepo: userRepo}
+}
+
+func snapshot(value interface{}) *string {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		utils.Logger.WithField("error", err).Error("Failed to encode audit snapshot")
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
+
+// Record stores an audit event for an action that has already been applied. A failure is
+// logged rather than returned so it doesn't turn a completed action into an error response.
+func (s *AuditService) Record(groupID, actorID int32, action, targetType string, targetID int32, before, after interface{}) {
+	event := &models.AuditEvent{
+		GroupID:    groupID,
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+	}
+	if err := s.repo.Create(event); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"group_id":  groupID,
+			"actor_id":  actorID,
+			"action":    action,
+			"target_id": targetID,
+		}).Error("Failed to record audit event")
+	}
+}
+
+// authorize lets only the group owner read the audit trail. Events outlive their group, so
+// the owner who deleted a group can still read its history.
+func (s *AuditService) authorize(username string, groupID int32) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		deletedBy, deleted, lookupErr := s.repo.DeletedBy(groupID)
+		if lookupErr != nil {
+			return lookupErr
+		}
+		if !deleted {
+			return errors.New("group not found")
+		}
+		if deletedBy != user.UserID {
+			return errors.New("access denied: group owner role required")
+		}
+		return nil
+	}
+	if group.AdminID != user.UserID {
+		return errors.New("access denied: group owner role required")
+	}
+	return nil
+}
+
+func (s *AuditService) GetEvents(username string, groupID int32, filter repositories.AuditFilter, page, pageSize int) (dto.AuditEventsResponse, error) {
+	if err := s.authorize(username, groupID); err != nil {
+		return dto.AuditEventsResponse{}, err
+	}
+	events, total, err := s.repo.Find(groupID, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return dto.AuditEventsResponse{}, err
+	}
+	eventDTOs := make([]dto.AuditEventDTO, len(events))
+	for i := range events {
+		eventDTOs[i] = dto.ToAuditEventDTO(&events[i])
+	}
+	return dto.AuditEventsResponse{
+		Events: eventDTOs,
+		Pagination: dto.PaginationMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+			Pages:    (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	}, nil
+}
+
+// ExportCSV renders every event matching the filter as CSV, newest first
+func (s *AuditService) ExportCSV(username string, groupID int32, filter repositories.AuditFilter) ([]byte, error) {
+	if err := s.authorize(username, groupID); err != nil {
+		return nil, err
+	}
+	events, _, err := s.repo.Find(groupID, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"id", "created_at", "action", "actor_id", "actor_username", "target_type", "target_id", "before", "after"})
+	for _, event := range events {
+		before, after := "", ""
+		if event.Before != nil {
+			before = *event.Before
+		}
+		if event.After != nil {
+			after = *event.After
+		}
+		_ = w.Write([]string{
+			strconv.Itoa(int(event.ID)),
+			event.CreatedAt.UTC().Format(time.RFC3339),
+			event.Action,
+			strconv.Itoa(int(event.ActorID)),
+			event.Actor.Username,
+			event.TargetType,
+			strconv.Itoa(int(event.TargetID)),
+			before,
+			after,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"group_id": groupID,
+		"count":    len(events),
+	}).Info("Exported audit events")
+	return buf.Bytes(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"space/models"
 	"space/repositories"
 	"space/utils"
+	"strings"
 )
 
 type AuthService struct {
@@ -23,6 +24,10 @@ func NewAuthService(userRepo repositories.UserRepository) *AuthService {
 }
 
 func (s *AuthService) RegisterUser(input RegisterInput) error {
+	// Deleted accounts are renamed to deleted-user-<id>
+	if strings.HasPrefix(input.Username, "deleted-user-") {
+		return errors.New("username is reserved")
+	}
 	_, err := s.UserRepo.GetByUsernameOrEmail(input.Username, input.Email)
 	if err == nil {
 		return errors.New("user already exists")
@@ -42,6 +47,26 @@ func (s *AuthService) RegisterUser(input RegisterInput) error {
 	return s.UserRepo.Create(&user)
 }
 
+// ResetPassword sets a new password for the account. It is an operator action
+// (reset-password), so the old password isn't asked for.
+func (s *AuthService) ResetPassword(username, password string) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+	user, err := s.UserRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.DeletedAt != nil {
+		return errors.New("user account is deleted")
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	return s.UserRepo.UpdatePassword(user.UserID, hashed)
+}
+
 // services/auth_service.go
 type LoginInput struct {
 	Username string `json:"username" binding:"required"`
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const calendarTokenBytes = 24
+
+type CalendarService struct {
+	repo          *repositories.CalendarRepository
+	taskRepo      *repositories.TaskRepository
+	groupRepo     *repositories.GroupRepository
+	groupUserRepo *repositories.GroupUserRepository
+	userRepo      repositories.UserRepository
+}
+
+func NewCalendarService(repo *repositories.CalendarRepository, taskRepo *repositories.TaskRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, userRepo repositories.UserRepository) *CalendarService {
+	return &CalendarService{
+		repo:          repo,
+		taskRepo:      taskRepo,
+		groupRepo:     groupRepo,
+		groupUserRepo: groupUserRepo,
+		userRepo:      userRepo,
+	}
+}
+
+func feedURL(baseURL, token string) string {
+	return strings.TrimRight(baseURL, "/") + "/calendar/" + token + ".ics"
+}
+
+// CreateFeed issues a new secret feed URL for all of the user's groups (groupID == nil) or a single group
+func (s *CalendarService) CreateFeed(username string, groupID *int32, baseURL string) (*dto.CalendarFeedDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	feed := &models.CalendarFeed{UserID: user.UserID, GroupID: groupID}
+	if groupID != nil {
+		group, err := s.groupRepo.GetByID(*groupID)
+		if err != nil {
+			return nil, errors.New("group not found")
+		}
+		isMember, err := s.groupUserRepo.IsMember(*groupID, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, errors.New("access denied: group membership required")
+		}
+		feed.Group = *group
+	}
+
+	token, err := utils.RandomToken(calendarTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	feed.Token = token
+	if err := s.repo.Create(feed); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"user_id":  user.UserID,
+			"group_id": groupID,
+		}).Error("Failed to create calendar feed")
+		return nil, err
+	}
+
+	result := dto.ToCalendarFeedDTO(feed, feedURL(baseURL, feed.Token))
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id":  user.UserID,
+		"group_id": groupID,
+		"feed_id":  feed.ID,
+	}).Info("Calendar feed created")
+	return &result, nil
+}
+
+func (s *CalendarService) ListFeeds(username, baseURL string) ([]dto.CalendarFeedDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	feeds, err := s.repo.FindByUserID(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	feedDTOs := make([]dto.CalendarFeedDTO, len(feeds))
+	for i := range feeds {
+		feedDTOs[i] = dto.ToCalendarFeedDTO(&feeds[i], feedURL(baseURL, feeds[i].Token))
+	}
+	return feedDTOs, nil
+}
+
+// RevokeFeed deletes the feed so its URL stops working
+func (s *CalendarService) RevokeFeed(username string, feedID int32) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.repo.Delete(feedID, user.UserID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("feed not found")
+		}
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id": user.UserID,
+		"feed_id": feedID,
+	}).Info("Calendar feed revoked")
+	return nil
+}
+
+// RenderFeed builds the .ics body for a feed token. kind is "event" (VEVENT) or "todo" (VTODO).
+func (s *CalendarService) RenderFeed(token, kind string) (string, error) {
+	feed, err := s.repo.GetByToken(token)
+	if err != nil {
+		return "", errors.New("feed not found")
+	}
+
+	var groupIDs []int32
+	calendarName := "4edu: all groups"
+	if feed.GroupID != nil {
+		// Membership is re-checked so that leaving a group stops the feed
+		isMember, err := s.groupUserRepo.IsMember(*feed.GroupID, feed.UserID)
+		if err != nil {
+			return "", err
+		}
+		if !isMember {
+			return "", errors.New("feed not found")
+		}
+		group, err := s.groupRepo.GetByID(*feed.GroupID)
+		if err != nil {
+			return "", errors.New("feed not found")
+		}
+		groupIDs = []int32{group.ID}
+		calendarName = "4edu: " + group.Name
+	} else {
+		groupUsers, err := s.groupUserRepo.FindByUserID(feed.UserID)
+		if err != nil {
+			return "", err
+		}
+		for _, gu := range groupUsers {
+			groupIDs = append(groupIDs, gu.GroupID)
+		}
+	}
+
+	var tasks []*models.Task
+	if len(groupIDs) > 0 {
+		tasks, err = s.taskRepo.FindWithDeadlineByGroupIDs(groupIDs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w := utils.NewICalWriter(calendarName)
+	for _, task := range tasks {
+		writeTaskComponent(w, task, kind)
+	}
+
+	if err := s.repo.TouchLastUsed(feed.ID); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"feed_id": feed.ID,
+		}).Warn("Failed to update calendar feed usage")
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"feed_id": feed.ID,
+		"user_id": feed.UserID,
+		"count":   len(tasks),
+	}).Debug("Calendar feed rendered")
+	return w.String(), nil
+}
+
+func writeTaskComponent(w *utils.ICalWriter, task *models.Task, kind string) {
+	component := "VEVENT"
+	if kind == "todo" {
+		component = "VTODO"
+	}
+
+	summary := task.Title
+	if task.SubjectID != nil && task.Subject.Name != "" {
+		summary = "[" + task.Subject.Name + "] " + task.Title
+	}
+
+	w.Line("BEGIN:" + component)
+	w.Prop("UID", fmt.Sprintf("task-%d@4edu.su", task.ID))
+	w.Time("DTSTAMP", task.UpdatedAt)
+	w.Time("CREATED", task.CreatedAt)
+	w.Time("LAST-MODIFIED", task.UpdatedAt)
+	// SEQUENCE must grow on every change; seconds since creation do
+	w.Line(fmt.Sprintf("SEQUENCE:%d", int64(task.UpdatedAt.Sub(task.CreatedAt).Seconds())))
+	if component == "VTODO" {
+		w.Time("DUE", *task.Deadline)
+		w.Line("STATUS:NEEDS-ACTION")
+	} else {
+		w.Time("DTSTART", *task.Deadline)
+		w.Time("DTEND", *task.Deadline)
+		if task.IsVerified {
+			w.Line("STATUS:CONFIRMED")
+		} else {
+			w.Line("STATUS:TENTATIVE")
+		}
+	}
+	w.Prop("SUMMARY", summary)
+	if task.Description != "" {
+		w.Prop("DESCRIPTION", task.Description)
+	}
+	w.Prop("CATEGORIES", task.Group.Name)
+	w.Line("END:" + component)
+}
@@ -2,9 +2,13 @@ package services
 
 import (
 	"errors"
+	"os"
 	"space/models"
+	"space/models/dto"
 	"space/repositories"
 	"space/utils"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -30,13 +34,34 @@ type GroupApplicationService struct {
 	groupModerRepo *repositories.GroupModerRepository
 	userRepo       repositories.UserRepository
 	groupUserRepo  *repositories.GroupUserRepository
+	membershipRepo *repositories.GroupMembershipRepository
+	audit          *AuditService
+	expiry         time.Duration
+}
+
+// DefaultApplicationExpiryDays is how long an application stays pending when
+// APPLICATION_EXPIRY_DAYS isn't set
+const DefaultApplicationExpiryDays = 30
+
+// ApplicationExpiryFromEnv reads APPLICATION_EXPIRY_DAYS (see docker-compose.yml)
+func ApplicationExpiryFromEnv() time.Duration {
+	days := DefaultApplicationExpiryDays
+	if value := os.Getenv("APPLICATION_EXPIRY_DAYS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			days = n
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
 }
 
 func NewGroupApplicationService(repo *repositories.GroupApplicationRepository,
 	groupRepo *repositories.GroupRepository,
 	groupModerRepo *repositories.GroupModerRepository,
 	userRepo repositories.UserRepository,
-	groupUserRepo *repositories.GroupUserRepository) *GroupApplicationService {
+	groupUserRepo *repositories.GroupUserRepository,
+	membershipRepo *repositories.GroupMembershipRepository,
+	audit *AuditService,
+	expiry time.Duration) *GroupApplicationService {
 
 	return &GroupApplicationService{
 		repo:           repo,
@@ -44,14 +69,72 @@ func NewGroupApplicationService(repo *repositories.GroupApplicationRepository,
 		groupModerRepo: groupModerRepo,
 		userRepo:       userRepo,
 		groupUserRepo:  groupUserRepo,
+		membershipRepo: membershipRepo,
+		audit:          audit,
+		expiry:         expiry,
+	}
+}
+
+// ExpireStale closes pending applications older than the expiry window
+func (s *GroupApplicationService) ExpireStale() {
+	count, err := s.repo.ExpireStale(time.Now().Add(-s.expiry))
+	if err == nil && count > 0 {
+		utils.Logger.WithField("count", count).Info("Expired stale group applications")
+	}
+}
+
+// stale reports whether a pending application is past the expiry window. RunExpiry closes
+// those within the hour; until then they are treated as expired.
+func (s *GroupApplicationService) stale(app *models.GroupApplication) bool {
+	return app.Status == models.ApplicationPending && time.Since(app.CreatedAt) > s.expiry
+}
+
+// RunExpiry expires stale applications every hour until stop is closed
+func (s *GroupApplicationService) RunExpiry(stop <-chan struct{}) {
+	s.ExpireStale()
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.ExpireStale()
+		}
+	}
+}
+
+// inAcademicGroup reports whether the user's verified academic group is the group's one
+func inAcademicGroup(user *models.User, group *models.Group) bool {
+	return user.AcademicGroupID != nil && *user.AcademicGroupID == group.AcademicGroupID
+}
+
+// checkJoinPolicy rejects banned users and those the group's settings don't let join without
+// an invite
+func (s *GroupApplicationService) checkJoinPolicy(group *models.Group, user *models.User) error {
+	banned, err := s.membershipRepo.IsBanned(group.ID, user.UserID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return errors.New("user is banned from this group")
+	}
+	if group.Visibility == models.GroupPrivate || group.JoinPolicy == models.JoinInviteOnly {
+		return errors.New("group can only be joined by invite")
 	}
+	if group.SameAcademicGroupOnly && !inAcademicGroup(user, group) {
+		return errors.New("group is restricted to members of its academic group")
+	}
+	return nil
 }
 
-func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, message string) error {
+// ApplyToGroup submits an application, or joins right away when the group's join policy is
+// open. The returned status is "pending" or "joined".
+func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, message string) (string, error) {
 	username, exists := c.Get("username")
 	if !exists {
 		utils.Logger.Error("User not authenticated")
-		return errors.New("user not authenticated")
+		return "", errors.New("user not authenticated")
 	}
 
 	user, err := s.userRepo.GetByUsername(username.(string))
@@ -60,7 +143,12 @@ func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, me
 			"error":    err,
 			"username": username,
 		}).Error("Failed to find authenticated user")
-		return errors.New("failed to find authenticated user")
+		return "", errors.New("failed to find authenticated user")
+	}
+
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return "", errors.New("group not found")
 	}
 	utils.Logger.WithFields(logrus.Fields{
 		"username": username,
@@ -75,7 +163,7 @@ func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, me
 			"user_id":  user.UserID,
 			"group_id": groupID,
 		}).Error("Failed to check group membership")
-		return err
+		return "", err
 	}
 	if isMember {
 		utils.Logger.WithFields(logrus.Fields{
@@ -83,9 +171,12 @@ func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, me
 			"user_id":  user.UserID,
 			"group_id": groupID,
 		}).Error("Failed to check group membership")
-		return errors.New("user is already a group member")
+		return "", errors.New("user is already a group member")
 	}
 
+	if err := s.repo.ExpireStaleOf(groupID, user.UserID, time.Now().Add(-s.expiry)); err != nil {
+		return "", err
+	}
 	exists, err = s.repo.ExistsPending(groupID, user.UserID)
 	if err != nil {
 		utils.Logger.WithFields(logrus.Fields{
@@ -93,14 +184,39 @@ func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, me
 			"user_id":  user.UserID,
 			"group_id": groupID,
 		}).Error("Failed to check pending application")
-		return err
+		return "", err
 	}
 	if exists {
 		utils.Logger.WithFields(logrus.Fields{
 			"user_id":  user.UserID,
 			"group_id": groupID,
 		}).Warn("Application already submitted and pending")
-		return errors.New("application already submitted and pending")
+		return "", errors.New("application already submitted and pending")
+	}
+
+	if err := s.checkJoinPolicy(group, user); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"user_id":  user.UserID,
+			"group_id": groupID,
+		}).Warn("Group join policy does not allow the application")
+		return "", err
+	}
+
+	if group.JoinPolicy == models.JoinOpen {
+		if err := s.groupUserRepo.Create(&models.GroupUser{
+			GroupID: groupID,
+			UserID:  user.UserID,
+			Role:    "member",
+		}); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"user_id":  user.UserID,
+				"group_id": groupID,
+			}).Error("Failed to join open group")
+			return "", err
+		}
+		return "joined", nil
 	}
 
 	app := &models.GroupApplication{
@@ -120,16 +236,19 @@ func (s *GroupApplicationService) ApplyToGroup(c *gin.Context, groupID int32, me
 			"user_id":  user.UserID,
 			"group_id": groupID,
 		}).Error("Failed to create group application")
-		return err
+		return "", err
+	}
+	if s.AutoApprove(app.ApplicationID) {
+		return "joined", nil
 	}
 
-	return nil
+	return "pending", nil
 
 	// return s.repo.Create(app)
 }
 
 // стоит и дальше добавить логгирование
-func (s *GroupApplicationService) GetPendingApplications(c *gin.Context) ([]models.GroupApplication, error) {
+func (s *GroupApplicationService) GetPendingApplications(c *gin.Context) ([]dto.GroupApplicationDTO, error) {
 	// Fetch groups user moderates or admins
 	username, exists := c.Get("username")
 	if !exists {
@@ -146,13 +265,16 @@ func (s *GroupApplicationService) GetPendingApplications(c *gin.Context) ([]mode
 		return nil, err
 	}
 
-	var allApps []models.GroupApplication
+	cutoff := time.Now().Add(-s.expiry)
+	allApps := []dto.GroupApplicationDTO{}
 	for _, gid := range groupIDs {
-		apps, err := s.repo.GetPendingByGroup(gid.ID)
+		apps, err := s.repo.GetPendingByGroup(gid.ID, cutoff)
 		if err != nil {
 			return nil, err
 		}
-		allApps = append(allApps, apps...)
+		for i := range apps {
+			allApps = append(allApps, dto.ToGroupApplicationDTO(&apps[i], s.expiry))
+		}
 	}
 	return allApps, nil
 }
@@ -163,17 +285,19 @@ func (s *GroupApplicationService) GetPendingApplications(c *gin.Context) ([]mode
 //		}
 //		return s.repo.UpdateStatus(appID, status)
 //	}
-func (s *GroupApplicationService) ReviewApplication(groupID int32, targetUsername, reviewerUsername, status string) error {
+
+// ReviewApplication approves or rejects a pending application by its ID, storing the
+// reviewer and their note on it. Approval adds the applicant to the group.
+func (s *GroupApplicationService) ReviewApplication(appID int32, reviewerUsername, status, note string) (dto.GroupApplicationDTO, error) {
 	utils.Logger.WithFields(logrus.Fields{
-		"reviewer": reviewerUsername,
-		"username": targetUsername,
-		"group_id": groupID,
-		"status":   status,
+		"reviewer":       reviewerUsername,
+		"application_id": appID,
+		"status":         status,
 	}).Debug("Processing application review")
 
-	if status != "approved" && status != "rejected" {
+	if status != models.ApplicationApproved && status != models.ApplicationRejected {
 		utils.Logger.WithField("status", status).Error("Invalid status")
-		return errors.New("invalid status")
+		return dto.GroupApplicationDTO{}, errors.New("invalid status")
 	}
 
 	reviewer, err := s.userRepo.GetByUsername(reviewerUsername)
@@ -182,80 +306,328 @@ func (s *GroupApplicationService) ReviewApplication(groupID int32, targetUsernam
 			"error":    err,
 			"reviewer": reviewerUsername,
 		}).Error("Failed to find reviewer")
-		return errors.New("failed to find reviewer")
+		return dto.GroupApplicationDTO{}, errors.New("user not found")
 	}
 
-	targetUser, err := s.userRepo.GetByUsername(targetUsername)
+	app, err := s.repo.GetByID(appID)
 	if err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":    err,
-			"username": targetUsername,
-		}).Error("Failed to find target user")
-		return errors.New("failed to find target user")
+		return dto.GroupApplicationDTO{}, errors.New("application not found")
 	}
 
-	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, reviewer.UserID)
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(app.GroupID, reviewer.UserID)
 	if err != nil {
 		utils.Logger.WithFields(logrus.Fields{
 			"error":       err,
 			"reviewer_id": reviewer.UserID,
-			"group_id":    groupID,
+			"group_id":    app.GroupID,
 		}).Error("Failed to check authorization")
-		return err
+		return dto.GroupApplicationDTO{}, err
 	}
 	if !isAuthorized {
 		utils.Logger.WithFields(logrus.Fields{
 			"reviewer_id": reviewer.UserID,
-			"group_id":    groupID,
-		}).Error("Unauthorized: not an admin or moderator")
-		return errors.New("unauthorized: not an admin or moderator")
+			"group_id":    app.GroupID,
+		}).Warn("Unauthorized: not an admin or moderator")
+		return dto.GroupApplicationDTO{}, errors.New("access denied: admin or moderator role required")
 	}
 
+	if s.stale(app) {
+		return dto.GroupApplicationDTO{}, repositories.ErrApplicationNotPending
+	}
+	previous := app.Status
+	if err := s.repo.Review(app, status, reviewer.UserID, note); err != nil {
+		if errors.Is(err, repositories.ErrApplicationNotPending) {
+			return dto.GroupApplicationDTO{}, err
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":          err,
+			"application_id": appID,
+		}).Error("Failed to review application")
+		return dto.GroupApplicationDTO{}, err
+	}
+	app.Reviewer = reviewer
+
 	utils.Logger.WithFields(logrus.Fields{
-		"group_id": groupID,
-		"user_id":  targetUser.UserID,
-	}).Debug("Updating application status")
+		"application_id": appID,
+		"group_id":       app.GroupID,
+		"user_id":        app.UserID,
+		"status":         status,
+	}).Info("Application reviewed")
+	s.audit.Record(app.GroupID, reviewer.UserID, models.AuditApplicationReview, "application", app.ApplicationID,
+		map[string]interface{}{"status": previous, "user_id": app.UserID},
+		map[string]interface{}{"status": status, "user_id": app.UserID, "note": note})
+	return dto.ToGroupApplicationDTO(app, s.expiry), nil
+}
 
-	if err := s.repo.UpdateStatusByGroupAndUser(groupID, targetUser.UserID, status); err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":    err,
-			"group_id": groupID,
-			"user_id":  targetUser.UserID,
-		}).Error("Failed to update application status")
-		return err
+// GetMyApplications lists the user's applications with their current status
+func (s *GroupApplicationService) GetMyApplications(username string) ([]dto.GroupApplicationDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
 	}
+	apps, err := s.repo.FindByUser(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.GroupApplicationDTO, len(apps))
+	for i := range apps {
+		result[i] = dto.ToGroupApplicationDTO(&apps[i], s.expiry)
+	}
+	return result, nil
+}
 
-	if status == "approved" {
-		utils.Logger.WithFields(logrus.Fields{
-			"group_id": groupID,
-			"user_id":  targetUser.UserID,
-		}).Debug("Creating group user for approved application")
-		if err := s.groupUserRepo.Create(&models.GroupUser{
-			GroupID: groupID,
-			UserID:  targetUser.UserID,
-		}); err != nil {
-			utils.Logger.WithFields(logrus.Fields{
-				"error":    err,
-				"group_id": groupID,
-				"user_id":  targetUser.UserID,
-			}).Error("Failed to create group user")
-			return err
+// WithdrawApplication lets the applicant take back one of their pending applications
+func (s *GroupApplicationService) WithdrawApplication(username string, appID int32) (dto.GroupApplicationDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.GroupApplicationDTO{}, errors.New("user not found")
+	}
+	app, err := s.repo.GetByID(appID)
+	if err != nil || app.UserID != user.UserID {
+		return dto.GroupApplicationDTO{}, errors.New("application not found")
+	}
+	if s.stale(app) {
+		return dto.GroupApplicationDTO{}, repositories.ErrApplicationNotPending
+	}
+	if err := s.repo.Withdraw(appID); err != nil {
+		return dto.GroupApplicationDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"application_id": appID,
+		"user_id":        user.UserID,
+		"group_id":       app.GroupID,
+	}).Info("Application withdrawn")
+
+	app, err = s.repo.GetByID(appID)
+	if err != nil {
+		return dto.GroupApplicationDTO{}, err
+	}
+	return dto.ToGroupApplicationDTO(app, s.expiry), nil
+}
+
+// BulkReview applies one decision to several applications in a single transaction and
+// reports the outcome of each. Applications the reviewer can't moderate, that don't exist or
+// are no longer pending are skipped and reported without affecting the rest.
+func (s *GroupApplicationService) BulkReview(reviewerUsername string, req dto.BulkReviewRequest) (dto.BulkReviewResponse, error) {
+	reviewer, err := s.userRepo.GetByUsername(reviewerUsername)
+	if err != nil {
+		return dto.BulkReviewResponse{}, errors.New("user not found")
+	}
+
+	ids := make([]int32, 0, len(req.ApplicationIDs))
+	seen := make(map[int32]bool)
+	for _, id := range req.ApplicationIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
 		}
 	}
+	apps, err := s.repo.FindByIDs(ids)
+	if err != nil {
+		return dto.BulkReviewResponse{}, err
+	}
+	byID := make(map[int32]*models.GroupApplication, len(apps))
+	for i := range apps {
+		byID[apps[i].ApplicationID] = &apps[i]
+	}
 
-	return nil
+	skipped := make(map[int32]string)
+	authorized := make(map[int32]bool)
+	var toReview []*models.GroupApplication
+	previous := make(map[int32]string)
+	for _, id := range ids {
+		app, ok := byID[id]
+		if !ok {
+			skipped[id] = "application not found"
+			continue
+		}
+		allowed, checked := authorized[app.GroupID]
+		if !checked {
+			allowed, err = s.groupRepo.IsAdminOrModerator(app.GroupID, reviewer.UserID)
+			if err != nil {
+				return dto.BulkReviewResponse{}, err
+			}
+			authorized[app.GroupID] = allowed
+		}
+		if !allowed {
+			skipped[id] = "access denied: admin or moderator role required"
+			continue
+		}
+		if s.stale(app) {
+			skipped[id] = repositories.ErrApplicationNotPending.Error()
+			continue
+		}
+		previous[id] = app.Status
+		toReview = append(toReview, app)
+	}
+
+	failed := map[int32]error{}
+	if len(toReview) > 0 {
+		failed, err = s.repo.BulkReview(toReview, req.Status, reviewer.UserID, req.Note)
+		if err != nil {
+			return dto.BulkReviewResponse{}, err
+		}
+	}
+
+	response := dto.BulkReviewResponse{Results: make([]dto.BulkReviewItemDTO, 0, len(ids))}
+	for _, id := range ids {
+		item := dto.BulkReviewItemDTO{ApplicationID: id}
+		app := byID[id]
+		if message, ok := skipped[id]; ok {
+			item.Error = message
+		} else if err, ok := failed[id]; ok {
+			if errors.Is(err, repositories.ErrApplicationNotPending) {
+				item.Error = err.Error()
+			} else {
+				utils.Logger.WithFields(logrus.Fields{
+					"error":          err,
+					"application_id": id,
+				}).Error("Failed to review application")
+				item.Error = "failed to review application"
+			}
+		} else {
+			item.Reviewed = true
+			item.Status = app.Status
+			s.audit.Record(app.GroupID, reviewer.UserID, models.AuditApplicationReview, "application", id,
+				map[string]interface{}{"status": previous[id], "user_id": app.UserID},
+				map[string]interface{}{"status": req.Status, "user_id": app.UserID, "note": req.Note, "bulk": true})
+		}
+		if item.Reviewed {
+			response.Reviewed++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"reviewer": reviewerUsername,
+		"status":   req.Status,
+		"reviewed": response.Reviewed,
+		"failed":   response.Failed,
+	}).Info("Applications reviewed in bulk")
+	return response, nil
+}
+
+// matchRule returns the first of the group's auto-approval rules the application satisfies,
+// or "" if none does
+func (s *GroupApplicationService) matchRule(app *models.GroupApplication, group *models.Group, applicant *models.User) (string, error) {
+	rules, err := s.repo.GetRules(app.GroupID)
+	if err != nil {
+		return "", err
+	}
+	if rules.SameAcademicGroup && inAcademicGroup(applicant, group) {
+		return models.RuleSameAcademicGroup, nil
+	}
+	if rules.InvitedByMember {
+		invited, err := s.repo.InvitedByMember(app)
+		if err != nil {
+			return "", err
+		}
+		if invited {
+			return models.RuleInvitedByMember, nil
+		}
+	}
+	return "", nil
 }
 
-func (s *GroupApplicationService) RejectApplication(groupID, userID int32, actingUserID int32) error {
-	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, actingUserID)
-	if err != nil || !isAuthorized {
-		return errors.New("not authorized to reject applications")
+// AutoApprove approves a freshly submitted application when it matches one of the group's
+// auto-approval rules and reports whether it did. Failures are logged and leave the
+// application pending for a moderator.
+func (s *GroupApplicationService) AutoApprove(appID int32) bool {
+	app, err := s.repo.GetByID(appID)
+	if err != nil || app.Status != models.ApplicationPending {
+		return false
+	}
+	rule, err := s.matchRule(app, &app.Group, &app.User)
+	if err == nil && rule != "" {
+		err = s.repo.AutoApprove(app, rule)
+	}
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":          err,
+			"application_id": appID,
+		}).Error("Failed to apply auto-approval rules")
+		return false
+	}
+	if rule == "" {
+		return false
 	}
+	utils.Logger.WithFields(logrus.Fields{
+		"application_id": appID,
+		"group_id":       app.GroupID,
+		"user_id":        app.UserID,
+		"rule":           rule,
+	}).Info("Application auto-approved")
+	s.audit.Record(app.GroupID, app.UserID, models.AuditApplicationAutoApprove, "application", appID,
+		map[string]interface{}{"status": models.ApplicationPending, "user_id": app.UserID},
+		map[string]interface{}{"status": models.ApplicationApproved, "user_id": app.UserID, "rule": rule})
+	return true
+}
 
-	app, err := s.repo.GetByGroupAndUser(groupID, userID)
-	if err != nil || app.Status != "pending" {
-		return errors.New("no pending application found")
+func (s *GroupApplicationService) GetApprovalRules(username string, groupID int32) (dto.ApprovalRulesDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, errors.New("user not found")
+	}
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return dto.ApprovalRulesDTO{}, errors.New("group not found")
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, user.UserID)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, err
+	}
+	if !isAuthorized {
+		return dto.ApprovalRulesDTO{}, errors.New("access denied: admin or moderator role required")
+	}
+	rules, err := s.repo.GetRules(groupID)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, err
+	}
+	return dto.ToApprovalRulesDTO(rules), nil
+}
+
+// UpdateApprovalRules changes which applications the group approves automatically. Pending
+// applications aren't re-evaluated; the rules apply to new ones.
+func (s *GroupApplicationService) UpdateApprovalRules(username string, groupID int32, req dto.UpdateApprovalRulesRequest) (dto.ApprovalRulesDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, errors.New("group not found")
+	}
+	if group.AdminID != user.UserID {
+		return dto.ApprovalRulesDTO{}, errors.New("access denied: group owner role required")
 	}
 
-	return s.repo.UpdateStatus(app.ApplicationID, "rejected")
+	rules, err := s.repo.GetRules(groupID)
+	if err != nil {
+		return dto.ApprovalRulesDTO{}, err
+	}
+	before := dto.ToApprovalRulesDTO(rules)
+	if req.SameAcademicGroup != nil {
+		rules.SameAcademicGroup = *req.SameAcademicGroup
+	}
+	if req.InvitedByMember != nil {
+		rules.InvitedByMember = *req.InvitedByMember
+	}
+	rules.UpdatedBy = &user.UserID
+	if err := s.repo.SaveRules(rules); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to save approval rules")
+		return dto.ApprovalRulesDTO{}, err
+	}
+
+	after := dto.ToApprovalRulesDTO(rules)
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":            groupID,
+		"same_academic_group": rules.SameAcademicGroup,
+		"invited_by_member":   rules.InvitedByMember,
+	}).Info("Approval rules updated")
+	s.audit.Record(groupID, user.UserID, models.AuditApprovalRules, "group", groupID, before, after)
+	return after, nil
 }
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const inviteCodeLength = 8
+
+type GroupInviteService struct {
+	repo          *repositories.GroupInviteRepository
+	groupRepo     *repositories.GroupRepository
+	groupUserRepo *repositories.GroupUserRepository
+	userRepo      repositories.UserRepository
+	applications  *GroupApplicationService
+}
+
+func NewGroupInviteService(repo *repositories.GroupInviteRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, userRepo repositories.UserRepository, applications *GroupApplicationService) *GroupInviteService {
+	return &GroupInviteService{
+		repo:          repo,
+		groupRepo:     groupRepo,
+		groupUserRepo: groupUserRepo,
+		userRepo:      userRepo,
+		applications:  applications,
+	}
+}
+
+func inviteURL(baseURL, code string) string {
+	return strings.TrimRight(baseURL, "/") + "/api/invites/" + code
+}
+
+func inviteStatus(invite *models.GroupInvite) string {
+	switch repositories.CheckUsable(invite, time.Now()) {
+	case repositories.ErrInviteRevoked:
+		return "revoked"
+	case repositories.ErrInviteExpired:
+		return "expired"
+	case repositories.ErrInviteExhausted:
+		return "exhausted"
+	}
+	return "active"
+}
+
+// NormalizeInviteCode makes codes typed by hand case- and space-insensitive
+func NormalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// authorize returns the user if they are an admin or moderator of the group
+func (s *GroupInviteService) authorize(username string, groupID int32) (*models.User, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return nil, errors.New("group not found")
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthorized {
+		return nil, errors.New("access denied: admin or moderator role required")
+	}
+	return user, nil
+}
+
+func (s *GroupInviteService) CreateInvite(username string, groupID int32, req dto.CreateInviteRequest, baseURL string) (dto.InviteDTO, error) {
+	user, err := s.authorize(username, groupID)
+	if err != nil {
+		return dto.InviteDTO{}, err
+	}
+
+	invite := &models.GroupInvite{
+		GroupID:     groupID,
+		CreatedBy:   user.UserID,
+		AutoApprove: req.AutoApprove,
+		MaxUses:     req.MaxUses,
+	}
+	switch {
+	case req.ExpiresInHours != nil && req.ExpiresAt != nil:
+		return dto.InviteDTO{}, errors.New("set either expires_in_hours or expires_at")
+	case req.ExpiresInHours != nil:
+		expiresAt := time.Now().Add(time.Duration(*req.ExpiresInHours) * time.Hour)
+		invite.ExpiresAt = &expiresAt
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(time.Now()) {
+			return dto.InviteDTO{}, errors.New("expiry must be in the future")
+		}
+		invite.ExpiresAt = req.ExpiresAt
+	}
+
+	// Codes are short enough to be typed, so make sure a fresh one isn't taken
+	for attempt := 0; ; attempt++ {
+		if attempt == 5 {
+			return dto.InviteDTO{}, errors.New("failed to generate invite code")
+		}
+		code, err := utils.RandomCode(inviteCodeLength)
+		if err != nil {
+			return dto.InviteDTO{}, err
+		}
+		if _, err := s.repo.GetByCode(code); err != nil {
+			invite.Code = code
+			break
+		}
+	}
+	if err := s.repo.Create(invite); err != nil {
+		return dto.InviteDTO{}, err
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":     groupID,
+		"invite_id":    invite.ID,
+		"created_by":   user.UserID,
+		"auto_approve": invite.AutoApprove,
+	}).Info("Group invite created")
+
+	created, err := s.repo.GetByID(groupID, invite.ID)
+	if err != nil {
+		return dto.InviteDTO{}, err
+	}
+	return dto.ToInviteDTO(created, inviteURL(baseURL, created.Code), inviteStatus(created)), nil
+}
+
+func (s *GroupInviteService) ListInvites(username string, groupID int32, baseURL string) ([]dto.InviteDTO, error) {
+	if _, err := s.authorize(username, groupID); err != nil {
+		return nil, err
+	}
+	invites, err := s.repo.FindByGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	inviteDTOs := make([]dto.InviteDTO, len(invites))
+	for i := range invites {
+		inviteDTOs[i] = dto.ToInviteDTO(&invites[i], inviteURL(baseURL, invites[i].Code), inviteStatus(&invites[i]))
+	}
+	return inviteDTOs, nil
+}
+
+// RevokeInvite disables the invite; users who already joined through it stay in the group
+func (s *GroupInviteService) RevokeInvite(username string, groupID, inviteID int32) error {
+	user, err := s.authorize(username, groupID)
+	if err != nil {
+		return err
+	}
+	invite, err := s.repo.GetByID(groupID, inviteID)
+	if err != nil {
+		return errors.New("invite not found")
+	}
+	if invite.RevokedAt != nil {
+		return errors.New("invite has been revoked")
+	}
+	if err := s.repo.Revoke(invite, user.UserID); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"invite_id": inviteID,
+		}).Error("Failed to revoke group invite")
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"invite_id":  inviteID,
+		"revoked_by": user.UserID,
+	}).Info("Group invite revoked")
+	return nil
+}
+
+func (s *GroupInviteService) GetInviteUses(username string, groupID, inviteID int32) ([]dto.InviteUseDTO, error) {
+	if _, err := s.authorize(username, groupID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(groupID, inviteID); err != nil {
+		return nil, errors.New("invite not found")
+	}
+	uses, err := s.repo.FindUses(inviteID)
+	if err != nil {
+		return nil, err
+	}
+	useDTOs := make([]dto.InviteUseDTO, len(uses))
+	for i := range uses {
+		useDTOs[i] = dto.ToInviteUseDTO(&uses[i])
+	}
+	return useDTOs, nil
+}
+
+// PreviewInvite shows which group an invite leads to. Anyone with the code may see it.
+func (s *GroupInviteService) PreviewInvite(username, code string) (dto.InvitePreviewDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.InvitePreviewDTO{}, errors.New("user not found")
+	}
+	invite, err := s.repo.GetByCode(NormalizeInviteCode(code))
+	if err != nil {
+		return dto.InvitePreviewDTO{}, errors.New("invite not found")
+	}
+	isMember, err := s.groupUserRepo.IsMember(invite.GroupID, user.UserID)
+	if err != nil {
+		return dto.InvitePreviewDTO{}, err
+	}
+	return dto.InvitePreviewDTO{
+		GroupID:     invite.GroupID,
+		GroupName:   invite.Group.Name,
+		AutoApprove: invite.AutoApprove,
+		ExpiresAt:   invite.ExpiresAt,
+		Status:      inviteStatus(invite),
+		IsMember:    isMember,
+	}, nil
+}
+
+func (s *GroupInviteService) AcceptInvite(username, code, message string) (dto.AcceptInviteResponse, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.AcceptInviteResponse{}, errors.New("user not found")
+	}
+	invite, err := s.repo.GetByCode(NormalizeInviteCode(code))
+	if err != nil {
+		return dto.AcceptInviteResponse{}, errors.New("invite not found")
+	}
+
+	use, err := s.repo.Redeem(invite.Code, user.UserID, message)
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":     err,
+			"user_id":   user.UserID,
+			"invite_id": invite.ID,
+		}).Warn("Failed to redeem group invite")
+		return dto.AcceptInviteResponse{}, err
+	}
+
+	// the application may still be approved right away by the group's auto-approval rules
+	if use.ApplicationID != nil && s.applications.AutoApprove(*use.ApplicationID) {
+		use.Result = "joined"
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id":   user.UserID,
+		"group_id":  invite.GroupID,
+		"invite_id": invite.ID,
+		"result":    use.Result,
+	}).Info("Group invite redeemed")
+	return dto.AcceptInviteResponse{
+		GroupID:       invite.GroupID,
+		Result:        use.Result,
+		ApplicationID: use.ApplicationID,
+	}, nil
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+type GroupMembershipService struct {
+	repo           *repositories.GroupMembershipRepository
+	groupRepo      *repositories.GroupRepository
+	groupModerRepo *repositories.GroupModerRepository
+	userRepo       repositories.UserRepository
+	audit          *AuditService
+}
+
+func NewGroupMembershipService(repo *repositories.GroupMembershipRepository, groupRepo *repositories.GroupRepository, groupModerRepo *repositories.GroupModerRepository, userRepo repositories.UserRepository, audit *AuditService) *GroupMembershipService {
+	return &GroupMembershipService{
+		repo:           repo,
+		groupRepo:      groupRepo,
+		groupModerRepo: groupModerRepo,
+		userRepo:       userRepo,
+		audit:          audit,
+	}
+}
+
+func (s *GroupMembershipService) loadGroup(username string, groupID int32) (*models.User, *models.Group, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, nil, errors.New("group not found")
+	}
+	return user, group, nil
+}
+
+// authorize returns the acting user and group if the user is the group's admin or moderator
+func (s *GroupMembershipService) authorize(username string, groupID int32) (*models.User, *models.Group, error) {
+	user, group, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, user.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isAuthorized {
+		return nil, nil, errors.New("access denied: admin or moderator role required")
+	}
+	return user, group, nil
+}
+
+// authorizeOwner returns the acting user and group if the user owns the group
+func (s *GroupMembershipService) authorizeOwner(username string, groupID int32) (*models.User, *models.Group, error) {
+	user, group, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if group.AdminID != user.UserID {
+		return nil, nil, errors.New("access denied: group owner role required")
+	}
+	return user, group, nil
+}
+
+// checkTarget keeps moderators from acting on the owner, other moderators or themselves
+func (s *GroupMembershipService) checkTarget(actor *models.User, group *models.Group, targetID int32) error {
+	if targetID == actor.UserID {
+		return errors.New("cannot remove yourself, leave the group instead")
+	}
+	if targetID == group.AdminID {
+		return errors.New("group owner cannot be removed")
+	}
+	if actor.UserID == group.AdminID {
+		return nil
+	}
+	isModerator, err := s.groupModerRepo.IsModerator(group.ID, targetID)
+	if err != nil {
+		return err
+	}
+	if isModerator {
+		return errors.New("only the group owner can remove moderators")
+	}
+	return nil
+}
+
+// Leave removes the user from the group. The owner has to transfer ownership first.
+func (s *GroupMembershipService) Leave(username string, groupID int32) error {
+	user, group, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return err
+	}
+	if group.AdminID == user.UserID {
+		return errors.New("group owner must transfer ownership before leaving")
+	}
+	if err := s.repo.Remove(&models.GroupMembershipEvent{
+		GroupID: groupID,
+		UserID:  user.UserID,
+		ActorID: user.UserID,
+		Action:  "left",
+	}); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"user_id":  user.UserID,
+	}).Info("User left group")
+	return nil
+}
+
+// Kick removes a member from the group. They may apply again; use Ban to prevent that.
+func (s *GroupMembershipService) Kick(username string, groupID, targetID int32, reason string) error {
+	actor, group, err := s.authorize(username, groupID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTarget(actor, group, targetID); err != nil {
+		return err
+	}
+	if err := s.repo.Remove(&models.GroupMembershipEvent{
+		GroupID: groupID,
+		UserID:  targetID,
+		ActorID: actor.UserID,
+		Action:  "kicked",
+		Reason:  reason,
+	}); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":  groupID,
+		"user_id":   targetID,
+		"kicked_by": actor.UserID,
+	}).Info("User kicked from group")
+	s.audit.Record(groupID, actor.UserID, models.AuditMemberKick, "user", targetID,
+		map[string]interface{}{"member": true}, map[string]interface{}{"member": false, "reason": reason})
+	return nil
+}
+
+// RemoveMember backs DELETE /api/group-users: removing yourself leaves the group, removing
+// someone else is a kick without a reason
+func (s *GroupMembershipService) RemoveMember(username string, groupID, targetID int32) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.UserID == targetID {
+		return s.Leave(username, groupID)
+	}
+	return s.Kick(username, groupID, targetID, "")
+}
+
+// Ban removes the user from the group, if they are in it, and keeps them from applying or
+// redeeming invites until the ban is lifted
+func (s *GroupMembershipService) Ban(username string, groupID int32, req dto.BanRequest) (dto.GroupBanDTO, error) {
+	actor, group, err := s.authorize(username, groupID)
+	if err != nil {
+		return dto.GroupBanDTO{}, err
+	}
+	target, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return dto.GroupBanDTO{}, errors.New("target user not found")
+	}
+	if err := s.checkTarget(actor, group, target.UserID); err != nil {
+		return dto.GroupBanDTO{}, err
+	}
+
+	ban := &models.GroupBan{
+		GroupID:  groupID,
+		UserID:   target.UserID,
+		Reason:   req.Reason,
+		BannedBy: actor.UserID,
+	}
+	if err := s.repo.Ban(ban); err != nil {
+		if !errors.Is(err, repositories.ErrAlreadyBanned) {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"group_id": groupID,
+				"user_id":  target.UserID,
+			}).Error("Failed to ban user")
+		}
+		return dto.GroupBanDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":  groupID,
+		"user_id":   target.UserID,
+		"banned_by": actor.UserID,
+	}).Info("User banned from group")
+	s.audit.Record(groupID, actor.UserID, models.AuditMemberBan, "user", target.UserID,
+		map[string]interface{}{"banned": false}, map[string]interface{}{"banned": true, "reason": req.Reason})
+
+	ban.User = *target
+	ban.Moderator = *actor
+	return dto.ToGroupBanDTO(ban), nil
+}
+
+func (s *GroupMembershipService) Unban(username string, groupID, targetID int32, reason string) error {
+	actor, _, err := s.authorize(username, groupID)
+	if err != nil {
+		return err
+	}
+	ban, err := s.repo.GetActiveBan(groupID, targetID)
+	if err != nil {
+		return errors.New("ban not found")
+	}
+	if err := s.repo.Lift(ban, actor.UserID, reason); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":  groupID,
+		"user_id":   targetID,
+		"lifted_by": actor.UserID,
+	}).Info("Group ban lifted")
+	s.audit.Record(groupID, actor.UserID, models.AuditMemberUnban, "user", targetID,
+		map[string]interface{}{"banned": true, "reason": ban.Reason}, map[string]interface{}{"banned": false, "reason": reason})
+	return nil
+}
+
+func (s *GroupMembershipService) GetBans(username string, groupID int32, includeLifted bool) ([]dto.GroupBanDTO, error) {
+	if _, _, err := s.authorize(username, groupID); err != nil {
+		return nil, err
+	}
+	bans, err := s.repo.FindBans(groupID, includeLifted)
+	if err != nil {
+		return nil, err
+	}
+	banDTOs := make([]dto.GroupBanDTO, len(bans))
+	for i := range bans {
+		banDTOs[i] = dto.ToGroupBanDTO(&bans[i])
+	}
+	return banDTOs, nil
+}
+
+// GetEvents returns the group's membership log: who left, was kicked, banned or unbanned
+func (s *GroupMembershipService) GetEvents(username string, groupID int32, page, pageSize int) (dto.MembershipEventsResponse, error) {
+	if _, _, err := s.authorize(username, groupID); err != nil {
+		return dto.MembershipEventsResponse{}, err
+	}
+	events, total, err := s.repo.FindEvents(groupID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return dto.MembershipEventsResponse{}, err
+	}
+	eventDTOs := make([]dto.MembershipEventDTO, len(events))
+	for i := range events {
+		eventDTOs[i] = dto.ToMembershipEventDTO(&events[i])
+	}
+	return dto.MembershipEventsResponse{
+		Events: eventDTOs,
+		Pagination: dto.PaginationMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+			Pages:    (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	}, nil
+}
+
+// SetRole makes a member a moderator or demotes them back to member. Only the owner can
+// change roles, and the owner's own role only changes through an ownership transfer.
+func (s *GroupMembershipService) SetRole(username string, groupID, targetID int32, role string) (dto.MemberRoleDTO, error) {
+	actor, group, err := s.authorizeOwner(username, groupID)
+	if err != nil {
+		return dto.MemberRoleDTO{}, err
+	}
+	if targetID == group.AdminID {
+		return dto.MemberRoleDTO{}, errors.New("owner role can only be changed by transferring ownership")
+	}
+	target, err := s.userRepo.GetByID(targetID)
+	if err != nil {
+		return dto.MemberRoleDTO{}, errors.New("target user not found")
+	}
+
+	changed, err := s.repo.SetRole(groupID, target.UserID, role, actor.UserID)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotMember) {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":    err,
+				"group_id": groupID,
+				"user_id":  target.UserID,
+				"role":     role,
+			}).Error("Failed to change member role")
+		}
+		return dto.MemberRoleDTO{}, err
+	}
+	if changed {
+		utils.Logger.WithFields(logrus.Fields{
+			"group_id":   groupID,
+			"user_id":    target.UserID,
+			"role":       role,
+			"changed_by": actor.UserID,
+		}).Info("Group member role changed")
+		action, previous := models.AuditModeratorGrant, "member"
+		if role != "moderator" {
+			action, previous = models.AuditModeratorRevoke, "moderator"
+		}
+		s.audit.Record(groupID, actor.UserID, action, "user", target.UserID,
+			map[string]interface{}{"role": previous}, map[string]interface{}{"role": role})
+	}
+	return dto.MemberRoleDTO{
+		GroupID:  groupID,
+		UserID:   target.UserID,
+		Username: target.Username,
+		Role:     role,
+		Changed:  changed,
+	}, nil
+}
+
+// RemoveModerator demotes a moderator to a plain member; they stay in the group
+func (s *GroupMembershipService) RemoveModerator(username string, groupID, targetID int32) error {
+	if _, _, err := s.authorizeOwner(username, groupID); err != nil {
+		return err
+	}
+	isModerator, err := s.groupModerRepo.IsModerator(groupID, targetID)
+	if err != nil {
+		return err
+	}
+	if !isModerator {
+		return errors.New("user is not a moderator")
+	}
+	_, err = s.SetRole(username, groupID, targetID, "member")
+	return err
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"errors"
 	"space/models"
 	"space/models/dto"
 	"space/repositories"
@@ -22,22 +21,6 @@ func (s *GroupModerService) GetGroupModer(groupID, userID int32) (*models.GroupM
 	return s.groupModerRepo.GetByID(groupID, userID)
 }
 
-func (s *GroupModerService) CreateGroupModer(groupModer *models.GroupModer) error {
-	if groupModer.GroupID == 0 || groupModer.UserID == 0 {
-		return errors.New("group_id and user_id are required")
-	}
-	// Check if moderator already exists
-	_, err := s.groupModerRepo.GetByID(groupModer.GroupID, groupModer.UserID)
-	if err == nil {
-		return errors.New("moderator already exists")
-	}
-	return s.groupModerRepo.Create(groupModer)
-}
-
-func (s *GroupModerService) DeleteGroupModer(groupID, userID int32) error {
-	return s.groupModerRepo.Delete(groupID, userID)
-}
-
 func (s *GroupModerService) GetModeratorsByGroupID(groupID int32) ([]dto.UserDTO, error) {
 	groupModers, err := s.groupModerRepo.FindByGroupID(groupID)
 	if err != nil {
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+type GroupOwnershipService struct {
+	repo          *repositories.GroupOwnershipRepository
+	groupRepo     *repositories.GroupRepository
+	groupUserRepo *repositories.GroupUserRepository
+	userRepo      repositories.UserRepository
+}
+
+func NewGroupOwnershipService(repo *repositories.GroupOwnershipRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, userRepo repositories.UserRepository) *GroupOwnershipService {
+	return &GroupOwnershipService{
+		repo:          repo,
+		groupRepo:     groupRepo,
+		groupUserRepo: groupUserRepo,
+		userRepo:      userRepo,
+	}
+}
+
+func (s *GroupOwnershipService) loadGroup(username string, groupID int32) (*models.User, *models.Group, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, nil, errors.New("group not found")
+	}
+	return user, group, nil
+}
+
+// loadPending returns the group's pending transfer
+func (s *GroupOwnershipService) loadPending(groupID int32) (*models.GroupOwnershipTransfer, error) {
+	transfer, err := s.repo.GetPending(groupID)
+	if err != nil {
+		return nil, errors.New("no pending transfer")
+	}
+	return transfer, nil
+}
+
+// ProposeTransfer offers the group to another member; it changes hands once they accept.
+// A new proposal replaces a pending one.
+func (s *GroupOwnershipService) ProposeTransfer(username string, groupID int32, req dto.ProposeTransferRequest) (dto.OwnershipTransferDTO, error) {
+	user, group, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return dto.OwnershipTransferDTO{}, err
+	}
+	if group.AdminID != user.UserID {
+		return dto.OwnershipTransferDTO{}, errors.New("only the group owner can transfer ownership")
+	}
+	target, err := s.userRepo.GetByUsername(req.Username)
+	if err != nil {
+		return dto.OwnershipTransferDTO{}, errors.New("target user not found")
+	}
+	if target.UserID == user.UserID {
+		return dto.OwnershipTransferDTO{}, errors.New("cannot transfer ownership to yourself")
+	}
+	isMember, err := s.groupUserRepo.IsMember(groupID, target.UserID)
+	if err != nil {
+		return dto.OwnershipTransferDTO{}, err
+	}
+	if !isMember {
+		return dto.OwnershipTransferDTO{}, errors.New("new owner must be a group member")
+	}
+
+	transfer := &models.GroupOwnershipTransfer{
+		GroupID:    groupID,
+		FromUserID: user.UserID,
+		ToUserID:   target.UserID,
+	}
+	if err := s.repo.Propose(transfer); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to propose ownership transfer")
+		return dto.OwnershipTransferDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"from":     user.UserID,
+		"to":       target.UserID,
+	}).Info("Ownership transfer proposed")
+
+	transfer.Group = *group
+	transfer.FromUser = *user
+	transfer.ToUser = *target
+	return dto.ToOwnershipTransferDTO(transfer), nil
+}
+
+// GetTransfers lists the group's pending and past transfers. Visible to admins and moderators.
+func (s *GroupOwnershipService) GetTransfers(username string, groupID int32) ([]dto.OwnershipTransferDTO, error) {
+	user, _, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return nil, err
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthorized {
+		return nil, errors.New("access denied: admin or moderator role required")
+	}
+	transfers, err := s.repo.FindByGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	transferDTOs := make([]dto.OwnershipTransferDTO, len(transfers))
+	for i := range transfers {
+		transferDTOs[i] = dto.ToOwnershipTransferDTO(&transfers[i])
+	}
+	return transferDTOs, nil
+}
+
+// GetIncomingTransfers lists the transfers waiting for the user's answer
+func (s *GroupOwnershipService) GetIncomingTransfers(username string) ([]dto.OwnershipTransferDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	transfers, err := s.repo.FindPendingForUser(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	transferDTOs := make([]dto.OwnershipTransferDTO, len(transfers))
+	for i := range transfers {
+		transferDTOs[i] = dto.ToOwnershipTransferDTO(&transfers[i])
+	}
+	return transferDTOs, nil
+}
+
+func (s *GroupOwnershipService) CancelTransfer(username string, groupID int32) error {
+	user, _, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return err
+	}
+	transfer, err := s.loadPending(groupID)
+	if err != nil {
+		return err
+	}
+	if transfer.FromUserID != user.UserID {
+		return errors.New("only the group owner can cancel the transfer")
+	}
+	return s.repo.Close(transfer, "cancelled")
+}
+
+// RespondToTransfer accepts or declines the group's pending transfer addressed to the user
+func (s *GroupOwnershipService) RespondToTransfer(username string, groupID int32, accept bool) (dto.OwnershipTransferDTO, error) {
+	user, _, err := s.loadGroup(username, groupID)
+	if err != nil {
+		return dto.OwnershipTransferDTO{}, err
+	}
+	transfer, err := s.loadPending(groupID)
+	if err != nil {
+		return dto.OwnershipTransferDTO{}, err
+	}
+	if transfer.ToUserID != user.UserID {
+		return dto.OwnershipTransferDTO{}, errors.New("transfer is addressed to another user")
+	}
+
+	if !accept {
+		if err := s.repo.Close(transfer, "declined"); err != nil {
+			return dto.OwnershipTransferDTO{}, err
+		}
+		return dto.ToOwnershipTransferDTO(transfer), nil
+	}
+	if err := s.repo.Accept(transfer); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"group_id":    groupID,
+			"transfer_id": transfer.ID,
+		}).Warn("Failed to accept ownership transfer")
+		return dto.OwnershipTransferDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"from":     transfer.FromUserID,
+		"to":       transfer.ToUserID,
+	}).Info("Group ownership transferred")
+	return dto.ToOwnershipTransferDTO(transfer), nil
+}
+
+// AssignOwner makes the user the group's owner right away, without a transfer the current
+// owner would have to propose. It is an operator action (create-admin), not exposed over HTTP.
+func (s *GroupOwnershipService) AssignOwner(groupID int32, username string) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.DeletedAt != nil {
+		return errors.New("user account is deleted")
+	}
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return errors.New("group not found")
+	}
+	if err := s.repo.AssignOwner(groupID, user.UserID); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"user_id":  user.UserID,
+		}).Error("Failed to assign group owner")
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"to":       user.UserID,
+	}).Info("Group owner assigned by operator")
+	return nil
+}
+
+// DeleteAccount deletes the user's account after checking their password. Groups they own pass
+// to their longest-serving moderator, or longest-standing member when there are none.
+func (s *GroupOwnershipService) DeleteAccount(username, password string) (dto.DeleteAccountResponse, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.DeleteAccountResponse{}, errors.New("user not found")
+	}
+	if err := utils.CheckPasswordHash(user.HashPassword, password); err != nil {
+		return dto.DeleteAccountResponse{}, errors.New("invalid password")
+	}
+
+	successions, err := s.repo.DeleteAccount(user)
+	if err != nil {
+		return dto.DeleteAccountResponse{}, err
+	}
+
+	response := dto.DeleteAccountResponse{Groups: make([]dto.SuccessionDTO, len(successions))}
+	for i, succession := range successions {
+		response.Groups[i] = dto.SuccessionDTO{
+			GroupID:    succession.GroupID,
+			NewOwnerID: succession.NewOwnerID,
+			Deleted:    succession.Deleted,
+		}
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id":     user.UserID,
+		"successions": len(successions),
+	}).Info("Account deleted")
+	return response, nil
+}
@@ -16,25 +16,36 @@ type GroupService struct {
 	userRepo       repositories.UserRepository // интерфейс!!!
 	groupuserRepo  *repositories.GroupUserRepository
 	groupModerRepo *repositories.GroupModerRepository
+	reputationRepo *repositories.ReputationRepository
+	audit          *AuditService
 }
 
-func NewGroupService(groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository, groupuserRepo *repositories.GroupUserRepository, groupModerRepo *repositories.GroupModerRepository) *GroupService {
-	return &GroupService{groupRepo, userRepo, groupuserRepo, groupModerRepo}
+func NewGroupService(groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository, groupuserRepo *repositories.GroupUserRepository, groupModerRepo *repositories.GroupModerRepository, reputationRepo *repositories.ReputationRepository, audit *AuditService) *GroupService {
+	return &GroupService{groupRepo, userRepo, groupuserRepo, groupModerRepo, reputationRepo, audit}
+}
+
+// withReputation sets each user's reputation in the group on their DTO
+func (s *GroupService) withReputation(groupID int32, users ...*dto.UserDTO) error {
+	scores, err := s.reputationRepo.Scores(groupID)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		score := scores[user.UserID]
+		user.Reputation = &score
+	}
+	return nil
 }
 
 func (s *GroupService) GetGroupByID(id int32) (*models.Group, error) {
 	return s.groupRepo.GetByID(id)
 }
 func (s *GroupService) GetGroupDTOByID(id int32) (dto.GroupDTO, error) {
-	group, error := s.groupRepo.GetByID(id)
-	groupDTO := dto.GroupDTO{
-		ID:              group.ID,
-		Name:            group.Name,
-		AdminUsername:   group.Admin.Username,
-		AcademicGroupID: group.AcademicGroupID,
-		AcademicGroup:   group.AcademicGroup.Name}
-
-	return groupDTO, error
+	group, err := s.groupRepo.GetByID(id)
+	if err != nil {
+		return dto.GroupDTO{}, err
+	}
+	return dto.ToGroupDTO(group), nil
 }
 
 func (s *GroupService) GetAllGroups(page, pageSize int32) ([]dto.GroupDTO, int64, error) {
@@ -45,24 +56,39 @@ func (s *GroupService) GetAllGroups(page, pageSize int32) ([]dto.GroupDTO, int64
 
 	var groupDTOs []dto.GroupDTO
 	for _, group := range groups {
-		dto := dto.GroupDTO{
-			ID:              group.ID,
-			Name:            group.Name,
-			AdminUsername:   group.Admin.Username,
-			AcademicGroupID: group.AcademicGroupID,
-			AcademicGroup:   group.AcademicGroup.Name,
-		}
-		groupDTOs = append(groupDTOs, dto)
+		groupDTOs = append(groupDTOs, dto.ToGroupDTO(&group))
 	}
 
 	return groupDTOs, total, nil
 }
 
+// validateJoinSettings fills in the default visibility and join policy and rejects unknown values
+func validateJoinSettings(group *models.Group) error {
+	switch group.Visibility {
+	case "":
+		group.Visibility = models.GroupPublic
+	case models.GroupPublic, models.GroupUnlisted, models.GroupPrivate:
+	default:
+		return errors.New("invalid visibility")
+	}
+	switch group.JoinPolicy {
+	case "":
+		group.JoinPolicy = models.JoinApplication
+	case models.JoinOpen, models.JoinApplication, models.JoinInviteOnly:
+	default:
+		return errors.New("invalid join policy")
+	}
+	return nil
+}
+
 func (s *GroupService) CreateGroup(c *gin.Context, group *models.Group) error {
 	if group.Name == "" || group.AcademicGroupID == 0 {
 		utils.Logger.WithFields(logrus.Fields{}).Error("name and academic_group_id are required")
 		return errors.New("name and academic_group_id are required")
 	}
+	if err := validateJoinSettings(group); err != nil {
+		return err
+	}
 
 	// Get authenticated user's username from context
 	username, exists := c.Get("username")
@@ -95,22 +121,6 @@ func (s *GroupService) CreateGroup(c *gin.Context, group *models.Group) error {
 		}).Error("failed to create new group")
 		return err
 	}
-	// Add admin to groupuser relationship
-	groupUser := &models.GroupUser{
-		GroupID: group.ID,
-		UserID:  user.UserID,
-		Role:    "admin",
-	}
-	if err := s.groupuserRepo.Create(groupUser); err != nil {
-		utils.Logger.WithFields(logrus.Fields{
-			"error":      err,
-			"user":       username,
-			"group_name": group.Name,
-			"userID":     user.UserID,
-			"place":      "GroupService.CreateGroup",
-		}).Error("failed to assign to admin user groupuser relationship")
-	}
-
 	return nil
 }
 
@@ -118,6 +128,9 @@ func (s *GroupService) UpdateGroup(c *gin.Context, group *models.Group) error {
 	if group.Name == "" {
 		return errors.New("name is required")
 	}
+	if err := validateJoinSettings(group); err != nil {
+		return err
+	}
 
 	// Get authenticated user
 	username, exists := c.Get("username")
@@ -184,6 +197,7 @@ func (s *GroupService) DeleteGroup(id int32, username string) error {
 		"username": username,
 		"group_id": id,
 	}).Info("Group deleted successfully")
+	s.audit.Record(id, user.UserID, models.AuditGroupDelete, "group", id, dto.ToGroupDTO(group), nil)
 	return nil
 }
 
@@ -286,6 +300,13 @@ func (s *GroupService) GetGroupModeratorsAndAdmin(groupID int32) (dto.Moderators
 		Admin:      dto.ToUserDTO(&group.Admin),
 		Moderators: moderatorDTOs,
 	}
+	listed := []*dto.UserDTO{&response.Admin}
+	for i := range response.Moderators {
+		listed = append(listed, &response.Moderators[i])
+	}
+	if err := s.withReputation(groupID, listed...); err != nil {
+		return dto.ModeratorsResponse{}, err
+	}
 	utils.Logger.WithFields(logrus.Fields{
 		"group_id":        groupID,
 		"admin_id":        group.AdminID,
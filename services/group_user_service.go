@@ -1,7 +1,6 @@
 package services
 
 import (
-	"errors"
 	"space/models"
 	"space/models/dto"
 	"space/repositories"
@@ -22,27 +21,6 @@ func (s *GroupUserService) GetGroupUser(groupID, userID int32) (*models.GroupUse
 	return s.groupUserRepo.GetByID(groupID, userID)
 }
 
-func (s *GroupUserService) CreateGroupUser(groupUser *models.GroupUser) error {
-	if groupUser.GroupID == 0 || groupUser.UserID == 0 {
-		return errors.New("group_id and user_id are required")
-	}
-	if groupUser.Role == "" {
-		groupUser.Role = "member" // Default role
-	}
-	return s.groupUserRepo.Create(groupUser)
-}
-
-func (s *GroupUserService) UpdateGroupUser(groupUser *models.GroupUser) error {
-	if groupUser.Role == "" {
-		return errors.New("role is required")
-	}
-	return s.groupUserRepo.Update(groupUser)
-}
-
-func (s *GroupUserService) DeleteGroupUser(groupID, userID int32) error {
-	return s.groupUserRepo.Delete(groupID, userID)
-}
-
 func (s *GroupUserService) GetUsersByGroupID(groupID int32) ([]dto.UserDTO, error) {
 	groupUsers, err := s.groupUserRepo.FindByGroupID(groupID)
 	if err != nil {
@@ -68,6 +46,13 @@ func (s *GroupService) GetGroupUsers(groupID int32) ([]dto.UserDTO, error) {
 	for _, user := range users {
 		userDTOs = append(userDTOs, dto.ToUserDTO(&user.User))
 	}
+	listed := make([]*dto.UserDTO, len(userDTOs))
+	for i := range userDTOs {
+		listed[i] = &userDTOs[i]
+	}
+	if err := s.withReputation(groupID, listed...); err != nil {
+		return nil, err
+	}
 	utils.Logger.WithFields(logrus.Fields{
 		"group_id": groupID,
 		"count":    len(userDTOs),
@@ -0,0 +1,577 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxMaterialContentSize    = 1 << 20
+	MaxAttachmentSize         = 10 << 20
+	maxAttachmentsPerMaterial = 20
+)
+
+type MaterialService struct {
+	repo          *repositories.MaterialRepository
+	subjectRepo   *repositories.SubjectRepository
+	groupRepo     *repositories.GroupRepository
+	groupUserRepo *repositories.GroupUserRepository
+	userRepo      repositories.UserRepository
+}
+
+func NewMaterialService(repo *repositories.MaterialRepository, subjectRepo *repositories.SubjectRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, userRepo repositories.UserRepository) *MaterialService {
+	return &MaterialService{
+		repo:          repo,
+		subjectRepo:   subjectRepo,
+		groupRepo:     groupRepo,
+		groupUserRepo: groupUserRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// memberGroups returns the user's groups in the academic group that owns the subject.
+// Materials of a subject are only visible to members of those groups; site administrators
+// also see the shared ones without being a member.
+func (s *MaterialService) memberGroups(user *models.User, subject *models.Subject) ([]int32, error) {
+	groupIDs, err := s.groupUserRepo.FindGroupIDsInAcademicGroup(user.UserID, subject.AcademicGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(groupIDs) == 0 && !user.IsAdmin {
+		return nil, errors.New("access denied: group membership required")
+	}
+	return groupIDs, nil
+}
+
+// canManage: a group's materials are managed by its admin and moderators, shared materials
+// only by site administrators. Group roles don't count for those, anyone can create a group.
+func (s *MaterialService) canManage(user *models.User, groupID *int32) (bool, error) {
+	if groupID != nil {
+		return s.groupRepo.IsAdminOrModerator(*groupID, user.UserID)
+	}
+	return user.IsAdmin, nil
+}
+
+// errManageDenied is returned when canManage refuses the user
+func errManageDenied(groupID *int32) error {
+	if groupID == nil {
+		return errors.New("access denied: administrator role required")
+	}
+	return errors.New("access denied: admin or moderator role required")
+}
+
+func (s *MaterialService) canEdit(user *models.User, material *models.Material) (bool, error) {
+	if material.CreatedBy == user.UserID {
+		return true, nil
+	}
+	return s.canManage(user, material.GroupID)
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// loadMaterial returns a material the user may read. Deactivated materials are only visible
+// to those who can edit them.
+func (s *MaterialService) loadMaterial(user *models.User, id int32) (*models.Material, error) {
+	material, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("material not found")
+	}
+	groupIDs, err := s.memberGroups(user, &material.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if material.GroupID != nil && !containsID(groupIDs, *material.GroupID) {
+		return nil, errors.New("access denied: group membership required")
+	}
+	if !material.IsActive {
+		canEdit, err := s.canEdit(user, material)
+		if err != nil {
+			return nil, err
+		}
+		if !canEdit {
+			return nil, errors.New("material not found")
+		}
+	}
+	return material, nil
+}
+
+// loadEditableMaterial returns a material the user created or manages
+func (s *MaterialService) loadEditableMaterial(user *models.User, id int32) (*models.Material, error) {
+	material, err := s.loadMaterial(user, id)
+	if err != nil {
+		return nil, err
+	}
+	canEdit, err := s.canEdit(user, material)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("access denied: only the author, admins and moderators can edit materials")
+	}
+	return material, nil
+}
+
+func (s *MaterialService) ListMaterials(username string, subjectID int32, groupID *int32, includeInactive bool) ([]dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	subject, err := s.subjectRepo.GetByID(subjectID)
+	if err != nil {
+		return nil, errors.New("subject not found")
+	}
+	groupIDs, err := s.memberGroups(user, subject)
+	if err != nil {
+		return nil, err
+	}
+	if groupID != nil {
+		if !containsID(groupIDs, *groupID) {
+			return nil, errors.New("access denied: group membership required")
+		}
+		groupIDs = []int32{*groupID}
+	}
+	if includeInactive {
+		canManage, err := s.canManage(user, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if !canManage {
+			return nil, errManageDenied(groupID)
+		}
+	}
+
+	materials, err := s.repo.FindBySubject(subjectID, groupIDs, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	materialDTOs := make([]dto.MaterialDTO, len(materials))
+	for i := range materials {
+		materialDTOs[i] = dto.ToMaterialDTO(&materials[i])
+	}
+	return materialDTOs, nil
+}
+
+func (s *MaterialService) GetMaterial(username string, id int32) (dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadMaterial(user, id)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	return dto.ToMaterialDTO(material), nil
+}
+
+// CreateMaterial adds a material to the subject. Any member may post to one of their groups;
+// materials shared with the whole academic group need a site administrator.
+func (s *MaterialService) CreateMaterial(username string, subjectID int32, req dto.CreateMaterialRequest) (dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialDTO{}, errors.New("user not found")
+	}
+	subject, err := s.subjectRepo.GetByID(subjectID)
+	if err != nil {
+		return dto.MaterialDTO{}, errors.New("subject not found")
+	}
+	groupIDs, err := s.memberGroups(user, subject)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	if req.GroupID != nil {
+		group, err := s.groupRepo.GetByID(*req.GroupID)
+		if err != nil {
+			return dto.MaterialDTO{}, errors.New("group not found")
+		}
+		if group.AcademicGroupID != subject.AcademicGroupID {
+			return dto.MaterialDTO{}, errors.New("group does not belong to the subject's academic group")
+		}
+		if !containsID(groupIDs, group.ID) {
+			return dto.MaterialDTO{}, errors.New("access denied: group membership required")
+		}
+	} else {
+		canManage, err := s.canManage(user, nil)
+		if err != nil {
+			return dto.MaterialDTO{}, err
+		}
+		if !canManage {
+			return dto.MaterialDTO{}, errManageDenied(nil)
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return dto.MaterialDTO{}, errors.New("material title is required")
+	}
+	if len(req.Content) > maxMaterialContentSize {
+		return dto.MaterialDTO{}, errors.New("material content is too large")
+	}
+	material := &models.Material{
+		SubjectID: subjectID,
+		GroupID:   req.GroupID,
+		Title:     title,
+		Content:   req.Content,
+		CreatedBy: user.UserID,
+		IsActive:  true,
+	}
+	if req.Position != nil {
+		material.Position = *req.Position
+	} else if material.Position, err = s.repo.NextPosition(subjectID); err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	if err := s.repo.Create(material); err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"subject_id":  subjectID,
+		"material_id": material.MaterialID,
+	}).Info("Material created")
+
+	created, err := s.repo.GetByID(material.MaterialID)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	return dto.ToMaterialDTO(created), nil
+}
+
+// UpdateMaterial edits a material; setting is_active to false hides it from members.
+// A changed title or content is saved as a new revision.
+func (s *MaterialService) UpdateMaterial(username string, id int32, req dto.UpdateMaterialRequest) (dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadEditableMaterial(user, id)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	if req.BaseVersion != nil && *req.BaseVersion != material.Version {
+		return dto.MaterialDTO{}, repositories.ErrVersionConflict
+	}
+
+	title, content := material.Title, material.Content
+	if req.Title != nil {
+		title = strings.TrimSpace(*req.Title)
+		if title == "" {
+			return dto.MaterialDTO{}, errors.New("material title is required")
+		}
+	}
+	if req.Content != nil {
+		if len(*req.Content) > maxMaterialContentSize {
+			return dto.MaterialDTO{}, errors.New("material content is too large")
+		}
+		content = *req.Content
+	}
+	if req.IsActive != nil {
+		material.IsActive = *req.IsActive
+	}
+
+	if title != material.Title || content != material.Content {
+		material.Title, material.Content = title, content
+		err = s.repo.SaveRevision(material, &models.MaterialRevision{
+			Title:    title,
+			Content:  content,
+			EditedBy: user.UserID,
+			Comment:  strings.TrimSpace(req.Comment),
+		})
+	} else if req.IsActive != nil {
+		err = s.repo.SetActive(material, material.IsActive)
+	}
+	if err != nil {
+		if err != repositories.ErrVersionConflict {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":       err,
+				"material_id": id,
+			}).Error("Failed to update material")
+		}
+		return dto.MaterialDTO{}, err
+	}
+	return dto.ToMaterialDTO(material), nil
+}
+
+// DeactivateMaterial hides a material without deleting it; it can be restored with UpdateMaterial
+func (s *MaterialService) DeactivateMaterial(username string, id int32) error {
+	active := false
+	if _, err := s.UpdateMaterial(username, id, dto.UpdateMaterialRequest{IsActive: &active}); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"material_id": id,
+	}).Info("Material deactivated")
+	return nil
+}
+
+// ReorderMaterials puts the listed materials first in the given order; the user's other visible
+// materials of the subject follow in their current order
+func (s *MaterialService) ReorderMaterials(username string, subjectID int32, ids []int32) ([]dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	subject, err := s.subjectRepo.GetByID(subjectID)
+	if err != nil {
+		return nil, errors.New("subject not found")
+	}
+	groupIDs, err := s.memberGroups(user, subject)
+	if err != nil {
+		return nil, err
+	}
+	canManage, err := s.canManage(user, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errManageDenied(nil)
+	}
+
+	materials, err := s.repo.FindBySubject(subjectID, groupIDs, true)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int32]bool, len(materials))
+	for _, m := range materials {
+		visible[m.MaterialID] = true
+	}
+	listed := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		if !visible[id] || listed[id] {
+			return nil, errors.New("invalid material order")
+		}
+		listed[id] = true
+	}
+	order := append([]int32{}, ids...)
+	for _, m := range materials {
+		if !listed[m.MaterialID] {
+			order = append(order, m.MaterialID)
+		}
+	}
+	if err := s.repo.Reorder(subjectID, order); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":      err,
+			"subject_id": subjectID,
+		}).Error("Failed to reorder materials")
+		return nil, err
+	}
+	return s.ListMaterials(username, subjectID, nil, true)
+}
+
+func (s *MaterialService) AddAttachment(username string, materialID int32, fileName, contentType string, data []byte) (dto.MaterialAttachmentDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialAttachmentDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadEditableMaterial(user, materialID)
+	if err != nil {
+		return dto.MaterialAttachmentDTO{}, err
+	}
+	if len(material.Attachments) >= maxAttachmentsPerMaterial {
+		return dto.MaterialAttachmentDTO{}, errors.New("too many attachments")
+	}
+	if len(data) > MaxAttachmentSize {
+		return dto.MaterialAttachmentDTO{}, errors.New("attachment is too large")
+	}
+	attachment := &models.MaterialAttachment{
+		MaterialID:  materialID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Data:        data,
+		UploadedBy:  user.UserID,
+	}
+	if err := s.repo.CreateAttachment(attachment); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"material_id": materialID,
+		}).Error("Failed to save attachment")
+		return dto.MaterialAttachmentDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":      username,
+		"material_id":   materialID,
+		"attachment_id": attachment.ID,
+		"size":          attachment.Size,
+	}).Info("Material attachment uploaded")
+	return dto.ToMaterialAttachmentDTO(attachment), nil
+}
+
+// GetAttachment returns an attachment with its contents for download
+func (s *MaterialService) GetAttachment(username string, materialID, attachmentID int32) (*models.MaterialAttachment, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if _, err := s.loadMaterial(user, materialID); err != nil {
+		return nil, err
+	}
+	attachment, err := s.repo.GetAttachment(materialID, attachmentID)
+	if err != nil {
+		return nil, errors.New("attachment not found")
+	}
+	return attachment, nil
+}
+
+func (s *MaterialService) DeleteAttachment(username string, materialID, attachmentID int32) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if _, err := s.loadEditableMaterial(user, materialID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetAttachment(materialID, attachmentID); err != nil {
+		return errors.New("attachment not found")
+	}
+	return s.repo.DeleteAttachment(materialID, attachmentID)
+}
+
+// GetRevisions lists the material's revisions, newest first
+func (s *MaterialService) GetRevisions(username string, id int32) ([]dto.MaterialRevisionDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	material, err := s.loadMaterial(user, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.repo.FindRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	revisionDTOs := make([]dto.MaterialRevisionDTO, len(revisions))
+	for i := range revisions {
+		revisionDTOs[i] = dto.ToMaterialRevisionDTO(&revisions[i], material.Version)
+	}
+	return revisionDTOs, nil
+}
+
+func (s *MaterialService) loadRevision(materialID, version int32) (*models.MaterialRevision, error) {
+	revision, err := s.repo.GetRevision(materialID, version)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return revision, nil
+}
+
+func (s *MaterialService) GetRevision(username string, id, version int32) (dto.MaterialRevisionDetailDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialRevisionDetailDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadMaterial(user, id)
+	if err != nil {
+		return dto.MaterialRevisionDetailDTO{}, err
+	}
+	revision, err := s.loadRevision(id, version)
+	if err != nil {
+		return dto.MaterialRevisionDetailDTO{}, err
+	}
+	return dto.MaterialRevisionDetailDTO{
+		MaterialRevisionDTO: dto.ToMaterialRevisionDTO(revision, material.Version),
+		Content:             revision.Content,
+	}, nil
+}
+
+// DiffRevisions returns a unified diff of the title and content between two revisions;
+// to defaults to the current version
+func (s *MaterialService) DiffRevisions(username string, id, from int32, to *int32) (dto.MaterialDiffDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialDiffDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadMaterial(user, id)
+	if err != nil {
+		return dto.MaterialDiffDTO{}, err
+	}
+	toVersion := material.Version
+	if to != nil {
+		toVersion = *to
+	}
+	fromRevision, err := s.loadRevision(id, from)
+	if err != nil {
+		return dto.MaterialDiffDTO{}, err
+	}
+	toRevision, err := s.loadRevision(id, toVersion)
+	if err != nil {
+		return dto.MaterialDiffDTO{}, err
+	}
+
+	// The title goes first so that renames show up in the diff too
+	fromText := "# " + fromRevision.Title + "\n\n" + fromRevision.Content
+	toText := "# " + toRevision.Title + "\n\n" + toRevision.Content
+	diff := utils.UnifiedDiff(fromText, toText,
+		fmt.Sprintf("material-%d/v%d", id, from), fmt.Sprintf("material-%d/v%d", id, toVersion), 3)
+	return dto.MaterialDiffDTO{
+		MaterialID: id,
+		From:       from,
+		To:         toVersion,
+		Identical:  diff == "",
+		Diff:       diff,
+	}, nil
+}
+
+// RestoreRevision rolls the material back by saving the old revision's title and content as
+// a new revision, so the history is never rewritten
+func (s *MaterialService) RestoreRevision(username string, id, version int32, comment string) (dto.MaterialDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.MaterialDTO{}, errors.New("user not found")
+	}
+	material, err := s.loadEditableMaterial(user, id)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	revision, err := s.loadRevision(id, version)
+	if err != nil {
+		return dto.MaterialDTO{}, err
+	}
+	if version == material.Version {
+		return dto.MaterialDTO{}, errors.New("revision is already current")
+	}
+
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		comment = fmt.Sprintf("Restored version %d", version)
+	}
+	material.Title, material.Content = revision.Title, revision.Content
+	err = s.repo.SaveRevision(material, &models.MaterialRevision{
+		Title:        revision.Title,
+		Content:      revision.Content,
+		EditedBy:     user.UserID,
+		Comment:      comment,
+		RestoredFrom: &version,
+	})
+	if err != nil {
+		if err != repositories.ErrVersionConflict {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":       err,
+				"material_id": id,
+				"version":     version,
+			}).Error("Failed to restore material revision")
+		}
+		return dto.MaterialDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"material_id": id,
+		"restored":    version,
+		"version":     material.Version,
+	}).Info("Material revision restored")
+	return dto.ToMaterialDTO(material), nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RoomService struct {
+	repo            *repositories.RoomRepository
+	scheduleRepo    *repositories.ScheduleRepository
+	scheduleService *ScheduleService
+}
+
+func NewRoomService(repo *repositories.RoomRepository, scheduleRepo *repositories.ScheduleRepository, scheduleService *ScheduleService) *RoomService {
+	return &RoomService{
+		repo:            repo,
+		scheduleRepo:    scheduleRepo,
+		scheduleService: scheduleService,
+	}
+}
+
+// equipmentTags normalizes tags to lower case and drops empty and repeated ones
+func equipmentTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		tag = normalizeName(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// NewRoomFilter builds a room filter from request values
+func NewRoomFilter(building string, minCapacity int32, equipment []string) repositories.RoomFilter {
+	return repositories.RoomFilter{
+		Building:    strings.TrimSpace(building),
+		MinCapacity: minCapacity,
+		Equipment:   equipmentTags(equipment),
+	}
+}
+
+func toRoomDTOs(rooms []models.Room) []dto.RoomDTO {
+	roomDTOs := make([]dto.RoomDTO, len(rooms))
+	for i := range rooms {
+		roomDTOs[i] = dto.ToRoomDTO(&rooms[i])
+	}
+	return roomDTOs
+}
+
+func (s *RoomService) ListRooms(filter repositories.RoomFilter) ([]dto.RoomDTO, error) {
+	rooms, err := s.repo.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+	return toRoomDTOs(rooms), nil
+}
+
+func (s *RoomService) GetRoom(id int32) (dto.RoomDTO, error) {
+	room, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.RoomDTO{}, errors.New("room not found")
+	}
+	return dto.ToRoomDTO(room), nil
+}
+
+// setRoomCode derives the room's unique code from its label and makes sure no other room has it
+func (s *RoomService) setRoomCode(room *models.Room) error {
+	if room.Number == "" {
+		return errors.New("room number is required")
+	}
+	room.Code = utils.ClassroomKey(utils.RoomLabel(room.Building, room.Number))
+	if existing, err := s.repo.GetByCode(room.Code); err == nil && existing.ID != room.ID {
+		return errors.New("room already exists")
+	}
+	return nil
+}
+
+func roomEquipment(tags []string) []models.RoomEquipment {
+	tags = equipmentTags(tags)
+	equipment := make([]models.RoomEquipment, len(tags))
+	for i, tag := range tags {
+		equipment[i] = models.RoomEquipment{Tag: tag}
+	}
+	return equipment
+}
+
+func (s *RoomService) CreateRoom(username string, req dto.RoomRequest) (dto.RoomDTO, error) {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return dto.RoomDTO{}, err
+	}
+	room := &models.Room{
+		Building:  strings.TrimSpace(req.Building),
+		Number:    strings.TrimSpace(req.Number),
+		Capacity:  req.Capacity,
+		Equipment: roomEquipment(req.Equipment),
+	}
+	if err := s.setRoomCode(room); err != nil {
+		return dto.RoomDTO{}, err
+	}
+	if err := s.repo.Create(room); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+			"code":  room.Code,
+		}).Error("Failed to create room")
+		return dto.RoomDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"room_id":  room.ID,
+	}).Info("Room created")
+	return dto.ToRoomDTO(room), nil
+}
+
+func (s *RoomService) UpdateRoom(username string, id int32, req dto.UpdateRoomRequest) (dto.RoomDTO, error) {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return dto.RoomDTO{}, err
+	}
+	room, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.RoomDTO{}, errors.New("room not found")
+	}
+	if req.Building != nil {
+		room.Building = strings.TrimSpace(*req.Building)
+	}
+	if req.Number != nil {
+		room.Number = strings.TrimSpace(*req.Number)
+	}
+	if req.Capacity != nil {
+		room.Capacity = *req.Capacity
+	}
+	if req.Equipment != nil {
+		room.Equipment = roomEquipment(*req.Equipment)
+	}
+	if err := s.setRoomCode(room); err != nil {
+		return dto.RoomDTO{}, err
+	}
+	if err := s.repo.Update(room); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"room_id": id,
+		}).Error("Failed to update room")
+		return dto.RoomDTO{}, err
+	}
+	return dto.ToRoomDTO(room), nil
+}
+
+func (s *RoomService) DeleteRoom(username string, id int32) error {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("room not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"room_id":  id,
+	}).Info("Room deleted")
+	return nil
+}
+
+// FindFreeRooms returns rooms matching the filter that are free on date during every time slot
+// numbered fromSlot to toSlot inclusive
+func (s *RoomService) FindFreeRooms(date time.Time, fromSlot, toSlot int32, filter repositories.RoomFilter) (dto.FreeRoomsResponse, error) {
+	if toSlot < fromSlot {
+		return dto.FreeRoomsResponse{}, errors.New("invalid time slot range")
+	}
+	slots, err := s.scheduleRepo.FindTimeSlots()
+	if err != nil {
+		return dto.FreeRoomsResponse{}, err
+	}
+	var slotIDs []int32
+	slotDTOs := []dto.TimeSlotDTO{}
+	for i := range slots {
+		if slots[i].SlotNumber >= fromSlot && slots[i].SlotNumber <= toSlot {
+			slotIDs = append(slotIDs, slots[i].SlotID)
+			slotDTOs = append(slotDTOs, dto.ToTimeSlotDTO(&slots[i]))
+		}
+	}
+	if len(slotIDs) == 0 {
+		return dto.FreeRoomsResponse{}, errors.New("invalid time slot range")
+	}
+
+	rooms, err := s.repo.FindFree(date, slotIDs, filter)
+	if err != nil {
+		return dto.FreeRoomsResponse{}, err
+	}
+	return dto.FreeRoomsResponse{
+		Date:      date.Format(dto.DateLayout),
+		TimeSlots: slotDTOs,
+		Rooms:     toRoomDTOs(rooms),
+	}, nil
+}
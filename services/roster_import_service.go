@@ -0,0 +1,331 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/roster"
+	"space/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxRosterRows             = 300
+	temporaryPasswordLength   = 12
+	maxUsernameAttempts       = 50
+	rosterFallbackUsername    = "student"
+	rosterReservedUsernameTag = "deleted-user-"
+)
+
+type RosterImportService struct {
+	repo      *repositories.RosterRepository
+	groupRepo *repositories.GroupRepository
+	userRepo  repositories.UserRepository
+	audit     *AuditService
+}
+
+func NewRosterImportService(repo *repositories.RosterRepository, groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository, audit *AuditService) *RosterImportService {
+	return &RosterImportService{
+		repo:      repo,
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+		audit:     audit,
+	}
+}
+
+// usernameBase derives a username from the local part of an email, keeping letters, digits,
+// dots, dashes and underscores
+func usernameBase(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	base := strings.Trim(b.String(), ".-_")
+	if base == "" || strings.HasPrefix(base, rosterReservedUsernameTag) {
+		return rosterFallbackUsername
+	}
+	return base
+}
+
+// pickUsernames assigns each new account a free username: the email's local part, then the
+// same with 2, 3, ... appended
+func (s *RosterImportService) pickUsernames(users []*models.User) error {
+	planned := make(map[string]bool)
+	for _, user := range users {
+		base := usernameBase(user.Email)
+		candidates := make([]string, maxUsernameAttempts)
+		candidates[0] = base
+		for i := 1; i < maxUsernameAttempts; i++ {
+			candidates[i] = fmt.Sprintf("%s%d", base, i+1)
+		}
+		taken, err := s.repo.FindTakenUsernames(candidates)
+		if err != nil {
+			return err
+		}
+		user.Username = ""
+		for _, candidate := range candidates {
+			if !taken[candidate] && !planned[candidate] {
+				user.Username = candidate
+				break
+			}
+		}
+		if user.Username == "" {
+			return errors.New("failed to pick a username for " + user.Email)
+		}
+		planned[user.Username] = true
+	}
+	return nil
+}
+
+// ImportRoster parses a CSV or XLSX student list and adds the students to the group. Rows are
+// matched to existing accounts by student ID, then by email. Accounts are global, so only a
+// site administrator's import gives unmatched rows a new account with a temporary password;
+// for the group owner they are rejected. Created users become members right away. A site
+// administrator's import also records the group's academic group as verified on created and
+// matched accounts; otherwise existing accounts are never changed: they join only if they already applied to the group, in which
+// case their application is approved; the others are reported so the owner can invite them. Rows that can't be read, repeat an earlier
+// row, conflict with existing accounts or belong to banned users are rejected. With dryRun
+// nothing is written; otherwise everything is applied in one transaction.
+func (s *RosterImportService) ImportRoster(username string, groupID int32, format string, file io.Reader, dryRun bool) (dto.RosterImportReport, error) {
+	actor, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.RosterImportReport{}, errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.RosterImportReport{}, errors.New("group not found")
+	}
+	if group.AdminID != actor.UserID && !actor.IsAdmin {
+		return dto.RosterImportReport{}, errors.New("access denied: group owner role required")
+	}
+
+	var entries []roster.Entry
+	var problems []roster.ParseError
+	switch format {
+	case "csv":
+		entries, problems, err = roster.ParseCSV(file)
+	case "xlsx":
+		entries, problems, err = roster.ParseXLSX(file)
+	default:
+		return dto.RosterImportReport{}, errors.New("unsupported roster format")
+	}
+	if err != nil {
+		if errors.Is(err, roster.ErrUnsupportedFormat) {
+			return dto.RosterImportReport{}, errors.New("unsupported roster format")
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"format":   format,
+		}).Warn("Failed to parse roster")
+		return dto.RosterImportReport{}, errors.New("invalid roster file")
+	}
+	if len(entries)+len(problems) > maxRosterRows {
+		return dto.RosterImportReport{}, errors.New("roster is too large")
+	}
+
+	report := dto.RosterImportReport{
+		DryRun:  dryRun,
+		Format:  format,
+		GroupID: groupID,
+		Items:   []dto.RosterImportItemDTO{},
+	}
+	for _, p := range problems {
+		report.Items = append(report.Items, dto.RosterImportItemDTO{Source: p.Source, Action: "rejected", Reason: p.Message})
+	}
+
+	var emails, studentIDs []string
+	for _, entry := range entries {
+		emails = append(emails, entry.Email)
+		if entry.StudentID != "" {
+			studentIDs = append(studentIDs, entry.StudentID)
+		}
+	}
+	byEmailRows, err := s.repo.FindUsersByEmails(emails)
+	if err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	byStudentRows, err := s.repo.FindUsersByStudentIDs(studentIDs)
+	if err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	members, err := s.repo.FindMemberIDs(groupID)
+	if err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	applicants, err := s.repo.FindApplicantIDs(groupID)
+	if err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	banned, err := s.repo.FindBannedIDs(groupID)
+	if err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	byEmail := make(map[string]*models.User, len(byEmailRows))
+	for i := range byEmailRows {
+		byEmail[strings.ToLower(byEmailRows[i].Email)] = &byEmailRows[i]
+	}
+	byStudentID := make(map[string]*models.User, len(byStudentRows))
+	for i := range byStudentRows {
+		byStudentID[*byStudentRows[i].StudentID] = &byStudentRows[i]
+	}
+
+	plan := &repositories.RosterImport{AcademicGroupID: group.AcademicGroupID}
+	// itemUsers links report items to the account they resolved to, so IDs and usernames can
+	// be filled in once the plan is settled
+	itemUsers := make(map[int]*models.User)
+	seenEmails := make(map[string]string)
+	seenStudentIDs := make(map[string]string)
+	joined := make(map[int32]bool)
+
+	for _, entry := range entries {
+		item := dto.RosterImportItemDTO{
+			Source:    entry.Source,
+			FullName:  entry.FullName,
+			Email:     entry.Email,
+			StudentID: entry.StudentID,
+		}
+		reject := func(reason string) {
+			item.Action, item.Reason = "rejected", reason
+			report.Items = append(report.Items, item)
+		}
+
+		if address, err := mail.ParseAddress(entry.Email); err != nil || address.Address != entry.Email {
+			reject("invalid email")
+			continue
+		}
+		if first, ok := seenEmails[entry.Email]; ok {
+			reject("the file already has this email (" + first + ")")
+			continue
+		}
+		if first, ok := seenStudentIDs[entry.StudentID]; ok && entry.StudentID != "" {
+			reject("the file already has this student ID (" + first + ")")
+			continue
+		}
+		seenEmails[entry.Email] = entry.Source
+		if entry.StudentID != "" {
+			seenStudentIDs[entry.StudentID] = entry.Source
+		}
+
+		studentUser := byStudentID[entry.StudentID]
+		emailUser := byEmail[entry.Email]
+		if studentUser != nil && emailUser != nil && studentUser.UserID != emailUser.UserID {
+			reject("the student ID and the email belong to different accounts")
+			continue
+		}
+		user := studentUser
+		if user == nil {
+			user = emailUser
+		}
+
+		if user == nil && !actor.IsAdmin {
+			reject("no account with this email or student ID; only a site administrator can create accounts")
+			continue
+		}
+		if user == nil {
+			user = &models.User{Email: entry.Email, FullName: entry.FullName, AcademicGroupID: &group.AcademicGroupID}
+			if entry.StudentID != "" {
+				studentID := entry.StudentID
+				user.StudentID = &studentID
+			}
+			item.Action = "created"
+			plan.Create = append(plan.Create, user)
+			plan.Join = append(plan.Join, user)
+			itemUsers[len(report.Items)] = user
+			report.Items = append(report.Items, item)
+			continue
+		}
+
+		if entry.StudentID != "" && user.StudentID != nil && *user.StudentID != entry.StudentID {
+			reject("the account " + user.Username + " has a different student ID")
+			continue
+		}
+		if banned[user.UserID] {
+			reject("user is banned from this group")
+			continue
+		}
+		if joined[user.UserID] {
+			reject("the file already has this account")
+			continue
+		}
+		joined[user.UserID] = true
+
+		item.Action = "matched"
+		if actor.IsAdmin {
+			plan.Enroll = append(plan.Enroll, user)
+		}
+		item.AlreadyMember = members[user.UserID]
+		switch {
+		case item.AlreadyMember:
+		case applicants[user.UserID]:
+			item.Joined = true
+			plan.Join = append(plan.Join, user)
+		default:
+			item.Reason = "existing account without an application; invite the student to join"
+		}
+		itemUsers[len(report.Items)] = user
+		report.Items = append(report.Items, item)
+	}
+
+	if err := s.pickUsernames(plan.Create); err != nil {
+		return dto.RosterImportReport{}, err
+	}
+	passwords := make(map[*models.User]string)
+	if !dryRun {
+		for _, user := range plan.Create {
+			password, err := utils.RandomCode(temporaryPasswordLength)
+			if err != nil {
+				return dto.RosterImportReport{}, err
+			}
+			hashed, err := utils.HashPassword(password)
+			if err != nil {
+				return dto.RosterImportReport{}, errors.New("failed to hash password")
+			}
+			user.HashPassword = hashed
+			passwords[user] = password
+		}
+		if err := s.repo.Apply(groupID, plan, actor.UserID); err != nil {
+			return dto.RosterImportReport{}, err
+		}
+	}
+
+	for i := range report.Items {
+		item := &report.Items[i]
+		if user, ok := itemUsers[i]; ok {
+			item.UserID = user.UserID
+			item.Username = user.Username
+			item.TemporaryPassword = passwords[user]
+		}
+		switch item.Action {
+		case "created":
+			report.Summary.Created++
+		case "matched":
+			report.Summary.Matched++
+		case "rejected":
+			report.Summary.Rejected++
+		}
+	}
+	report.Summary.Joined = len(plan.Join)
+
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"dry_run":  dryRun,
+		"created":  report.Summary.Created,
+		"matched":  report.Summary.Matched,
+		"rejected": report.Summary.Rejected,
+		"joined":   report.Summary.Joined,
+	}).Info("Roster imported")
+	if !dryRun {
+		s.audit.Record(groupID, actor.UserID, models.AuditRosterImport, "group", groupID, nil, report.Summary)
+	}
+	return report, nil
+}
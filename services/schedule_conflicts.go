@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"space/models"
+	"space/models/dto"
+	"space/utils"
+	"strings"
+	"time"
+)
+
+// ScheduleConflictError is returned when saving lessons would double-book a room,
+// a teacher or an academic group
+type ScheduleConflictError struct {
+	Conflicts []dto.ScheduleConflictDTO
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return "schedule conflict"
+}
+
+func normalizeName(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func sameName(a, b string) bool {
+	a = normalizeName(a)
+	return a != "" && a == normalizeName(b)
+}
+
+func slotKey(date time.Time, timeSlotID int32) string {
+	return fmt.Sprintf("%s|%d", date.Format(dto.DateLayout), timeSlotID)
+}
+
+func sameTeacher(a, b *models.Schedule) bool {
+	if a.TeacherID != nil && b.TeacherID != nil {
+		return *a.TeacherID == *b.TeacherID
+	}
+	return sameName(a.TeacherInitials, b.TeacherInitials)
+}
+
+func sameRoom(a, b *models.Schedule) bool {
+	if a.RoomID != nil && b.RoomID != nil {
+		return *a.RoomID == *b.RoomID
+	}
+	return sameName(utils.ClassroomKey(a.Classroom), utils.ClassroomKey(b.Classroom))
+}
+
+// lessonConflicts compares two lessons in the same slot. The same subject with the same teacher
+// in the same room is one joint lesson (a lecture for several groups, or a lesson mirrored into
+// several groups of one academic group) and is not a conflict.
+func lessonConflicts(a, b *models.Schedule) []dto.ScheduleConflictDTO {
+	if sameName(a.Subject.Name, b.Subject.Name) && sameTeacher(a, b) && sameRoom(a, b) {
+		return nil
+	}
+
+	base := dto.ScheduleConflictDTO{
+		Date:       a.Date.Format(dto.DateLayout),
+		TimeSlotID: a.TimeSlotID,
+		Lessons:    []dto.ConflictLessonDTO{dto.ToConflictLessonDTO(a), dto.ToConflictLessonDTO(b)},
+	}
+	var conflicts []dto.ScheduleConflictDTO
+	if a.GroupID == b.GroupID || a.Group.AcademicGroupID == b.Group.AcademicGroupID {
+		c := base
+		c.Type = "academic_group"
+		conflicts = append(conflicts, c)
+	}
+	if sameRoom(a, b) {
+		c := base
+		c.Type, c.Value = "room", a.Classroom
+		conflicts = append(conflicts, c)
+	}
+	if sameTeacher(a, b) {
+		c := base
+		c.Type, c.Value = "teacher", a.TeacherInitials
+		conflicts = append(conflicts, c)
+	}
+	return conflicts
+}
+
+// detectConflicts checks lessons about to be saved against the lessons already stored.
+// Candidates need Group and Subject filled in; a candidate with a ScheduleID is not compared
+// with itself, and one with a RuleID is not compared with lessons of the same rule, since
+// those are replaced. The result holds the conflicts of each candidate by index.
+func (s *ScheduleService) detectConflicts(candidates []models.Schedule) ([][]dto.ScheduleConflictDTO, error) {
+	result := make([][]dto.ScheduleConflictDTO, len(candidates))
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	seenDates := make(map[string]bool)
+	var dates []time.Time
+	for _, c := range candidates {
+		if key := c.Date.Format(dto.DateLayout); !seenDates[key] {
+			seenDates[key] = true
+			dates = append(dates, c.Date)
+		}
+	}
+	existing, err := s.repo.FindAtDates(dates)
+	if err != nil {
+		return nil, err
+	}
+	bySlot := make(map[string][]*models.Schedule)
+	for i := range existing {
+		key := slotKey(existing[i].Date, existing[i].TimeSlotID)
+		bySlot[key] = append(bySlot[key], &existing[i])
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		for _, other := range bySlot[slotKey(c.Date, c.TimeSlotID)] {
+			if c.ScheduleID != 0 && other.ScheduleID == c.ScheduleID {
+				continue
+			}
+			if c.RuleID != nil && other.RuleID != nil && *c.RuleID == *other.RuleID {
+				continue
+			}
+			result[i] = append(result[i], lessonConflicts(c, other)...)
+		}
+	}
+	return result, nil
+}
+
+// checkConflicts returns a ScheduleConflictError listing every conflict of the candidates
+func (s *ScheduleService) checkConflicts(candidates []models.Schedule) error {
+	perCandidate, err := s.detectConflicts(candidates)
+	if err != nil {
+		return err
+	}
+	var conflicts []dto.ScheduleConflictDTO
+	for _, c := range perCandidate {
+		conflicts = append(conflicts, c...)
+	}
+	if len(conflicts) > 0 {
+		return &ScheduleConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// GetConflicts lists double-bookings between from and to that involve a lesson of the user's
+// groups, or of one group when groupID is set
+func (s *ScheduleService) GetConflicts(username string, from, to time.Time, groupID *int32) ([]dto.ScheduleConflictDTO, error) {
+	if to.Before(from) || to.Sub(from) > maxScheduleRangeDays*24*time.Hour {
+		return nil, errors.New("invalid date range")
+	}
+
+	visible := make(map[int32]bool)
+	if groupID != nil {
+		if _, err := s.groupRepo.GetByID(*groupID); err != nil {
+			return nil, errors.New("group not found")
+		}
+		if _, err := s.requireMember(*groupID, username); err != nil {
+			return nil, err
+		}
+		visible[*groupID] = true
+	} else {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		groupUsers, err := s.groupUserRepo.FindByUserID(user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, gu := range groupUsers {
+			visible[gu.GroupID] = true
+		}
+	}
+
+	conflicts := []dto.ScheduleConflictDTO{}
+	if len(visible) == 0 {
+		return conflicts, nil
+	}
+
+	lessons, err := s.repo.FindByDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Lessons are ordered by date and slot, so each slot is a contiguous run
+	for start := 0; start < len(lessons); {
+		end := start + 1
+		for end < len(lessons) && slotKey(lessons[end].Date, lessons[end].TimeSlotID) == slotKey(lessons[start].Date, lessons[start].TimeSlotID) {
+			end++
+		}
+		for i := start; i < end; i++ {
+			for j := i + 1; j < end; j++ {
+				if !visible[lessons[i].GroupID] && !visible[lessons[j].GroupID] {
+					continue
+				}
+				conflicts = append(conflicts, lessonConflicts(&lessons[i], &lessons[j])...)
+			}
+		}
+		start = end
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const maxSemesterDays = 366
+
+type ScheduleRuleService struct {
+	repo            *repositories.ScheduleRuleRepository
+	groupRepo       *repositories.GroupRepository
+	scheduleService *ScheduleService
+}
+
+func NewScheduleRuleService(repo *repositories.ScheduleRuleRepository, groupRepo *repositories.GroupRepository, scheduleService *ScheduleService) *ScheduleRuleService {
+	return &ScheduleRuleService{
+		repo:            repo,
+		groupRepo:       groupRepo,
+		scheduleService: scheduleService,
+	}
+}
+
+// IsoWeekday returns 1 for Monday through 7 for Sunday
+func IsoWeekday(date time.Time) int32 {
+	return int32((int(date.Weekday())+6)%7 + 1)
+}
+
+// SemesterWeek returns the 1-based week number of date counted from the week containing semesterStart.
+// Odd weeks are the numerator, even weeks the denominator.
+func SemesterWeek(semesterStart, date time.Time) int {
+	firstMonday, _ := WeekBounds(semesterStart)
+	return int(date.Sub(firstMonday).Hours()/24)/7 + 1
+}
+
+// SemesterCalendar holds the university-wide semester start dates. Week parity is counted
+// from the configured start of the semester a date falls in, so every lesson agrees on which
+// week is the numerator; only dates before any configured start use the rule's or the
+// import's own start.
+type SemesterCalendar struct {
+	starts []time.Time
+}
+
+// NewSemesterCalendar returns a calendar with the given semester starts
+func NewSemesterCalendar(starts ...time.Time) SemesterCalendar {
+	sorted := append([]time.Time(nil), starts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	return SemesterCalendar{starts: sorted}
+}
+
+// ParseSemesterStarts parses a comma-separated list of dates such as "2025-09-01,2026-02-09"
+func ParseSemesterStarts(value string) ([]time.Time, error) {
+	var starts []time.Time
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		start, err := time.Parse(dto.DateLayout, part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semester start %q", part)
+		}
+		starts = append(starts, start)
+	}
+	return starts, nil
+}
+
+// SemesterCalendarFromEnv reads SEMESTER_STARTS (see docker-compose.yml)
+func SemesterCalendarFromEnv() (SemesterCalendar, error) {
+	starts, err := ParseSemesterStarts(os.Getenv("SEMESTER_STARTS"))
+	if err != nil {
+		return SemesterCalendar{}, err
+	}
+	return NewSemesterCalendar(starts...), nil
+}
+
+// Start returns the start of the semester containing date: the latest configured start on or
+// before it, or fallback when there is none
+func (c SemesterCalendar) Start(date, fallback time.Time) time.Time {
+	start := fallback
+	for _, s := range c.starts {
+		if s.After(date) {
+			break
+		}
+		start = s
+	}
+	return start
+}
+
+// Week returns the semester week number of date, counting from fallback when no configured
+// semester start applies
+func (c SemesterCalendar) Week(date, fallback time.Time) int {
+	return SemesterWeek(c.Start(date, fallback), date)
+}
+
+func matchesParity(parity string, week int) bool {
+	switch parity {
+	case "odd":
+		return week%2 == 1
+	case "even":
+		return week%2 == 0
+	}
+	return true
+}
+
+// expandRule lists every date the rule produces a lesson on, skipping exceptions
+func expandRule(rule *models.ScheduleRule, semesters SemesterCalendar) []time.Time {
+	skipped := make(map[string]bool, len(rule.Exceptions))
+	for _, e := range rule.Exceptions {
+		skipped[e.Date.Format(dto.DateLayout)] = true
+	}
+
+	var dates []time.Time
+	for d := rule.SemesterStart; !d.After(rule.SemesterEnd); d = d.AddDate(0, 0, 1) {
+		if IsoWeekday(d) != rule.Weekday {
+			continue
+		}
+		if !matchesParity(rule.WeekParity, semesters.Week(d, rule.SemesterStart)) {
+			continue
+		}
+		if skipped[d.Format(dto.DateLayout)] {
+			continue
+		}
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+func lessonFromRule(rule *models.ScheduleRule, date time.Time) models.Schedule {
+	return models.Schedule{
+		GroupID:         rule.GroupID,
+		SubjectID:       rule.SubjectID,
+		TimeSlotID:      rule.TimeSlotID,
+		Date:            date,
+		TeacherInitials: rule.TeacherInitials,
+		TeacherID:       rule.TeacherID,
+		Classroom:       rule.Classroom,
+		RoomID:          rule.RoomID,
+	}
+}
+
+func ruleLessons(rule *models.ScheduleRule, semesters SemesterCalendar) ([]models.Schedule, []string) {
+	dates := expandRule(rule, semesters)
+	lessons := make([]models.Schedule, len(dates))
+	lessonDates := make([]string, len(dates))
+	for i, d := range dates {
+		lessons[i] = lessonFromRule(rule, d)
+		lessonDates[i] = d.Format(dto.DateLayout)
+	}
+	return lessons, lessonDates
+}
+
+// withAssociations prepares generated lessons for the conflict check without touching the
+// rows that will be saved
+func withAssociations(lessons []models.Schedule, ruleID *int32, group *models.Group, subject *models.Subject) []models.Schedule {
+	candidates := make([]models.Schedule, len(lessons))
+	for i, lesson := range lessons {
+		lesson.RuleID = ruleID
+		lesson.Group = *group
+		lesson.Subject = *subject
+		candidates[i] = lesson
+	}
+	return candidates
+}
+
+func validateRule(rule *models.ScheduleRule) error {
+	if rule.Weekday < 1 || rule.Weekday > 7 {
+		return errors.New("invalid weekday")
+	}
+	switch rule.WeekParity {
+	case "all", "odd", "even":
+	default:
+		return errors.New("invalid week parity")
+	}
+	if rule.SemesterEnd.Before(rule.SemesterStart) {
+		return errors.New("invalid semester range")
+	}
+	if rule.SemesterEnd.Sub(rule.SemesterStart).Hours()/24 > maxSemesterDays {
+		return errors.New("semester is too long")
+	}
+	return nil
+}
+
+func (s *ScheduleRuleService) GetGroupRules(username string, groupID int32) ([]dto.ScheduleRuleDTO, error) {
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return nil, errors.New("group not found")
+	}
+	if _, err := s.scheduleService.requireMember(groupID, username); err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.FindByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	ruleDTOs := make([]dto.ScheduleRuleDTO, len(rules))
+	for i := range rules {
+		_, lessonDates := ruleLessons(&rules[i], s.scheduleService.semesters)
+		ruleDTOs[i] = dto.ToScheduleRuleDTO(&rules[i], lessonDates)
+	}
+	return ruleDTOs, nil
+}
+
+func (s *ScheduleRuleService) CreateRule(username string, groupID int32, req dto.ScheduleRuleRequest) (dto.ScheduleRuleDTO, error) {
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, errors.New("group not found")
+	}
+	user, err := s.scheduleService.requireModerator(groupID, username)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	start, err := ParseDate(req.SemesterStart)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	end, err := ParseDate(req.SemesterEnd)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	teacherID, teacherInitials, err := s.scheduleService.resolveTeacher(req.TeacherID, req.TeacherInitials)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	roomID, classroom, err := s.scheduleService.resolveRoom(req.RoomID, req.Classroom)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	rule := &models.ScheduleRule{
+		GroupID:         groupID,
+		SubjectID:       req.SubjectID,
+		TimeSlotID:      req.TimeSlotID,
+		Weekday:         req.Weekday,
+		WeekParity:      req.WeekParity,
+		SemesterStart:   start,
+		SemesterEnd:     end,
+		TeacherInitials: teacherInitials,
+		TeacherID:       teacherID,
+		Classroom:       classroom,
+		RoomID:          roomID,
+		CreatedBy:       user.UserID,
+	}
+	if rule.WeekParity == "" {
+		rule.WeekParity = "all"
+	}
+	if err := validateRule(rule); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	subject, err := s.scheduleService.validateEntry(group, rule.SubjectID, rule.TimeSlotID)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	lessons, _ := ruleLessons(rule, s.scheduleService.semesters)
+	if err := s.scheduleService.checkConflicts(withAssociations(lessons, nil, group, subject)); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	if err := s.repo.CreateWithLessons(rule, lessons); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to create schedule rule")
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"group_id": groupID,
+		"rule_id":  rule.ID,
+		"lessons":  len(lessons),
+	}).Info("Schedule rule created")
+
+	return s.loadRule(rule.ID)
+}
+
+func (s *ScheduleRuleService) UpdateRule(username string, id int32, req dto.UpdateScheduleRuleRequest) (dto.ScheduleRuleDTO, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, errors.New("schedule rule not found")
+	}
+	if _, err := s.scheduleService.requireModerator(rule.GroupID, username); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	if req.SubjectID != nil {
+		rule.SubjectID = *req.SubjectID
+	}
+	if req.TimeSlotID != nil {
+		rule.TimeSlotID = *req.TimeSlotID
+	}
+	if req.Weekday != nil {
+		rule.Weekday = *req.Weekday
+	}
+	if req.WeekParity != nil {
+		rule.WeekParity = *req.WeekParity
+	}
+	if req.SemesterStart != nil {
+		start, err := ParseDate(*req.SemesterStart)
+		if err != nil {
+			return dto.ScheduleRuleDTO{}, err
+		}
+		rule.SemesterStart = start
+	}
+	if req.SemesterEnd != nil {
+		end, err := ParseDate(*req.SemesterEnd)
+		if err != nil {
+			return dto.ScheduleRuleDTO{}, err
+		}
+		rule.SemesterEnd = end
+	}
+	if req.TeacherID != nil || req.TeacherInitials != nil {
+		initials := rule.TeacherInitials
+		if req.TeacherInitials != nil {
+			initials = *req.TeacherInitials
+		}
+		teacherID, teacherInitials, err := s.scheduleService.resolveTeacher(req.TeacherID, initials)
+		if err != nil {
+			return dto.ScheduleRuleDTO{}, err
+		}
+		rule.TeacherID, rule.TeacherInitials = teacherID, teacherInitials
+	}
+	if req.RoomID != nil || req.Classroom != nil {
+		classroom := rule.Classroom
+		if req.Classroom != nil {
+			classroom = *req.Classroom
+		}
+		roomID, classroom, err := s.scheduleService.resolveRoom(req.RoomID, classroom)
+		if err != nil {
+			return dto.ScheduleRuleDTO{}, err
+		}
+		rule.RoomID, rule.Classroom = roomID, classroom
+	}
+	if err := validateRule(rule); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	subject, err := s.scheduleService.validateEntry(&rule.Group, rule.SubjectID, rule.TimeSlotID)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	// Lessons of the rule are regenerated, so manual edits to them are overwritten
+	lessons, _ := ruleLessons(rule, s.scheduleService.semesters)
+	if err := s.scheduleService.checkConflicts(withAssociations(lessons, &rule.ID, &rule.Group, subject)); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	if err := s.repo.ReplaceLessons(rule, lessons); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"rule_id": id,
+		}).Error("Failed to update schedule rule")
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	return s.loadRule(id)
+}
+
+func (s *ScheduleRuleService) DeleteRule(username string, id int32) error {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("schedule rule not found")
+	}
+	if _, err := s.scheduleService.requireModerator(rule.GroupID, username); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"rule_id":  id,
+		"group_id": rule.GroupID,
+	}).Info("Schedule rule deleted")
+	return nil
+}
+
+// AddException cancels the lesson of the rule on a single date
+func (s *ScheduleRuleService) AddException(username string, id int32, req dto.RuleExceptionRequest) (dto.ScheduleRuleDTO, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, errors.New("schedule rule not found")
+	}
+	if _, err := s.scheduleService.requireModerator(rule.GroupID, username); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	date, err := ParseDate(req.Date)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	if !s.occursOn(rule, date) {
+		return dto.ScheduleRuleDTO{}, errors.New("rule has no lesson on this date")
+	}
+
+	exception := &models.ScheduleRuleException{RuleID: id, Date: date, Reason: req.Reason}
+	if err := s.repo.AddException(exception); err != nil {
+		if errors.Is(err, repositories.ErrExceptionExists) {
+			return dto.ScheduleRuleDTO{}, errors.New("exception already exists")
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"rule_id": id,
+			"date":    req.Date,
+		}).Error("Failed to add schedule rule exception")
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	return s.loadRule(id)
+}
+
+// RemoveException restores a previously cancelled lesson
+func (s *ScheduleRuleService) RemoveException(username string, id int32, dateValue string) (dto.ScheduleRuleDTO, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, errors.New("schedule rule not found")
+	}
+	if _, err := s.scheduleService.requireModerator(rule.GroupID, username); err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	date, err := ParseDate(dateValue)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	var lesson *models.Schedule
+	if s.occursOn(rule, date) {
+		l := lessonFromRule(rule, date)
+		lesson = &l
+		if err := s.scheduleService.checkConflicts(withAssociations([]models.Schedule{l}, &rule.ID, &rule.Group, &rule.Subject)); err != nil {
+			return dto.ScheduleRuleDTO{}, err
+		}
+	}
+	if err := s.repo.RemoveException(id, date, lesson); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ScheduleRuleDTO{}, errors.New("exception not found")
+		}
+		return dto.ScheduleRuleDTO{}, err
+	}
+
+	return s.loadRule(id)
+}
+
+// occursOn reports whether the rule pattern covers date, ignoring exceptions
+func (s *ScheduleRuleService) occursOn(rule *models.ScheduleRule, date time.Time) bool {
+	if date.Before(rule.SemesterStart) || date.After(rule.SemesterEnd) {
+		return false
+	}
+	return IsoWeekday(date) == rule.Weekday && matchesParity(rule.WeekParity, s.scheduleService.semesters.Week(date, rule.SemesterStart))
+}
+
+func (s *ScheduleRuleService) loadRule(id int32) (dto.ScheduleRuleDTO, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleRuleDTO{}, err
+	}
+	_, lessonDates := ruleLessons(rule, s.scheduleService.semesters)
+	return dto.ToScheduleRuleDTO(rule, lessonDates), nil
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxScheduleRangeDays = 62
+
+type ScheduleService struct {
+	repo          *repositories.ScheduleRepository
+	groupRepo     *repositories.GroupRepository
+	groupUserRepo *repositories.GroupUserRepository
+	subjectRepo   *repositories.SubjectRepository
+	teacherRepo   *repositories.TeacherRepository
+	roomRepo      *repositories.RoomRepository
+	userRepo      repositories.UserRepository
+	semesters     SemesterCalendar
+}
+
+func NewScheduleService(repo *repositories.ScheduleRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, subjectRepo *repositories.SubjectRepository, teacherRepo *repositories.TeacherRepository, roomRepo *repositories.RoomRepository, userRepo repositories.UserRepository, semesters SemesterCalendar) *ScheduleService {
+	return &ScheduleService{
+		repo:          repo,
+		groupRepo:     groupRepo,
+		groupUserRepo: groupUserRepo,
+		subjectRepo:   subjectRepo,
+		teacherRepo:   teacherRepo,
+		roomRepo:      roomRepo,
+		userRepo:      userRepo,
+		semesters:     semesters,
+	}
+}
+
+// ParseDate parses a YYYY-MM-DD date
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dto.DateLayout, value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date")
+	}
+	return date, nil
+}
+
+// WeekBounds returns Monday and Sunday of the week containing date
+func WeekBounds(date time.Time) (time.Time, time.Time) {
+	offset := (int(date.Weekday()) + 6) % 7
+	monday := date.AddDate(0, 0, -offset)
+	return monday, monday.AddDate(0, 0, 6)
+}
+
+func parseSlotTime(value string) (time.Time, error) {
+	if t, err := time.Parse("15:04", value); err == nil {
+		return t, nil
+	}
+	return time.Parse("15:04:05", value)
+}
+
+func validateSlotTimes(start, end string) error {
+	startTime, err := parseSlotTime(start)
+	if err != nil {
+		return errors.New("invalid time range")
+	}
+	endTime, err := parseSlotTime(end)
+	if err != nil || !endTime.After(startTime) {
+		return errors.New("invalid time range")
+	}
+	return nil
+}
+
+// canManageSharedData: time slots, teachers and rooms are shared by all groups, so only site
+// administrators may edit them. Group roles don't count, anyone can create a group.
+func (s *ScheduleService) canManageSharedData(username string) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.IsAdmin {
+		return errors.New("access denied: administrator role required")
+	}
+	return nil
+}
+
+func (s *ScheduleService) requireMember(groupID int32, username string) (*models.User, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	isMember, err := s.groupUserRepo.IsMember(groupID, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("access denied: group membership required")
+	}
+	return user, nil
+}
+
+func (s *ScheduleService) requireModerator(groupID int32, username string) (*models.User, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(groupID, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthorized {
+		return nil, errors.New("access denied: admin or moderator role required")
+	}
+	return user, nil
+}
+
+// resolveTeacher links a lesson to a teacher: an explicit teacherID wins and its initials become the
+// display text, otherwise the initials are matched against the teachers table when unambiguous
+func (s *ScheduleService) resolveTeacher(teacherID *int32, initials string) (*int32, string, error) {
+	if teacherID != nil {
+		teacher, err := s.teacherRepo.GetByID(*teacherID)
+		if err != nil {
+			return nil, "", errors.New("teacher not found")
+		}
+		return &teacher.ID, teacher.Initials, nil
+	}
+	if strings.TrimSpace(initials) == "" {
+		return nil, initials, nil
+	}
+	teachers, err := s.teacherRepo.FindByInitials(initials)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(teachers) == 1 {
+		return &teachers[0].ID, initials, nil
+	}
+	return nil, initials, nil
+}
+
+// resolveRoom links a lesson to a registered room: an explicit roomID wins and its label becomes the
+// classroom text, otherwise the classroom text is matched against room codes
+func (s *ScheduleService) resolveRoom(roomID *int32, classroom string) (*int32, string, error) {
+	if roomID != nil {
+		room, err := s.roomRepo.GetByID(*roomID)
+		if err != nil {
+			return nil, "", errors.New("room not found")
+		}
+		return &room.ID, utils.RoomLabel(room.Building, room.Number), nil
+	}
+	if strings.TrimSpace(classroom) == "" {
+		return nil, classroom, nil
+	}
+	room, err := s.roomRepo.GetByCode(utils.ClassroomKey(classroom))
+	if err != nil {
+		return nil, classroom, nil
+	}
+	return &room.ID, classroom, nil
+}
+
+func (s *ScheduleService) GetTimeSlots() ([]dto.TimeSlotDTO, error) {
+	slots, err := s.repo.FindTimeSlots()
+	if err != nil {
+		return nil, err
+	}
+	slotDTOs := make([]dto.TimeSlotDTO, len(slots))
+	for i := range slots {
+		slotDTOs[i] = dto.ToTimeSlotDTO(&slots[i])
+	}
+	return slotDTOs, nil
+}
+
+func (s *ScheduleService) CreateTimeSlot(username string, req dto.TimeSlotRequest) (dto.TimeSlotDTO, error) {
+	if err := s.canManageSharedData(username); err != nil {
+		return dto.TimeSlotDTO{}, err
+	}
+	if err := validateSlotTimes(req.StartTime, req.EndTime); err != nil {
+		return dto.TimeSlotDTO{}, err
+	}
+	slot := &models.TimeSlot{SlotNumber: req.SlotNumber, StartTime: req.StartTime, EndTime: req.EndTime}
+	if err := s.repo.CreateTimeSlot(slot); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"slot_number": req.SlotNumber,
+		}).Error("Failed to create time slot")
+		return dto.TimeSlotDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"slot_id":     slot.SlotID,
+		"slot_number": slot.SlotNumber,
+	}).Info("Time slot created")
+	return dto.ToTimeSlotDTO(slot), nil
+}
+
+func (s *ScheduleService) UpdateTimeSlot(username string, id int32, req dto.TimeSlotRequest) (dto.TimeSlotDTO, error) {
+	if err := s.canManageSharedData(username); err != nil {
+		return dto.TimeSlotDTO{}, err
+	}
+	slot, err := s.repo.GetTimeSlotByID(id)
+	if err != nil {
+		return dto.TimeSlotDTO{}, errors.New("time slot not found")
+	}
+	if err := validateSlotTimes(req.StartTime, req.EndTime); err != nil {
+		return dto.TimeSlotDTO{}, err
+	}
+	slot.SlotNumber = req.SlotNumber
+	slot.StartTime = req.StartTime
+	slot.EndTime = req.EndTime
+	if err := s.repo.UpdateTimeSlot(slot); err != nil {
+		return dto.TimeSlotDTO{}, err
+	}
+	return dto.ToTimeSlotDTO(slot), nil
+}
+
+func (s *ScheduleService) DeleteTimeSlot(username string, id int32) error {
+	if err := s.canManageSharedData(username); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetTimeSlotByID(id); err != nil {
+		return errors.New("time slot not found")
+	}
+	used, err := s.repo.IsTimeSlotUsed(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("time slot is in use")
+	}
+	return s.repo.DeleteTimeSlot(id)
+}
+
+func buildScheduleResponse(from, to time.Time, schedules []models.Schedule) dto.ScheduleResponse {
+	byDate := make(map[string][]dto.ScheduleEntryDTO)
+	for i := range schedules {
+		entry := dto.ToScheduleEntryDTO(&schedules[i])
+		byDate[entry.Date] = append(byDate[entry.Date], entry)
+	}
+
+	response := dto.ScheduleResponse{
+		From: from.Format(dto.DateLayout),
+		To:   to.Format(dto.DateLayout),
+		Days: []dto.ScheduleDayDTO{},
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dto.DateLayout)
+		entries := byDate[date]
+		if entries == nil {
+			entries = []dto.ScheduleEntryDTO{}
+		}
+		response.Days = append(response.Days, dto.ScheduleDayDTO{Date: date, Entries: entries})
+	}
+	return response
+}
+
+// GetUserSchedule returns the day or week containing date across all groups the user belongs to
+func (s *ScheduleService) GetUserSchedule(username string, date time.Time, period string) (dto.ScheduleResponse, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.ScheduleResponse{}, errors.New("user not found")
+	}
+
+	from, to := date, date
+	if period == "week" {
+		from, to = WeekBounds(date)
+	}
+
+	groupUsers, err := s.groupUserRepo.FindByUserID(user.UserID)
+	if err != nil {
+		return dto.ScheduleResponse{}, err
+	}
+	var groupIDs []int32
+	for _, gu := range groupUsers {
+		groupIDs = append(groupIDs, gu.GroupID)
+	}
+
+	var schedules []models.Schedule
+	if len(groupIDs) > 0 {
+		schedules, err = s.repo.FindByGroupIDs(groupIDs, from, to)
+		if err != nil {
+			return dto.ScheduleResponse{}, err
+		}
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"username": username,
+		"from":     from.Format(dto.DateLayout),
+		"to":       to.Format(dto.DateLayout),
+		"count":    len(schedules),
+	}).Debug("Fetched user schedule")
+	return buildScheduleResponse(from, to, schedules), nil
+}
+
+func (s *ScheduleService) GetGroupSchedule(username string, groupID int32, from, to time.Time) (dto.ScheduleResponse, error) {
+	if to.Before(from) || to.Sub(from) > maxScheduleRangeDays*24*time.Hour {
+		return dto.ScheduleResponse{}, errors.New("invalid date range")
+	}
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return dto.ScheduleResponse{}, errors.New("group not found")
+	}
+	if _, err := s.requireMember(groupID, username); err != nil {
+		return dto.ScheduleResponse{}, err
+	}
+
+	schedules, err := s.repo.FindByGroupIDs([]int32{groupID}, from, to)
+	if err != nil {
+		return dto.ScheduleResponse{}, err
+	}
+	return buildScheduleResponse(from, to, schedules), nil
+}
+
+func (s *ScheduleService) GetEntry(username string, id int32) (dto.ScheduleEntryDTO, error) {
+	schedule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, errors.New("schedule entry not found")
+	}
+	if _, err := s.requireMember(schedule.GroupID, username); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	return dto.ToScheduleEntryDTO(schedule), nil
+}
+
+// validateEntry checks that the subject belongs to the group's academic group and the slot exists
+func (s *ScheduleService) validateEntry(group *models.Group, subjectID, timeSlotID int32) (*models.Subject, error) {
+	subject, err := s.subjectRepo.GetByID(subjectID)
+	if err != nil {
+		return nil, errors.New("subject not found")
+	}
+	if subject.AcademicGroupID != group.AcademicGroupID {
+		return nil, errors.New("subject does not belong to the group's academic group")
+	}
+	if _, err := s.repo.GetTimeSlotByID(timeSlotID); err != nil {
+		return nil, errors.New("time slot not found")
+	}
+	return subject, nil
+}
+
+func (s *ScheduleService) CreateEntry(username string, groupID int32, req dto.CreateScheduleRequest) (dto.ScheduleEntryDTO, error) {
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, errors.New("group not found")
+	}
+	if _, err := s.requireModerator(groupID, username); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	date, err := ParseDate(req.Date)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	subject, err := s.validateEntry(group, req.SubjectID, req.TimeSlotID)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	teacherID, teacherInitials, err := s.resolveTeacher(req.TeacherID, req.TeacherInitials)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	roomID, classroom, err := s.resolveRoom(req.RoomID, req.Classroom)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	schedule := &models.Schedule{
+		GroupID:         groupID,
+		SubjectID:       req.SubjectID,
+		TimeSlotID:      req.TimeSlotID,
+		Date:            date,
+		TeacherInitials: teacherInitials,
+		TeacherID:       teacherID,
+		Classroom:       classroom,
+		RoomID:          roomID,
+		Group:           *group,
+		Subject:         *subject,
+	}
+	if err := s.checkConflicts([]models.Schedule{*schedule}); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	if err := s.repo.Create(schedule); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"group_id":    groupID,
+		"schedule_id": schedule.ScheduleID,
+		"date":        req.Date,
+	}).Info("Schedule entry created")
+
+	created, err := s.repo.GetByID(schedule.ScheduleID)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	return dto.ToScheduleEntryDTO(created), nil
+}
+
+func (s *ScheduleService) UpdateEntry(username string, id int32, req dto.UpdateScheduleRequest) (dto.ScheduleEntryDTO, error) {
+	schedule, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, errors.New("schedule entry not found")
+	}
+	if _, err := s.requireModerator(schedule.GroupID, username); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	if req.SubjectID != nil {
+		schedule.SubjectID = *req.SubjectID
+	}
+	if req.TimeSlotID != nil {
+		schedule.TimeSlotID = *req.TimeSlotID
+	}
+	if req.Date != nil {
+		date, err := ParseDate(*req.Date)
+		if err != nil {
+			return dto.ScheduleEntryDTO{}, err
+		}
+		schedule.Date = date
+	}
+	if req.TeacherID != nil || req.TeacherInitials != nil {
+		initials := schedule.TeacherInitials
+		if req.TeacherInitials != nil {
+			initials = *req.TeacherInitials
+		}
+		teacherID, teacherInitials, err := s.resolveTeacher(req.TeacherID, initials)
+		if err != nil {
+			return dto.ScheduleEntryDTO{}, err
+		}
+		schedule.TeacherID, schedule.TeacherInitials = teacherID, teacherInitials
+	}
+	if req.RoomID != nil || req.Classroom != nil {
+		classroom := schedule.Classroom
+		if req.Classroom != nil {
+			classroom = *req.Classroom
+		}
+		roomID, classroom, err := s.resolveRoom(req.RoomID, classroom)
+		if err != nil {
+			return dto.ScheduleEntryDTO{}, err
+		}
+		schedule.RoomID, schedule.Classroom = roomID, classroom
+	}
+	subject, err := s.validateEntry(&schedule.Group, schedule.SubjectID, schedule.TimeSlotID)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	schedule.Subject = *subject
+	if err := s.checkConflicts([]models.Schedule{*schedule}); err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	if err := s.repo.Update(schedule); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":       err,
+			"schedule_id": id,
+		}).Error("Failed to update schedule entry")
+		return dto.ScheduleEntryDTO{}, err
+	}
+
+	updated, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.ScheduleEntryDTO{}, err
+	}
+	return dto.ToScheduleEntryDTO(updated), nil
+}
+
+func (s *ScheduleService) DeleteEntry(username string, id int32) error {
+	schedule, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("schedule entry not found")
+	}
+	if _, err := s.requireModerator(schedule.GroupID, username); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":    username,
+		"schedule_id": id,
+		"group_id":    schedule.GroupID,
+	}).Info("Schedule entry deleted")
+	return nil
+}
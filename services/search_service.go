@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"space/models/dto"
+	"space/repositories"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 200
+)
+
+// searchPaths maps result types to the endpoint returning the found item
+var searchPaths = map[string]string{
+	"task":     "/api/tasks/%d",
+	"material": "/api/materials/%d",
+	"subject":  "/api/subjects/%d",
+	"group":    "/api/groups/%d",
+}
+
+type SearchService struct {
+	repo     *repositories.SearchRepository
+	userRepo repositories.UserRepository
+}
+
+func NewSearchService(repo *repositories.SearchRepository, userRepo repositories.UserRepository) *SearchService {
+	return &SearchService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// highlightSnippet escapes the snippet and turns the database match markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	return strings.NewReplacer(
+		repositories.SnippetMatchStart, "<mark>",
+		repositories.SnippetMatchEnd, "</mark>",
+	).Replace(snippet)
+}
+
+// Search finds tasks, materials, subjects and groups visible to the user. types limits the
+// results to some of task, material, subject and group.
+func (s *SearchService) Search(username, query string, types []string, page, pageSize int) (dto.SearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minSearchQueryLength {
+		return dto.SearchResponse{}, errors.New("search query is too short")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return dto.SearchResponse{}, errors.New("search query is too long")
+	}
+	for _, t := range types {
+		if _, ok := searchPaths[t]; !ok {
+			return dto.SearchResponse{}, errors.New("invalid search type")
+		}
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.SearchResponse{}, errors.New("user not found")
+	}
+
+	hits, facets, err := s.repo.Search(user.UserID, query, types, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return dto.SearchResponse{}, err
+	}
+
+	facetCounts := make(map[string]int64, len(searchPaths))
+	for t := range searchPaths {
+		facetCounts[t] = 0
+	}
+	var total int64
+	for _, f := range facets {
+		facetCounts[f.Type] = f.Count
+	}
+	if len(types) == 0 {
+		for _, count := range facetCounts {
+			total += count
+		}
+	} else {
+		for _, t := range types {
+			total += facetCounts[t]
+		}
+	}
+
+	results := make([]dto.SearchResultDTO, len(hits))
+	for i, hit := range hits {
+		results[i] = dto.SearchResultDTO{
+			Type:      hit.Type,
+			ID:        hit.ID,
+			Title:     hit.Title,
+			Snippet:   highlightSnippet(hit.Snippet),
+			GroupID:   hit.GroupID,
+			SubjectID: hit.SubjectID,
+			Path:      fmt.Sprintf(searchPaths[hit.Type], hit.ID),
+			Rank:      hit.Rank,
+			CreatedAt: hit.CreatedAt,
+		}
+	}
+
+	return dto.SearchResponse{
+		Query:   query,
+		Results: results,
+		Facets:  facetCounts,
+		Pagination: dto.PaginationMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+			Pages:    (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	}, nil
+}
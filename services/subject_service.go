@@ -22,15 +22,28 @@ type SubjectService struct {
 	subjectRepo *repositories.SubjectRepository
 	groupRepo   *repositories.GroupRepository
 	userRepo    repositories.UserRepository
+	teacherRepo *repositories.TeacherRepository
 }
 
-func NewSubjectService(subjectRepo *repositories.SubjectRepository, groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository) *SubjectService {
+func NewSubjectService(subjectRepo *repositories.SubjectRepository, groupRepo *repositories.GroupRepository, userRepo repositories.UserRepository, teacherRepo *repositories.TeacherRepository) *SubjectService {
 	return &SubjectService{
 		subjectRepo: subjectRepo,
 		groupRepo:   groupRepo,
 		userRepo:    userRepo,
+		teacherRepo: teacherRepo,
 	}
 }
+
+// checkTeacher makes sure the lead teacher exists before the foreign key would reject it
+func (s *SubjectService) checkTeacher(subject *models.Subject) error {
+	if subject.TeacherID == nil {
+		return nil
+	}
+	if _, err := s.teacherRepo.GetByID(*subject.TeacherID); err != nil {
+		return errors.New("teacher not found")
+	}
+	return nil
+}
 func (s *SubjectService) GetSubjectByID(id int32) (*models.Subject, error) {
 	return s.subjectRepo.GetByID(id)
 }
@@ -39,6 +52,9 @@ func (s *SubjectService) CreateSubject(subject *models.Subject) error {
 	if subject.Name == "" || subject.AcademicGroupID == 0 {
 		return errors.New("name and group_id are required")
 	}
+	if err := s.checkTeacher(subject); err != nil {
+		return err
+	}
 	return s.subjectRepo.Create(subject)
 }
 
@@ -46,6 +62,9 @@ func (s *SubjectService) UpdateSubject(subject *models.Subject) error {
 	if subject.Name == "" {
 		return errors.New("name is required")
 	}
+	if err := s.checkTeacher(subject); err != nil {
+		return err
+	}
 	return s.subjectRepo.Update(subject)
 }
 
@@ -11,11 +11,14 @@ import (
 )
 
 type TaskService struct {
-	taskRepo *repositories.TaskRepository
+	taskRepo       *repositories.TaskRepository
+	verifyRepo     *repositories.TaskVerificationRepository
+	reputationRepo *repositories.ReputationRepository
+	audit          *AuditService
 }
 
-func NewTaskService(taskRepo *repositories.TaskRepository) *TaskService {
-	return &TaskService{taskRepo}
+func NewTaskService(taskRepo *repositories.TaskRepository, verifyRepo *repositories.TaskVerificationRepository, reputationRepo *repositories.ReputationRepository, audit *AuditService) *TaskService {
+	return &TaskService{taskRepo, verifyRepo, reputationRepo, audit}
 }
 
 func (s *TaskService) UpdateTask(task *models.Task) error {
@@ -24,13 +27,14 @@ func (s *TaskService) UpdateTask(task *models.Task) error {
 }
 func (s *TaskService) CreateTask(groupID, userID int32, title, description string, deadline *time.Time, subjectID *int32) error {
 	task := &models.Task{
-		GroupID:     groupID,
-		UserID:      userID,
-		SubjectID:   subjectID,
-		Title:       title,
-		Description: description,
-		IsVerified:  false,
-		Deadline:    deadline,
+		GroupID:            groupID,
+		UserID:             userID,
+		SubjectID:          subjectID,
+		Title:              title,
+		Description:        description,
+		IsVerified:         false,
+		VerificationStatus: models.TaskPending,
+		Deadline:           deadline,
 	}
 	if err := s.taskRepo.Create(task); err != nil {
 		return err
@@ -41,8 +45,47 @@ func (s *TaskService) CreateTask(groupID, userID int32, title, description strin
 		"user_id":    userID,
 		"subject_id": subjectID,
 	}).Info("Task created successfully")
+	s.autoVerify(task)
 	return nil
 }
+
+// autoVerify verifies a new task right away when its author's reputation in the group reaches
+// the group's auto-verify threshold. Failures are logged; the task just stays pending.
+func (s *TaskService) autoVerify(task *models.Task) {
+	policy, err := s.verifyRepo.GetPolicy(task.GroupID)
+	if err != nil || policy.AutoVerifyReputation <= 0 {
+		return
+	}
+	reputation, err := s.reputationRepo.Get(task.GroupID, task.UserID)
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": task.ID,
+		}).Error("Failed to check author reputation")
+		return
+	}
+	if reputation.Score < policy.AutoVerifyReputation {
+		return
+	}
+	changed, err := s.verifyRepo.AutoVerify(task)
+	if err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": task.ID,
+		}).Error("Failed to auto-verify task")
+		return
+	}
+	if changed {
+		utils.Logger.WithFields(logrus.Fields{
+			"task_id":    task.ID,
+			"user_id":    task.UserID,
+			"reputation": reputation.Score,
+		}).Info("Task auto-verified by author reputation")
+		s.audit.Record(task.GroupID, task.UserID, models.AuditTaskVerify, "task", task.ID,
+			map[string]interface{}{"status": models.TaskPending},
+			map[string]interface{}{"status": models.TaskVerified, "method": models.VerifyByReputation, "reputation": reputation.Score})
+	}
+}
 func (s *TaskService) OldNoPagGetGroupTasks(groupID int32) ([]dto.TaskDTO, error) {
 	tasks, err := s.taskRepo.OldNoPagFindByGroupID(groupID)
 	if err != nil {
@@ -59,14 +102,33 @@ func (s *TaskService) OldNoPagGetGroupTasks(groupID int32) ([]dto.TaskDTO, error
 	return taskDTOs, nil
 }
 
-func (s *TaskService) VerifyTask(taskID int32, isVerified bool) error {
-	if err := s.taskRepo.UpdateVerificationStatus(taskID, isVerified); err != nil {
+// VerifyTask marks the task verified or rejected on behalf of actorID, who must already have
+// been checked to be an admin or moderator of the task's group. The decision overrides any
+// vote, in either verification mode.
+func (s *TaskService) VerifyTask(taskID int32, isVerified bool, actorID int32) error {
+	task, err := s.taskRepo.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	previous := task.VerificationStatus
+	status := models.TaskRejected
+	if isVerified {
+		status = models.TaskVerified
+	}
+	changed, err := s.verifyRepo.Override(task, status, actorID)
+	if err != nil {
 		return err
 	}
 	utils.Logger.WithFields(logrus.Fields{
-		"task_id":     taskID,
-		"is_verified": isVerified,
+		"task_id":  taskID,
+		"status":   status,
+		"actor_id": actorID,
 	}).Info("Task verification status updated")
+	if changed {
+		s.audit.Record(task.GroupID, actorID, models.AuditTaskVerify, "task", taskID,
+			map[string]interface{}{"status": previous},
+			map[string]interface{}{"status": status, "method": models.VerifyByModerator})
+	}
 	return nil
 }
 func (s *TaskService) GetTaskByID(taskID int32) (*models.Task, error) {
@@ -112,13 +174,14 @@ func (s *TaskService) GetTasksByGroupIDs(groupIDs []int32, page, pageSize int) (
 			}
 		}
 		taskDTOs = append(taskDTOs, dto.TaskDetailDTO{
-			ID:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			IsVerified:  task.IsVerified,
-			Deadline:    task.Deadline,
-			CreatedAt:   task.CreatedAt,
-			UpdatedAt:   task.UpdatedAt,
+			ID:                 task.ID,
+			Title:              task.Title,
+			Description:        task.Description,
+			IsVerified:         task.IsVerified,
+			VerificationStatus: task.VerificationStatus,
+			Deadline:           task.Deadline,
+			CreatedAt:          task.CreatedAt,
+			UpdatedAt:          task.UpdatedAt,
 			User: dto.UserDTO{
 				UserID:   task.User.UserID,
 				Username: task.User.Username,
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TaskVerificationService struct {
+	repo           *repositories.TaskVerificationRepository
+	taskRepo       *repositories.TaskRepository
+	groupRepo      *repositories.GroupRepository
+	groupUserRepo  *repositories.GroupUserRepository
+	userRepo       repositories.UserRepository
+	reputationRepo *repositories.ReputationRepository
+	audit          *AuditService
+}
+
+func NewTaskVerificationService(repo *repositories.TaskVerificationRepository, taskRepo *repositories.TaskRepository, groupRepo *repositories.GroupRepository, groupUserRepo *repositories.GroupUserRepository, userRepo repositories.UserRepository, reputationRepo *repositories.ReputationRepository, audit *AuditService) *TaskVerificationService {
+	return &TaskVerificationService{
+		repo:           repo,
+		taskRepo:       taskRepo,
+		groupRepo:      groupRepo,
+		groupUserRepo:  groupUserRepo,
+		userRepo:       userRepo,
+		reputationRepo: reputationRepo,
+		audit:          audit,
+	}
+}
+
+// loadTask returns the user and the task if the user belongs to the task's group
+func (s *TaskVerificationService) loadTask(username string, taskID int32) (*models.User, *models.Task, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+	task, err := s.taskRepo.GetByID(taskID)
+	if err != nil {
+		return nil, nil, errors.New("task not found")
+	}
+	isMember, err := s.groupUserRepo.IsMember(task.GroupID, user.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isMember {
+		return nil, nil, errors.New("access denied: group membership required")
+	}
+	return user, task, nil
+}
+
+// checkMember returns an error unless the user belongs to the group
+func (s *TaskVerificationService) checkMember(username string, groupID int32) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if _, err := s.groupRepo.GetByID(groupID); err != nil {
+		return errors.New("group not found")
+	}
+	isMember, err := s.groupUserRepo.IsMember(groupID, user.UserID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errors.New("access denied: group membership required")
+	}
+	return nil
+}
+
+func (s *TaskVerificationService) GetPolicy(username string, groupID int32) (dto.VerificationPolicyDTO, error) {
+	if err := s.checkMember(username, groupID); err != nil {
+		return dto.VerificationPolicyDTO{}, err
+	}
+	policy, err := s.repo.GetPolicy(groupID)
+	if err != nil {
+		return dto.VerificationPolicyDTO{}, err
+	}
+	return dto.ToVerificationPolicyDTO(policy), nil
+}
+
+// UpdatePolicy changes how the group's tasks are verified. Tasks already decided keep their
+// status; pending ones are decided by the new rules on their next vote.
+func (s *TaskVerificationService) UpdatePolicy(username string, groupID int32, req dto.UpdateVerificationPolicyRequest) (dto.VerificationPolicyDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.VerificationPolicyDTO{}, errors.New("user not found")
+	}
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.VerificationPolicyDTO{}, errors.New("group not found")
+	}
+	if group.AdminID != user.UserID {
+		return dto.VerificationPolicyDTO{}, errors.New("access denied: group owner role required")
+	}
+
+	policy, err := s.repo.GetPolicy(groupID)
+	if err != nil {
+		return dto.VerificationPolicyDTO{}, err
+	}
+	before := dto.ToVerificationPolicyDTO(policy)
+	if req.Mode != nil {
+		policy.Mode = *req.Mode
+	}
+	if req.Voters != nil {
+		policy.Voters = *req.Voters
+	}
+	if req.Quorum != nil {
+		policy.Quorum = *req.Quorum
+	}
+	if req.Threshold != nil {
+		policy.Threshold = *req.Threshold
+	}
+	if req.AutoVerifyReputation != nil {
+		policy.AutoVerifyReputation = *req.AutoVerifyReputation
+	}
+	policy.UpdatedBy = &user.UserID
+	if err := s.repo.SavePolicy(policy); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+		}).Error("Failed to save verification policy")
+		return dto.VerificationPolicyDTO{}, err
+	}
+
+	after := dto.ToVerificationPolicyDTO(policy)
+	utils.Logger.WithFields(logrus.Fields{
+		"group_id":               groupID,
+		"mode":                   policy.Mode,
+		"voters":                 policy.Voters,
+		"quorum":                 policy.Quorum,
+		"threshold":              policy.Threshold,
+		"auto_verify_reputation": policy.AutoVerifyReputation,
+	}).Info("Verification policy updated")
+	s.audit.Record(groupID, user.UserID, models.AuditVerificationPolicy, "group", groupID, before, after)
+	return after, nil
+}
+
+// Vote records the user's legit/fake vote and applies the group's policy. Only groups in vote
+// mode accept votes, and a task's author can't vote on it.
+func (s *TaskVerificationService) Vote(username string, taskID int32, req dto.VoteRequest) (dto.TaskVerificationDTO, error) {
+	user, task, err := s.loadTask(username, taskID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	policy, err := s.repo.GetPolicy(task.GroupID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	if policy.Mode != models.VerifyByVote {
+		return dto.TaskVerificationDTO{}, errors.New("verification voting is disabled for this group")
+	}
+	if task.UserID == user.UserID {
+		return dto.TaskVerificationDTO{}, errors.New("cannot vote on your own task")
+	}
+	if policy.Voters == "moderators" {
+		isModerator, err := s.groupRepo.IsAdminOrModerator(task.GroupID, user.UserID)
+		if err != nil {
+			return dto.TaskVerificationDTO{}, err
+		}
+		if !isModerator {
+			return dto.TaskVerificationDTO{}, errors.New("only moderators can vote on tasks in this group")
+		}
+	}
+
+	previous := task.VerificationStatus
+	t, changed, err := s.repo.Vote(task, &models.TaskVote{
+		TaskID: task.ID,
+		UserID: user.UserID,
+		Vote:   req.Vote,
+		Reason: req.Reason,
+	}, policy)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"task_id": task.ID,
+		"user_id": user.UserID,
+		"vote":    req.Vote,
+		"legit":   t.Legit,
+		"fake":    t.Fake,
+	}).Info("Task vote recorded")
+	s.recordOutcome(task, user.UserID, previous, changed, t)
+	return s.verification(user, task, policy)
+}
+
+// RetractVote deletes the user's vote; a task decided by votes can fall back to pending
+func (s *TaskVerificationService) RetractVote(username string, taskID int32) (dto.TaskVerificationDTO, error) {
+	user, task, err := s.loadTask(username, taskID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	policy, err := s.repo.GetPolicy(task.GroupID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	previous := task.VerificationStatus
+	t, changed, err := s.repo.Retract(task, user.UserID, policy)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	s.recordOutcome(task, user.UserID, previous, changed, t)
+	return s.verification(user, task, policy)
+}
+
+func (s *TaskVerificationService) recordOutcome(task *models.Task, voterID int32, previous string, changed bool, t repositories.Tally) {
+	if !changed {
+		return
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"task_id": task.ID,
+		"status":  task.VerificationStatus,
+		"legit":   t.Legit,
+		"fake":    t.Fake,
+	}).Info("Task verification decided by vote")
+	s.audit.Record(task.GroupID, voterID, models.AuditTaskVerify, "task", task.ID,
+		map[string]interface{}{"status": previous},
+		map[string]interface{}{"status": task.VerificationStatus, "method": models.VerifyByVote, "legit": t.Legit, "fake": t.Fake})
+}
+
+// GetVerification returns the task's status, tally, votes and decision history
+func (s *TaskVerificationService) GetVerification(username string, taskID int32) (dto.TaskVerificationDTO, error) {
+	user, task, err := s.loadTask(username, taskID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	policy, err := s.repo.GetPolicy(task.GroupID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	return s.verification(user, task, policy)
+}
+
+func (s *TaskVerificationService) verification(user *models.User, task *models.Task, policy *models.GroupVerificationPolicy) (dto.TaskVerificationDTO, error) {
+	votes, err := s.repo.FindVotes(task.ID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	decisions, err := s.repo.FindDecisions(task.ID)
+	if err != nil {
+		return dto.TaskVerificationDTO{}, err
+	}
+	result := dto.TaskVerificationDTO{
+		TaskID:  task.ID,
+		Status:  task.VerificationStatus,
+		Policy:  dto.ToVerificationPolicyDTO(policy),
+		Votes:   make([]dto.TaskVoteDTO, len(votes)),
+		History: make([]dto.VerificationDecisionDTO, len(decisions)),
+	}
+	for i := range votes {
+		result.Votes[i] = dto.ToTaskVoteDTO(&votes[i])
+		switch votes[i].Vote {
+		case "legit":
+			result.Legit++
+		case "fake":
+			result.Fake++
+		}
+		if votes[i].UserID == user.UserID {
+			result.MyVote = votes[i].Vote
+		}
+	}
+	for i := range decisions {
+		result.History[i] = dto.ToVerificationDecisionDTO(&decisions[i])
+	}
+	return result, nil
+}
+
+// GetLeaderboard ranks the group's members by reputation
+func (s *TaskVerificationService) GetLeaderboard(username string, groupID int32, limit int) ([]dto.LeaderboardEntryDTO, error) {
+	if err := s.checkMember(username, groupID); err != nil {
+		return nil, err
+	}
+	rows, err := s.reputationRepo.Leaderboard(groupID, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]dto.LeaderboardEntryDTO, len(rows))
+	for i, row := range rows {
+		entries[i] = dto.LeaderboardEntryDTO{
+			Rank:       i + 1,
+			UserID:     row.UserID,
+			Username:   row.Username,
+			Reputation: row.Score,
+			Verified:   row.Verified,
+			Rejected:   row.Rejected,
+			Pending:    row.Pending,
+		}
+	}
+	return entries, nil
+}
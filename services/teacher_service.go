@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TeacherService struct {
+	repo            *repositories.TeacherRepository
+	scheduleService *ScheduleService
+}
+
+func NewTeacherService(repo *repositories.TeacherRepository, scheduleService *ScheduleService) *TeacherService {
+	return &TeacherService{
+		repo:            repo,
+		scheduleService: scheduleService,
+	}
+}
+
+func (s *TeacherService) ListTeachers(query, department string) ([]dto.TeacherDTO, error) {
+	teachers, err := s.repo.Find(strings.TrimSpace(query), strings.TrimSpace(department))
+	if err != nil {
+		return nil, err
+	}
+	teacherDTOs := make([]dto.TeacherDTO, len(teachers))
+	for i := range teachers {
+		teacherDTOs[i] = dto.ToTeacherDTO(&teachers[i])
+	}
+	return teacherDTOs, nil
+}
+
+func (s *TeacherService) GetTeacher(id int32) (dto.TeacherDetailDTO, error) {
+	teacher, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.TeacherDetailDTO{}, errors.New("teacher not found")
+	}
+	subjects, err := s.repo.FindSubjects(id)
+	if err != nil {
+		return dto.TeacherDetailDTO{}, err
+	}
+	subjectDTOs := make([]dto.SubjectDTO, len(subjects))
+	for i, subject := range subjects {
+		subjectDTOs[i] = dto.SubjectDTO{ID: subject.SubjectID, Name: subject.Name, AcademicGroupID: subject.AcademicGroupID}
+	}
+	return dto.TeacherDetailDTO{TeacherDTO: dto.ToTeacherDTO(teacher), Subjects: subjectDTOs}, nil
+}
+
+func (s *TeacherService) CreateTeacher(username string, req dto.TeacherRequest) (dto.TeacherDTO, error) {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return dto.TeacherDTO{}, err
+	}
+	initials := strings.Join(strings.Fields(req.Initials), " ")
+	if initials == "" {
+		return dto.TeacherDTO{}, errors.New("initials are required")
+	}
+	teacher := &models.Teacher{
+		FullName:   strings.TrimSpace(req.FullName),
+		Initials:   initials,
+		Email:      strings.TrimSpace(req.Email),
+		Department: strings.TrimSpace(req.Department),
+	}
+	if err := s.repo.Create(teacher); err != nil {
+		return dto.TeacherDTO{}, err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":   username,
+		"teacher_id": teacher.ID,
+	}).Info("Teacher created")
+	return dto.ToTeacherDTO(teacher), nil
+}
+
+func (s *TeacherService) UpdateTeacher(username string, id int32, req dto.UpdateTeacherRequest) (dto.TeacherDTO, error) {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return dto.TeacherDTO{}, err
+	}
+	teacher, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.TeacherDTO{}, errors.New("teacher not found")
+	}
+	if req.FullName != nil {
+		teacher.FullName = strings.TrimSpace(*req.FullName)
+	}
+	if req.Initials != nil {
+		initials := strings.Join(strings.Fields(*req.Initials), " ")
+		if initials == "" {
+			return dto.TeacherDTO{}, errors.New("initials are required")
+		}
+		teacher.Initials = initials
+	}
+	if req.Email != nil {
+		teacher.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.Department != nil {
+		teacher.Department = strings.TrimSpace(*req.Department)
+	}
+	if err := s.repo.Update(teacher); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":      err,
+			"teacher_id": id,
+		}).Error("Failed to update teacher")
+		return dto.TeacherDTO{}, err
+	}
+	return dto.ToTeacherDTO(teacher), nil
+}
+
+func (s *TeacherService) DeleteTeacher(username string, id int32) error {
+	if err := s.scheduleService.canManageSharedData(username); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("teacher not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"username":   username,
+		"teacher_id": id,
+	}).Info("Teacher deleted")
+	return nil
+}
+
+// GetTeacherSchedule returns the teacher's lessons across all groups for the day or the
+// Monday–Sunday week containing date
+func (s *TeacherService) GetTeacherSchedule(id int32, date time.Time, period string) (dto.TeacherScheduleResponse, error) {
+	teacher, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.TeacherScheduleResponse{}, errors.New("teacher not found")
+	}
+	from, to := date, date
+	if period == "week" {
+		from, to = WeekBounds(date)
+	}
+	lessons, err := s.repo.FindLessons(id, from, to)
+	if err != nil {
+		return dto.TeacherScheduleResponse{}, err
+	}
+	return dto.TeacherScheduleResponse{
+		Teacher:          dto.ToTeacherDTO(teacher),
+		ScheduleResponse: buildScheduleResponse(from, to, lessons),
+	}, nil
+}
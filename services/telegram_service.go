@@ -0,0 +1,492 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"html"
+	"math/big"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/telegram"
+	"space/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	linkCodeLength   = 8
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	linkCodeTTL      = 15 * time.Minute
+	reminderWindow   = 24 * time.Hour
+	digestLookahead  = 7 * 24 * time.Hour
+	pendingListLimit = 10
+)
+
+type TelegramService struct {
+	repo        *repositories.TelegramRepository
+	userRepo    repositories.UserRepository
+	groupRepo   *repositories.GroupRepository
+	taskService *TaskService
+	client      telegram.Client
+	config      telegram.Config
+}
+
+func NewTelegramService(repo *repositories.TelegramRepository, userRepo repositories.UserRepository, groupRepo *repositories.GroupRepository, taskService *TaskService, client telegram.Client, config telegram.Config) *TelegramService {
+	return &TelegramService{
+		repo:        repo,
+		userRepo:    userRepo,
+		groupRepo:   groupRepo,
+		taskService: taskService,
+		client:      client,
+		config:      config,
+	}
+}
+
+func generateLinkCode() (string, error) {
+	code := make([]byte, linkCodeLength)
+	max := big.NewInt(int64(len(linkCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = linkCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CreateLinkCode issues a one-time code the user sends to the bot to link their chat
+func (s *TelegramService) CreateLinkCode(username string) (*dto.TelegramLinkCodeDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	link, err := s.repo.GetByUserID(user.UserID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		link = &models.TelegramLink{UserID: user.UserID, DigestEnabled: true, RemindersEnabled: true}
+	}
+
+	code, err := generateLinkCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(linkCodeTTL)
+	link.LinkCode = &code
+	link.CodeExpiresAt = &expiresAt
+	if err := s.repo.Save(link); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": user.UserID,
+		}).Error("Failed to save telegram link code")
+		return nil, err
+	}
+
+	result := &dto.TelegramLinkCodeDTO{Code: code, ExpiresAt: expiresAt}
+	if s.config.BotUsername != "" {
+		result.DeepLink = "https://t.me/" + s.config.BotUsername + "?start=" + code
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id": user.UserID,
+	}).Info("Telegram link code issued")
+	return result, nil
+}
+
+func (s *TelegramService) GetStatus(username string) (dto.TelegramStatusDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.TelegramStatusDTO{}, errors.New("user not found")
+	}
+	link, err := s.repo.GetByUserID(user.UserID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dto.TelegramStatusDTO{Linked: false}, nil
+		}
+		return dto.TelegramStatusDTO{}, err
+	}
+	return dto.ToTelegramStatusDTO(link), nil
+}
+
+func (s *TelegramService) UpdateSettings(username string, req dto.TelegramSettingsRequest) (dto.TelegramStatusDTO, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return dto.TelegramStatusDTO{}, errors.New("user not found")
+	}
+	link, err := s.repo.GetByUserID(user.UserID)
+	if err != nil || link.ChatID == nil {
+		return dto.TelegramStatusDTO{}, errors.New("telegram is not linked")
+	}
+	if req.DigestEnabled != nil {
+		link.DigestEnabled = *req.DigestEnabled
+	}
+	if req.RemindersEnabled != nil {
+		link.RemindersEnabled = *req.RemindersEnabled
+	}
+	if err := s.repo.UpdateSettings(user.UserID, link.DigestEnabled, link.RemindersEnabled); err != nil {
+		return dto.TelegramStatusDTO{}, err
+	}
+	return dto.ToTelegramStatusDTO(link), nil
+}
+
+func (s *TelegramService) Unlink(username string) error {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.repo.DeleteByUserID(user.UserID); err != nil {
+		return err
+	}
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id": user.UserID,
+	}).Info("Telegram chat unlinked")
+	return nil
+}
+
+// Run long-polls the Bot API for updates until stop is closed
+func (s *TelegramService) Run(stop <-chan struct{}) {
+	utils.Logger.Info("Telegram bot polling started")
+	var offset int64
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		updates, err := s.client.GetUpdates(offset, 30)
+		if err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("Failed to fetch telegram updates")
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			s.HandleUpdate(update)
+		}
+	}
+}
+
+// RunScheduler sends deadline reminders and daily digests until stop is closed
+func (s *TelegramService) RunScheduler(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.SendReminders()
+			if now.Hour() == s.config.DigestHour {
+				s.SendDigests(now)
+			}
+		}
+	}
+}
+
+func (s *TelegramService) HandleUpdate(update telegram.Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		s.handleCallback(update.CallbackQuery)
+	case update.Message != nil:
+		s.handleMessage(update.Message)
+	}
+}
+
+func (s *TelegramService) reply(chatID int64, text string, markup *telegram.InlineKeyboardMarkup) {
+	if err := s.client.SendMessage(chatID, text, markup); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"chat_id": chatID,
+		}).Error("Failed to send telegram message")
+	}
+}
+
+func (s *TelegramService) handleMessage(msg *telegram.Message) {
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 {
+		return
+	}
+	command := strings.SplitN(fields[0], "@", 2)[0]
+	args := fields[1:]
+
+	// A linked shared chat would let everyone in it act as the account
+	if msg.Chat.Type != "private" {
+		s.reply(msg.Chat.ID, "The bot only works in a private chat.", nil)
+		return
+	}
+
+	switch command {
+	case "/start", "/link":
+		if len(args) == 0 {
+			s.reply(msg.Chat.ID, "Get a link code in the app and send <code>/link CODE</code>.", nil)
+			return
+		}
+		s.linkChat(msg.Chat.ID, strings.ToUpper(args[0]))
+	case "/digest":
+		link, err := s.repo.GetByChatID(msg.Chat.ID)
+		if err != nil {
+			s.reply(msg.Chat.ID, "This chat is not linked to an account.", nil)
+			return
+		}
+		s.sendDigest(link, time.Now().Add(-24*time.Hour))
+	case "/pending":
+		link, err := s.repo.GetByChatID(msg.Chat.ID)
+		if err != nil {
+			s.reply(msg.Chat.ID, "This chat is not linked to an account.", nil)
+			return
+		}
+		s.sendPendingTasks(link)
+	case "/unlink":
+		link, err := s.repo.GetByChatID(msg.Chat.ID)
+		if err != nil {
+			s.reply(msg.Chat.ID, "This chat is not linked to an account.", nil)
+			return
+		}
+		if err := s.repo.DeleteByUserID(link.UserID); err != nil {
+			s.reply(msg.Chat.ID, "Failed to unlink, please try again later.", nil)
+			return
+		}
+		s.reply(msg.Chat.ID, "Account unlinked.", nil)
+	default:
+		s.reply(msg.Chat.ID, "Commands: /digest, /pending, /unlink", nil)
+	}
+}
+
+func (s *TelegramService) linkChat(chatID int64, code string) {
+	link, err := s.repo.GetByCode(code)
+	if err != nil || link.CodeExpiresAt == nil || time.Now().After(*link.CodeExpiresAt) {
+		utils.Logger.WithFields(logrus.Fields{
+			"chat_id": chatID,
+		}).Warn("Invalid or expired telegram link code")
+		s.reply(chatID, "The code is invalid or expired. Request a new one in the app.", nil)
+		return
+	}
+
+	// A chat can only belong to one account
+	if existing, err := s.repo.GetByChatID(chatID); err == nil && existing.UserID != link.UserID {
+		existing.ChatID = nil
+		existing.LinkedAt = nil
+		if err := s.repo.Save(existing); err != nil {
+			s.reply(chatID, "Failed to link account, please try again later.", nil)
+			return
+		}
+	}
+
+	now := time.Now()
+	link.ChatID = &chatID
+	link.LinkedAt = &now
+	link.LinkCode = nil
+	link.CodeExpiresAt = nil
+	if err := s.repo.Save(link); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"user_id": link.UserID,
+		}).Error("Failed to link telegram chat")
+		s.reply(chatID, "Failed to link account, please try again later.", nil)
+		return
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id": link.UserID,
+		"chat_id": chatID,
+	}).Info("Telegram chat linked")
+	s.reply(chatID, "Account linked. You will receive deadline reminders and a daily digest.", nil)
+}
+
+func verifyKeyboard(taskID int32) *telegram.InlineKeyboardMarkup {
+	id := strconv.Itoa(int(taskID))
+	return &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{{
+			{Text: "✅ Legit", CallbackData: "verify:" + id + ":1"},
+			{Text: "❌ Fake", CallbackData: "verify:" + id + ":0"},
+		}},
+	}
+}
+
+func (s *TelegramService) handleCallback(cq *telegram.CallbackQuery) {
+	if cq.Message == nil {
+		return
+	}
+	parts := strings.Split(cq.Data, ":")
+	if len(parts) != 3 || parts[0] != "verify" {
+		s.answer(cq.ID, "Unknown action")
+		return
+	}
+	taskID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		s.answer(cq.ID, "Invalid task")
+		return
+	}
+	isVerified := parts[2] == "1"
+
+	// Links are made in private chats, whose ID is the user's Telegram ID; anyone else
+	// pressing the button (e.g. in a chat linked before that rule) is not the account owner
+	if cq.From.ID != cq.Message.Chat.ID {
+		utils.Logger.WithFields(logrus.Fields{
+			"chat_id": cq.Message.Chat.ID,
+			"from_id": cq.From.ID,
+		}).Warn("Forbidden: telegram callback from outside the linked private chat")
+		s.answer(cq.ID, "Only the linked account can do this")
+		return
+	}
+	link, err := s.repo.GetByChatID(cq.Message.Chat.ID)
+	if err != nil {
+		s.answer(cq.ID, "This chat is not linked to an account")
+		return
+	}
+	task, err := s.taskService.GetTaskByID(int32(taskID))
+	if err != nil {
+		s.answer(cq.ID, "Task not found")
+		return
+	}
+	isAuthorized, err := s.groupRepo.IsAdminOrModerator(task.GroupID, link.UserID)
+	if err != nil || !isAuthorized {
+		utils.Logger.WithFields(logrus.Fields{
+			"user_id":  link.UserID,
+			"group_id": task.GroupID,
+		}).Warn("Forbidden: telegram verification by non-moderator")
+		s.answer(cq.ID, "Admin or moderator role required")
+		return
+	}
+
+	if err := s.taskService.VerifyTask(task.ID, isVerified, link.UserID); err != nil {
+		s.answer(cq.ID, "Failed to verify task")
+		return
+	}
+
+	verdict := "verified ✅"
+	if !isVerified {
+		verdict = "marked as fake ❌"
+	}
+	s.answer(cq.ID, "Done")
+	text := fmt.Sprintf("<b>%s</b> %s by %s", html.EscapeString(task.Title), verdict, html.EscapeString(link.User.Username))
+	if err := s.client.EditMessageText(cq.Message.Chat.ID, cq.Message.MessageID, text); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":   err,
+			"task_id": task.ID,
+		}).Error("Failed to edit telegram message")
+	}
+}
+
+func (s *TelegramService) answer(callbackID, text string) {
+	if err := s.client.AnswerCallbackQuery(callbackID, text); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to answer telegram callback")
+	}
+}
+
+func formatTaskLine(task models.Task) string {
+	line := "• <b>" + html.EscapeString(task.Title) + "</b> — " + html.EscapeString(task.Group.Name)
+	if task.SubjectID != nil && task.Subject.Name != "" {
+		line += " (" + html.EscapeString(task.Subject.Name) + ")"
+	}
+	if task.Deadline != nil {
+		line += "\n   due " + task.Deadline.Format("02.01 15:04")
+	}
+	return line
+}
+
+func (s *TelegramService) sendPendingTasks(link *models.TelegramLink) {
+	tasks, err := s.repo.FindPendingForModerator(link.UserID, pendingListLimit)
+	if err != nil {
+		s.reply(*link.ChatID, "Failed to load tasks, please try again later.", nil)
+		return
+	}
+	if len(tasks) == 0 {
+		s.reply(*link.ChatID, "No tasks waiting for verification.", nil)
+		return
+	}
+	for _, task := range tasks {
+		text := fmt.Sprintf("%s\nposted by %s\n\n%s", formatTaskLine(task), html.EscapeString(task.User.Username), html.EscapeString(task.Description))
+		s.reply(*link.ChatID, text, verifyKeyboard(task.ID))
+	}
+}
+
+// SendReminders notifies linked users about deadlines in the next 24 hours, once per task
+func (s *TelegramService) SendReminders() {
+	links, err := s.repo.FindLinked()
+	if err != nil {
+		return
+	}
+	until := time.Now().Add(reminderWindow)
+	for i := range links {
+		link := &links[i]
+		if !link.RemindersEnabled {
+			continue
+		}
+		tasks, err := s.repo.FindUpcomingUnreminded(link.UserID, until)
+		if err != nil {
+			continue
+		}
+		for _, task := range tasks {
+			s.reply(*link.ChatID, "⏰ Deadline soon\n"+formatTaskLine(task), nil)
+			if err := s.repo.CreateReminder(task.ID, link.UserID); err != nil {
+				utils.Logger.WithFields(logrus.Fields{
+					"error":   err,
+					"task_id": task.ID,
+					"user_id": link.UserID,
+				}).Error("Failed to record telegram reminder")
+			}
+		}
+	}
+}
+
+// SendDigests sends the daily digest to every linked user who hasn't received one today
+func (s *TelegramService) SendDigests(now time.Time) {
+	links, err := s.repo.FindLinked()
+	if err != nil {
+		return
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for i := range links {
+		link := &links[i]
+		if !link.DigestEnabled || (link.LastDigestAt != nil && !link.LastDigestAt.Before(today)) {
+			continue
+		}
+		since := now.Add(-24 * time.Hour)
+		if link.LastDigestAt != nil {
+			since = *link.LastDigestAt
+		}
+		s.sendDigest(link, since)
+		if err := s.repo.MarkDigestSent(link.UserID, now); err != nil {
+			utils.Logger.WithFields(logrus.Fields{
+				"error":   err,
+				"user_id": link.UserID,
+			}).Error("Failed to record telegram digest")
+		}
+	}
+}
+
+func (s *TelegramService) sendDigest(link *models.TelegramLink, since time.Time) {
+	tasks, err := s.repo.FindDigestTasks(link.UserID, since, time.Now().Add(digestLookahead))
+	if err != nil {
+		return
+	}
+	if len(tasks) == 0 {
+		s.reply(*link.ChatID, "📋 Nothing new in your groups.", nil)
+		return
+	}
+	lines := make([]string, 0, len(tasks)+1)
+	lines = append(lines, "📋 <b>Your tasks digest</b>")
+	for _, task := range tasks {
+		lines = append(lines, formatTaskLine(task))
+	}
+	s.reply(*link.ChatID, strings.Join(lines, "\n"), nil)
+
+	utils.Logger.WithFields(logrus.Fields{
+		"user_id": link.UserID,
+		"count":   len(tasks),
+	}).Debug("Telegram digest sent")
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"space/models"
+	"space/models/dto"
+	"space/repositories"
+	"space/timetable"
+	"space/utils"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxImportLessons = 5000
+
+type TimetableImportService struct {
+	scheduleRepo    *repositories.ScheduleRepository
+	subjectRepo     *repositories.SubjectRepository
+	groupRepo       *repositories.GroupRepository
+	scheduleService *ScheduleService
+}
+
+func NewTimetableImportService(scheduleRepo *repositories.ScheduleRepository, subjectRepo *repositories.SubjectRepository, groupRepo *repositories.GroupRepository, scheduleService *ScheduleService) *TimetableImportService {
+	return &TimetableImportService{
+		scheduleRepo:    scheduleRepo,
+		subjectRepo:     subjectRepo,
+		groupRepo:       groupRepo,
+		scheduleService: scheduleService,
+	}
+}
+
+// expandImportedLessons turns weekly XLSX rows into dated lessons within [from, to] and
+// drops iCalendar lessons outside the range when one is given
+func expandImportedLessons(lessons []timetable.Lesson, from, to *time.Time, semesters SemesterCalendar) []timetable.Lesson {
+	var dated []timetable.Lesson
+	for _, lesson := range lessons {
+		if !lesson.Date.IsZero() {
+			if from != nil && lesson.Date.Before(*from) || to != nil && lesson.Date.After(*to) {
+				continue
+			}
+			dated = append(dated, lesson)
+			continue
+		}
+		for d := *from; !d.After(*to); d = d.AddDate(0, 0, 1) {
+			if IsoWeekday(d) != lesson.Weekday || !matchesParity(lesson.WeekParity, semesters.Week(d, *from)) {
+				continue
+			}
+			l := lesson
+			l.Date = d
+			dated = append(dated, l)
+		}
+	}
+	sort.SliceStable(dated, func(i, j int) bool { return dated[i].Date.Before(dated[j].Date) })
+	return dated
+}
+
+func subjectKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// slotResolver matches lesson times to existing time slots and, when canCreate is set, plans
+// new ones
+type slotResolver struct {
+	byNumber  map[int32]*models.TimeSlot
+	byStart   map[string]*models.TimeSlot
+	planned   map[int32]*models.TimeSlot
+	canCreate bool
+}
+
+func newSlotResolver(slots []models.TimeSlot, canCreate bool) *slotResolver {
+	r := &slotResolver{
+		byNumber:  make(map[int32]*models.TimeSlot),
+		byStart:   make(map[string]*models.TimeSlot),
+		planned:   make(map[int32]*models.TimeSlot),
+		canCreate: canCreate,
+	}
+	for i := range slots {
+		r.add(&slots[i])
+	}
+	return r
+}
+
+func (r *slotResolver) add(slot *models.TimeSlot) {
+	r.byNumber[slot.SlotNumber] = slot
+	r.byStart[dto.ToTimeSlotDTO(slot).StartTime] = slot
+}
+
+func (r *slotResolver) plan(number int32, start, end string) *models.TimeSlot {
+	slot := &models.TimeSlot{SlotNumber: number, StartTime: start, EndTime: end}
+	r.add(slot)
+	r.planned[number] = slot
+	return slot
+}
+
+func (r *slotResolver) resolve(lesson timetable.Lesson) (*models.TimeSlot, string) {
+	if lesson.SlotNumber > 0 {
+		if slot, ok := r.byNumber[lesson.SlotNumber]; ok {
+			existing := dto.ToTimeSlotDTO(slot)
+			if lesson.StartTime != "" && (existing.StartTime != lesson.StartTime || existing.EndTime != lesson.EndTime) {
+				return nil, fmt.Sprintf("pair %d is %s-%s, the file says %s-%s",
+					lesson.SlotNumber, existing.StartTime, existing.EndTime, lesson.StartTime, lesson.EndTime)
+			}
+			return slot, ""
+		}
+		if lesson.StartTime == "" {
+			return nil, fmt.Sprintf("pair %d doesn't exist and the file has no time for it", lesson.SlotNumber)
+		}
+		if slot, ok := r.byStart[lesson.StartTime]; ok {
+			return nil, fmt.Sprintf("time %s already belongs to pair %d", lesson.StartTime, slot.SlotNumber)
+		}
+		if !r.canCreate {
+			return nil, fmt.Sprintf("pair %d doesn't exist; only a site administrator can create time slots", lesson.SlotNumber)
+		}
+		return r.plan(lesson.SlotNumber, lesson.StartTime, lesson.EndTime), ""
+	}
+
+	if slot, ok := r.byStart[lesson.StartTime]; ok {
+		if end := dto.ToTimeSlotDTO(slot).EndTime; end != lesson.EndTime {
+			return nil, fmt.Sprintf("pair %d ends at %s, the file says %s", slot.SlotNumber, end, lesson.EndTime)
+		}
+		return slot, ""
+	}
+	if !r.canCreate {
+		return nil, "no pair starts at " + lesson.StartTime + "; only a site administrator can create time slots"
+	}
+	for number := int32(1); number <= 9; number++ {
+		if _, taken := r.byNumber[number]; !taken {
+			return r.plan(number, lesson.StartTime, lesson.EndTime), ""
+		}
+	}
+	return nil, "no free pair numbers left for " + lesson.StartTime
+}
+
+// ImportTimetable parses an XLSX or iCalendar timetable and plans the schedule changes for
+// the group. With dryRun nothing is written; otherwise all creates and updates are applied in
+// one transaction. Lessons that clash with a different existing lesson of the group, or that
+// double-book a room, teacher or academic group, are reported as conflicts and skipped.
+func (s *TimetableImportService) ImportTimetable(username string, groupID int32, format string, file io.Reader, from, to *time.Time, dryRun bool) (dto.TimetableImportReport, error) {
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return dto.TimetableImportReport{}, errors.New("group not found")
+	}
+	if _, err := s.scheduleService.requireModerator(groupID, username); err != nil {
+		return dto.TimetableImportReport{}, err
+	}
+
+	if format == "xlsx" && (from == nil || to == nil) {
+		return dto.TimetableImportReport{}, errors.New("semester dates are required for XLSX imports")
+	}
+	if from != nil && to != nil {
+		if to.Before(*from) {
+			return dto.TimetableImportReport{}, errors.New("invalid semester range")
+		}
+		if to.Sub(*from).Hours()/24 > maxSemesterDays {
+			return dto.TimetableImportReport{}, errors.New("semester is too long")
+		}
+	}
+
+	var lessons []timetable.Lesson
+	var problems []timetable.ParseError
+	switch format {
+	case "xlsx":
+		lessons, problems, err = timetable.ParseXLSX(file)
+	case "ics":
+		lessons, problems, err = timetable.ParseICal(file)
+	default:
+		return dto.TimetableImportReport{}, errors.New("unsupported timetable format")
+	}
+	if err != nil {
+		if errors.Is(err, timetable.ErrUnsupportedFormat) {
+			return dto.TimetableImportReport{}, errors.New("unsupported timetable format")
+		}
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"format":   format,
+		}).Warn("Failed to parse timetable")
+		return dto.TimetableImportReport{}, errors.New("invalid timetable file")
+	}
+
+	dated := expandImportedLessons(lessons, from, to, s.scheduleService.semesters)
+	if len(dated) > maxImportLessons {
+		return dto.TimetableImportReport{}, errors.New("timetable is too large")
+	}
+
+	report := dto.TimetableImportReport{
+		DryRun:           dryRun,
+		Format:           format,
+		TimeSlotsCreated: []dto.TimeSlotDTO{},
+		SubjectsCreated:  []string{},
+		Items:            []dto.TimetableImportItemDTO{},
+		Errors:           []dto.TimetableImportErrorDTO{},
+	}
+	for _, p := range problems {
+		report.Errors = append(report.Errors, dto.TimetableImportErrorDTO{Source: p.Source, Message: p.Message})
+	}
+	report.Summary.Errors = len(report.Errors)
+	if len(dated) == 0 {
+		return report, nil
+	}
+
+	slots, err := s.scheduleRepo.FindTimeSlots()
+	if err != nil {
+		return dto.TimetableImportReport{}, err
+	}
+	subjects, err := s.subjectRepo.FindAllByAcademicGroupID(group.AcademicGroupID)
+	if err != nil {
+		return dto.TimetableImportReport{}, err
+	}
+	existing, err := s.scheduleRepo.FindByGroupIDs([]int32{groupID}, dated[0].Date, dated[len(dated)-1].Date)
+	if err != nil {
+		return dto.TimetableImportReport{}, err
+	}
+
+	// Time slots are shared by all groups, so only site administrators' imports create them
+	resolver := newSlotResolver(slots, s.scheduleService.canManageSharedData(username) == nil)
+	subjectsByName := make(map[string]*models.Subject, len(subjects))
+	for i := range subjects {
+		subjectsByName[subjectKey(subjects[i].Name)] = &subjects[i]
+	}
+	existingByKey := make(map[string]*models.Schedule, len(existing))
+	for i := range existing {
+		key := fmt.Sprintf("%s|%d", existing[i].Date.Format(dto.DateLayout), existing[i].TimeSlot.SlotNumber)
+		existingByKey[key] = &existing[i]
+	}
+
+	teacherIDs := make(map[string]*int32)
+	linkTeacher := func(initials string) (*int32, error) {
+		key := utils.InitialsKey(initials)
+		if id, ok := teacherIDs[key]; ok {
+			return id, nil
+		}
+		id, _, err := s.scheduleService.resolveTeacher(nil, initials)
+		teacherIDs[key] = id
+		return id, err
+	}
+
+	roomIDs := make(map[string]*int32)
+	linkRoom := func(classroom string) *int32 {
+		key := utils.ClassroomKey(classroom)
+		if id, ok := roomIDs[key]; ok {
+			return id
+		}
+		id, _, _ := s.scheduleService.resolveRoom(nil, classroom)
+		roomIDs[key] = id
+		return id
+	}
+
+	plan := &repositories.TimetableImport{}
+	seen := make(map[string]string)
+	// candidates are the lessons to create or update, checked for double-booking below
+	var candidates []models.Schedule
+	var candidateItems []int
+	candidateCreates := make(map[int]repositories.ImportedLesson)
+
+	for _, lesson := range dated {
+		item := dto.TimetableImportItemDTO{
+			Source:     lesson.Source,
+			Date:       lesson.Date.Format(dto.DateLayout),
+			SlotNumber: lesson.SlotNumber,
+			StartTime:  lesson.StartTime,
+			EndTime:    lesson.EndTime,
+			Subject:    lesson.Subject,
+			Teacher:    lesson.Teacher,
+			Classroom:  lesson.Room,
+		}
+
+		slot, reason := resolver.resolve(lesson)
+		if slot == nil {
+			item.Action, item.Reason = "conflict", reason
+			report.Items = append(report.Items, item)
+			continue
+		}
+		slotDTO := dto.ToTimeSlotDTO(slot)
+		item.SlotNumber, item.StartTime, item.EndTime = slot.SlotNumber, slotDTO.StartTime, slotDTO.EndTime
+
+		key := fmt.Sprintf("%s|%d", item.Date, slot.SlotNumber)
+		if first, ok := seen[key]; ok {
+			item.Action, item.Reason = "conflict", "the file already has a lesson at this time ("+first+")"
+			report.Items = append(report.Items, item)
+			continue
+		}
+		seen[key] = lesson.Source
+
+		teacherID, err := linkTeacher(lesson.Teacher)
+		if err != nil {
+			return dto.TimetableImportReport{}, err
+		}
+
+		roomID := linkRoom(lesson.Room)
+
+		subject, known := subjectsByName[subjectKey(lesson.Subject)]
+		if current, ok := existingByKey[key]; ok {
+			switch {
+			case !known || current.SubjectID != subject.SubjectID:
+				item.Action, item.Reason = "conflict", "the schedule already has "+current.Subject.Name+" at this time"
+			case current.TeacherInitials == lesson.Teacher && current.Classroom == lesson.Room:
+				item.Action = "unchanged"
+			default:
+				item.Action = "update"
+				updated := *current
+				updated.TeacherInitials, updated.TeacherID = lesson.Teacher, teacherID
+				updated.Classroom, updated.RoomID = lesson.Room, roomID
+				candidates = append(candidates, updated)
+				candidateItems = append(candidateItems, len(report.Items))
+			}
+			report.Items = append(report.Items, item)
+			continue
+		}
+
+		if !known {
+			subject = &models.Subject{AcademicGroupID: group.AcademicGroupID, Name: lesson.Subject}
+			subjectsByName[subjectKey(lesson.Subject)] = subject
+		}
+		item.Action = "create"
+		created := repositories.ImportedLesson{
+			Schedule: models.Schedule{
+				GroupID:         groupID,
+				Date:            lesson.Date,
+				TeacherInitials: lesson.Teacher,
+				TeacherID:       teacherID,
+				Classroom:       lesson.Room,
+				RoomID:          roomID,
+			},
+			TimeSlot: slot,
+			Subject:  subject,
+		}
+		candidate := created.Schedule
+		candidate.TimeSlotID, candidate.SubjectID = slot.SlotID, subject.SubjectID
+		candidate.Group, candidate.Subject = *group, *subject
+		candidateCreates[len(candidates)] = created
+		candidates = append(candidates, candidate)
+		candidateItems = append(candidateItems, len(report.Items))
+		report.Items = append(report.Items, item)
+	}
+
+	conflicts, err := s.scheduleService.detectConflicts(candidates)
+	if err != nil {
+		return dto.TimetableImportReport{}, err
+	}
+	usedSlots := make(map[*models.TimeSlot]bool)
+	usedSubjects := make(map[*models.Subject]bool)
+	for i, candidate := range candidates {
+		item := &report.Items[candidateItems[i]]
+		if len(conflicts[i]) > 0 {
+			item.Action, item.Reason, item.Conflicts = "conflict", "double-booked with another lesson", conflicts[i]
+			continue
+		}
+		created, ok := candidateCreates[i]
+		if !ok {
+			plan.Updates = append(plan.Updates, candidate)
+			continue
+		}
+		plan.Creates = append(plan.Creates, created)
+		usedSlots[created.TimeSlot] = true
+		if created.Subject.SubjectID == 0 && !usedSubjects[created.Subject] {
+			usedSubjects[created.Subject] = true
+			plan.Subjects = append(plan.Subjects, created.Subject)
+			report.SubjectsCreated = append(report.SubjectsCreated, created.Subject.Name)
+		}
+	}
+
+	// Only slots that a created lesson refers to are worth creating
+	for number := int32(1); number <= 9; number++ {
+		if slot, ok := resolver.planned[number]; ok && usedSlots[slot] {
+			plan.TimeSlots = append(plan.TimeSlots, slot)
+			report.TimeSlotsCreated = append(report.TimeSlotsCreated, dto.ToTimeSlotDTO(slot))
+		}
+	}
+
+	for _, item := range report.Items {
+		switch item.Action {
+		case "create":
+			report.Summary.Created++
+		case "update":
+			report.Summary.Updated++
+		case "unchanged":
+			report.Summary.Unchanged++
+		case "conflict":
+			report.Summary.Conflicts++
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := s.scheduleRepo.ApplyImport(plan); err != nil {
+		utils.Logger.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": groupID,
+			"format":   format,
+		}).Error("Failed to apply timetable import")
+		return dto.TimetableImportReport{}, err
+	}
+
+	utils.Logger.WithFields(logrus.Fields{
+		"username":  username,
+		"group_id":  groupID,
+		"format":    format,
+		"created":   report.Summary.Created,
+		"updated":   report.Summary.Updated,
+		"conflicts": report.Summary.Conflicts,
+	}).Info("Timetable imported")
+
+	return report, nil
+}
@@ -0,0 +1,47 @@
+package telegram_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"space/services"
+	"space/telegram"
+	"space/utils"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestCallbackFromOtherUser checks that a verification button pressed by someone other than
+// the owner of the linked private chat is refused before any account is looked up
+func TestCallbackFromOtherUser(t *testing.T) {
+	utils.Logger = logrus.New()
+	utils.Logger.SetOutput(io.Discard)
+
+	var answered map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/answerCallbackQuery" {
+			t.Errorf("unexpected call %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&answered)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer server.Close()
+
+	client := telegram.NewClient(server.URL, "TOKEN")
+	bot := services.NewTelegramService(nil, nil, nil, nil, client, telegram.Config{Token: "TOKEN"})
+	bot.HandleUpdate(telegram.Update{
+		UpdateID: 1,
+		CallbackQuery: &telegram.CallbackQuery{
+			ID:      "cb1",
+			From:    telegram.User{ID: 99},
+			Message: &telegram.Message{MessageID: 7, Chat: telegram.Chat{ID: -100, Type: "group"}},
+			Data:    "verify:5:1",
+		},
+	})
+
+	if answered["callback_query_id"] != "cb1" || answered["text"] != "Only the linked account can do this" {
+		t.Errorf("unexpected answer %v", answered)
+	}
+}
@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const DefaultAPIURL = "https://api.telegram.org"
+
+// Client is the subset of the Telegram Bot API used by the bot.
+// It is an interface so the bot can be pointed at a local fake server.
+type Client interface {
+	GetUpdates(offset int64, timeout int) ([]Update, error)
+	SendMessage(chatID int64, text string, markup *InlineKeyboardMarkup) error
+	AnswerCallbackQuery(callbackID, text string) error
+	EditMessageText(chatID, messageID int64, text string) error
+}
+
+type Config struct {
+	Token       string
+	APIURL      string
+	BotUsername string
+	DigestHour  int
+}
+
+// LoadConfig reads bot settings from the environment (see .env)
+func LoadConfig() Config {
+	config := Config{
+		Token:       os.Getenv("TELEGRAM_BOT_TOKEN"),
+		APIURL:      os.Getenv("TELEGRAM_API_URL"),
+		BotUsername: os.Getenv("TELEGRAM_BOT_USERNAME"),
+		DigestHour:  8,
+	}
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	if hour := os.Getenv("TELEGRAM_DIGEST_HOUR"); hour != "" {
+		var h int
+		if _, err := fmt.Sscanf(hour, "%d", &h); err == nil && h >= 0 && h < 24 {
+			config.DigestHour = h
+		}
+	}
+	return config
+}
+
+func (c Config) Enabled() bool {
+	return c.Token != ""
+}
+
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // private, group, supergroup or channel
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data"`
+}
+
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+type httpClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a Bot API client talking to apiURL (normally DefaultAPIURL)
+func NewClient(apiURL, token string) Client {
+	return &httpClient{
+		baseURL: strings.TrimRight(apiURL, "/") + "/bot" + token,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (c *httpClient) call(method string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Post(c.baseURL+"/"+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("telegram %s: failed to decode response: %w", method, err)
+	}
+	if !apiResp.OK {
+		return errors.New("telegram " + method + ": " + apiResp.Description)
+	}
+	if result != nil {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
+
+func (c *httpClient) GetUpdates(offset int64, timeout int) ([]Update, error) {
+	var updates []Update
+	err := c.call("getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         timeout,
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+func (c *httpClient) SendMessage(chatID int64, text string, markup *InlineKeyboardMarkup) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}
+	if markup != nil {
+		payload["reply_markup"] = markup
+	}
+	return c.call("sendMessage", payload, nil)
+}
+
+func (c *httpClient) AnswerCallbackQuery(callbackID, text string) error {
+	return c.call("answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": callbackID,
+		"text":              text,
+	}, nil)
+}
+
+func (c *httpClient) EditMessageText(chatID, messageID int64, text string) error {
+	return c.call("editMessageText", map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}, nil)
+}
@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeBotAPI records the calls made to it and answers each method with the given result
+type fakeBotAPI struct {
+	t       *testing.T
+	results map[string]string
+	calls   map[string]map[string]interface{}
+}
+
+func newFakeBotAPI(t *testing.T, results map[string]string) (*fakeBotAPI, Client) {
+	fake := &fakeBotAPI{t: t, results: results, calls: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewClient(server.URL+"/", "TOKEN")
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/botTOKEN/")
+	if !ok {
+		f.t.Errorf("unexpected path %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		f.t.Errorf("%s: invalid payload: %v", method, err)
+	}
+	f.calls[method] = payload
+
+	result, ok := f.results[method]
+	if !ok {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "description": "Bad Request: unknown method"})
+		return
+	}
+	w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+}
+
+func TestGetUpdates(t *testing.T) {
+	fake, client := newFakeBotAPI(t, map[string]string{"getUpdates": `[
+		{"update_id": 10, "message": {"message_id": 1, "from": {"id": 42, "username": "ivan"},
+			"chat": {"id": 42, "type": "private"}, "text": "/link ABCD2345"}},
+		{"update_id": 11, "callback_query": {"id": "cb1", "from": {"id": 42},
+			"message": {"message_id": 7, "chat": {"id": 42, "type": "private"}}, "data": "verify:5:1"}}
+	]`})
+
+	updates, err := client.GetUpdates(10, 30)
+	if err != nil {
+		t.Fatalf("GetUpdates: %v", err)
+	}
+	call := fake.calls["getUpdates"]
+	if call["offset"] != float64(10) || call["timeout"] != float64(30) {
+		t.Errorf("unexpected getUpdates payload %v", call)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2", len(updates))
+	}
+
+	msg := updates[0].Message
+	if msg == nil || msg.Text != "/link ABCD2345" || msg.Chat.ID != 42 || msg.Chat.Type != "private" || msg.From.Username != "ivan" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	cq := updates[1].CallbackQuery
+	if cq == nil || cq.ID != "cb1" || cq.From.ID != 42 || cq.Data != "verify:5:1" {
+		t.Fatalf("unexpected callback query %+v", cq)
+	}
+	if cq.Message == nil || cq.Message.MessageID != 7 || cq.Message.Chat.ID != 42 {
+		t.Errorf("unexpected callback message %+v", cq.Message)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	fake, client := newFakeBotAPI(t, map[string]string{"sendMessage": `{"message_id": 3}`})
+
+	markup := &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "Legit", CallbackData: "verify:5:1"},
+	}}}
+	if err := client.SendMessage(42, "<b>Task</b>", markup); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	call := fake.calls["sendMessage"]
+	if call["chat_id"] != float64(42) || call["text"] != "<b>Task</b>" || call["parse_mode"] != "HTML" {
+		t.Errorf("unexpected sendMessage payload %v", call)
+	}
+	keyboard, _ := json.Marshal(call["reply_markup"])
+	if string(keyboard) != `{"inline_keyboard":[[{"callback_data":"verify:5:1","text":"Legit"}]]}` {
+		t.Errorf("unexpected reply_markup %s", keyboard)
+	}
+
+	if err := client.SendMessage(42, "plain", nil); err != nil {
+		t.Fatalf("SendMessage without markup: %v", err)
+	}
+	if _, ok := fake.calls["sendMessage"]["reply_markup"]; ok {
+		t.Error("reply_markup sent without a keyboard")
+	}
+}
+
+func TestCallbackQuery(t *testing.T) {
+	fake, client := newFakeBotAPI(t, map[string]string{
+		"answerCallbackQuery": `true`,
+		"editMessageText":     `{"message_id": 7}`,
+	})
+
+	if err := client.AnswerCallbackQuery("cb1", "Done"); err != nil {
+		t.Fatalf("AnswerCallbackQuery: %v", err)
+	}
+	call := fake.calls["answerCallbackQuery"]
+	if call["callback_query_id"] != "cb1" || call["text"] != "Done" {
+		t.Errorf("unexpected answerCallbackQuery payload %v", call)
+	}
+
+	if err := client.EditMessageText(42, 7, "verified"); err != nil {
+		t.Fatalf("EditMessageText: %v", err)
+	}
+	call = fake.calls["editMessageText"]
+	if call["chat_id"] != float64(42) || call["message_id"] != float64(7) || call["text"] != "verified" {
+		t.Errorf("unexpected editMessageText payload %v", call)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	_, client := newFakeBotAPI(t, map[string]string{})
+
+	err := client.SendMessage(42, "text", nil)
+	if err == nil || !strings.Contains(err.Error(), "Bad Request: unknown method") {
+		t.Errorf("got error %v, want the API description", err)
+	}
+}
@@ -0,0 +1,260 @@
+package timetable
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences caps RRULE expansion so a rule without UNTIL/COUNT stays bounded
+const maxOccurrences = 200
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICal reads VEVENTs from an iCalendar file. Weekly RRULEs (INTERVAL, COUNT, UNTIL)
+// and EXDATEs are expanded; SUMMARY is the subject, LOCATION the room, and the teacher is
+// taken from ORGANIZER's CN or a "Преподаватель:"/"Teacher:" line in DESCRIPTION.
+func ParseICal(r io.Reader) ([]Lesson, []ParseError, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, nil, ErrUnsupportedFormat
+	}
+
+	var lessons []Lesson
+	var problems []ParseError
+	var event []icalProperty
+	inEvent := false
+	eventCount := 0
+
+	for _, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			inEvent = true
+			event = event[:0]
+			eventCount++
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			inEvent = false
+			parsed, err := eventLessons(event, fmt.Sprintf("event %d", eventCount))
+			if err != nil {
+				problems = append(problems, *err)
+				continue
+			}
+			lessons = append(lessons, parsed...)
+		case inEvent:
+			event = append(event, prop)
+		}
+	}
+
+	return lessons, problems, nil
+}
+
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseProperty(line string) (icalProperty, bool) {
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return icalProperty{}, false
+	}
+	head := strings.Split(line[:colon], ";")
+	prop := icalProperty{
+		name:   strings.ToUpper(head[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prop, true
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(value)
+}
+
+// parseICalTime returns the wall-clock time of a DATE-TIME value. UTC values are
+// converted to the server's local zone, TZID values are kept in their own zone.
+func parseICalTime(prop icalProperty) (time.Time, error) {
+	if strings.EqualFold(prop.params["VALUE"], "DATE") {
+		return time.Time{}, fmt.Errorf("all-day events are not lessons")
+	}
+	value := strings.TrimSpace(prop.value)
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.In(time.Local), nil
+	}
+	loc := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func eventLessons(props []icalProperty, source string) ([]Lesson, *ParseError) {
+	var start, end time.Time
+	var rrule string
+	exdates := make(map[string]bool)
+	lesson := Lesson{Source: source}
+
+	for _, prop := range props {
+		switch prop.name {
+		case "DTSTART":
+			t, err := parseICalTime(prop)
+			if err != nil {
+				return nil, &ParseError{source, "invalid DTSTART: " + err.Error()}
+			}
+			start = t
+		case "DTEND":
+			t, err := parseICalTime(prop)
+			if err != nil {
+				return nil, &ParseError{source, "invalid DTEND: " + err.Error()}
+			}
+			end = t
+		case "SUMMARY":
+			lesson.Subject = normalizeSpaces(unescapeText(prop.value))
+		case "LOCATION":
+			lesson.Room = normalizeSpaces(unescapeText(prop.value))
+		case "ORGANIZER":
+			if cn := prop.params["CN"]; cn != "" && lesson.Teacher == "" {
+				lesson.Teacher = normalizeSpaces(cn)
+			}
+		case "DESCRIPTION":
+			for _, line := range strings.Split(unescapeText(prop.value), "\n") {
+				for _, prefix := range []string{"Преподаватель:", "Teacher:"} {
+					if rest, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+						lesson.Teacher = normalizeSpaces(rest)
+					}
+				}
+			}
+		case "RRULE":
+			rrule = prop.value
+		case "EXDATE":
+			for _, value := range strings.Split(prop.value, ",") {
+				t, err := parseICalTime(icalProperty{name: prop.name, params: prop.params, value: value})
+				if err == nil {
+					exdates[t.Format("2006-01-02")] = true
+				}
+			}
+		}
+	}
+
+	if lesson.Subject == "" {
+		return nil, &ParseError{source, "SUMMARY is missing"}
+	}
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return nil, &ParseError{source, "DTSTART/DTEND are missing or invalid"}
+	}
+	if start.Format("2006-01-02") != end.Format("2006-01-02") {
+		return nil, &ParseError{source, "event spans several days"}
+	}
+	lesson.StartTime = start.Format("15:04")
+	lesson.EndTime = end.Format("15:04")
+
+	dates := []time.Time{start}
+	if rrule != "" {
+		expanded, err := expandWeekly(start, rrule)
+		if err != nil {
+			return nil, &ParseError{source, err.Error()}
+		}
+		dates = expanded
+	}
+
+	lessons := make([]Lesson, 0, len(dates))
+	for _, d := range dates {
+		if exdates[d.Format("2006-01-02")] {
+			continue
+		}
+		l := lesson
+		l.Date = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		lessons = append(lessons, l)
+	}
+	return lessons, nil
+}
+
+// expandWeekly expands FREQ=WEEKLY rules; other frequencies are rejected
+func expandWeekly(start time.Time, rrule string) ([]time.Time, error) {
+	interval, count := 1, maxOccurrences
+	var until time.Time
+
+	for _, part := range strings.Split(rrule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			if !strings.EqualFold(value, "WEEKLY") {
+				return nil, fmt.Errorf("unsupported RRULE frequency %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE interval %s", value)
+			}
+			interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE count %s", value)
+			}
+			if n < count {
+				count = n
+			}
+		case "UNTIL":
+			t, err := parseICalTime(icalProperty{value: value, params: map[string]string{}})
+			if err != nil {
+				t, err = time.ParseInLocation("20060102", value, start.Location())
+				if err != nil {
+					return nil, fmt.Errorf("invalid RRULE until %s", value)
+				}
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			until = t
+		case "BYDAY":
+			// one lesson per event is what the university export produces
+			if strings.Contains(value, ",") {
+				return nil, fmt.Errorf("RRULE with several BYDAY values is not supported")
+			}
+		}
+	}
+
+	var dates []time.Time
+	for d := start; len(dates) < count; d = d.AddDate(0, 0, 7*interval) {
+		if !until.IsZero() && d.After(until) {
+			break
+		}
+		dates = append(dates, d)
+	}
+	return dates, nil
+}
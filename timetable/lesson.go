@@ -0,0 +1,101 @@
+// Package timetable parses the university timetable exports (XLSX and iCalendar)
+// into lessons that the schedule importer turns into TimeSlot/Subject/Schedule rows.
+package timetable
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lesson is one parsed timetable row. XLSX rows describe a weekly pattern
+// (Weekday/WeekParity), iCalendar events carry a concrete Date.
+type Lesson struct {
+	Source     string // row or event reference for the import report, e.g. "row 12"
+	Date       time.Time
+	Weekday    int32  // 1 = Monday, 0 when Date is set
+	WeekParity string // all, odd (numerator), even (denominator)
+	SlotNumber int32  // 0 when the export has no pair number
+	StartTime  string // HH:MM
+	EndTime    string // HH:MM
+	Subject    string
+	Teacher    string
+	Room       string
+}
+
+// ParseError reports a row that couldn't be read; parsing continues with the next row
+type ParseError struct {
+	Source  string
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Source, e.Message)
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported timetable format")
+
+var weekdays = map[string]int32{
+	"понедельник": 1, "пн": 1, "monday": 1, "mon": 1,
+	"вторник": 2, "вт": 2, "tuesday": 2, "tue": 2,
+	"среда": 3, "ср": 3, "wednesday": 3, "wed": 3,
+	"четверг": 4, "чт": 4, "thursday": 4, "thu": 4,
+	"пятница": 5, "пт": 5, "friday": 5, "fri": 5,
+	"суббота": 6, "сб": 6, "saturday": 6, "sat": 6,
+	"воскресенье": 7, "вс": 7, "sunday": 7, "sun": 7,
+}
+
+func parseWeekday(value string) (int32, bool) {
+	value = strings.Trim(strings.ToLower(strings.TrimSpace(value)), ".")
+	if day, ok := weekdays[value]; ok {
+		return day, true
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= 7 {
+		return int32(n), true
+	}
+	return 0, false
+}
+
+func parseParity(value string) (string, bool) {
+	switch strings.Trim(strings.ToLower(strings.TrimSpace(value)), ".") {
+	case "", "все", "всегда", "all", "every", "каждая":
+		return "all", true
+	case "числитель", "чис", "нечет", "нечетная", "нечётная", "odd", "numerator", "1":
+		return "odd", true
+	case "знаменатель", "знам", "чет", "четная", "чётная", "even", "denominator", "2":
+		return "even", true
+	}
+	return "", false
+}
+
+var timeRangePattern = regexp.MustCompile(`(\d{1,2})[:.](\d{2})\s*[-–—]\s*(\d{1,2})[:.](\d{2})`)
+
+// parseTimeRange reads "8:30-10:00", "08.30–10.00" and similar into HH:MM strings
+func parseTimeRange(value string) (string, string, bool) {
+	m := timeRangePattern.FindStringSubmatch(value)
+	if m == nil {
+		return "", "", false
+	}
+	start, ok1 := clock(m[1], m[2])
+	end, ok2 := clock(m[3], m[4])
+	if !ok1 || !ok2 || end <= start {
+		return "", "", false
+	}
+	return start, end, true
+}
+
+func clock(hours, minutes string) (string, bool) {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	if h > 23 || m > 59 {
+		return "", false
+	}
+	return fmt.Sprintf("%02d:%02d", h, m), true
+}
+
+func normalizeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
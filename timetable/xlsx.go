@@ -0,0 +1,170 @@
+package timetable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// headerAliases maps the column headers used in the university export to lesson fields.
+// Headers are matched case-insensitively by prefix, in this order.
+var headerAliases = []struct {
+	field   string
+	aliases []string
+}{
+	{"day", []string{"день", "day"}},
+	{"week", []string{"неделя", "числ", "week", "parity"}},
+	{"pair", []string{"пара", "№", "номер", "pair", "slot"}},
+	{"time", []string{"время", "time"}},
+	{"subject", []string{"дисциплина", "предмет", "subject"}},
+	{"teacher", []string{"преподаватель", "teacher"}},
+	{"room", []string{"аудитория", "ауд", "room", "classroom"}},
+}
+
+const headerSearchRows = 20
+
+// ParseXLSX reads the first sheet of a timetable workbook. The sheet has a header row with
+// Day, Pair, Time, Subject, Teacher, Room and Week columns (Russian or English titles);
+// Day, Pair and Time cells may be merged over the rows below them.
+func ParseXLSX(r io.Reader) ([]Lesson, []ParseError, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, ErrUnsupportedFormat
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, errors.New("workbook has no sheets")
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerRow, columns := findHeader(rows)
+	if headerRow < 0 {
+		return nil, nil, errors.New("timetable header row not found")
+	}
+
+	cell := func(row []string, field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return normalizeSpaces(row[idx])
+	}
+
+	var lessons []Lesson
+	var problems []ParseError
+	var day int32
+	var pair int32
+	var start, end string
+
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		source := fmt.Sprintf("row %d", i+1)
+
+		if value := cell(row, "day"); value != "" {
+			parsed, ok := parseWeekday(value)
+			if !ok {
+				problems = append(problems, ParseError{source, fmt.Sprintf("unknown weekday %q", value)})
+				day = 0
+				continue
+			}
+			if parsed != day {
+				pair, start, end = 0, "", ""
+			}
+			day = parsed
+		}
+		if value := cell(row, "pair"); value != "" {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, "."))
+			if err != nil || n < 1 || n > 9 {
+				problems = append(problems, ParseError{source, fmt.Sprintf("invalid pair number %q", value)})
+				continue
+			}
+			pair = int32(n)
+			start, end = "", ""
+		}
+		if value := cell(row, "time"); value != "" {
+			s, e, ok := parseTimeRange(value)
+			if !ok {
+				problems = append(problems, ParseError{source, fmt.Sprintf("invalid time range %q", value)})
+				continue
+			}
+			start, end = s, e
+		}
+
+		subject := cell(row, "subject")
+		if subject == "" {
+			continue
+		}
+		if day == 0 {
+			problems = append(problems, ParseError{source, "weekday is missing"})
+			continue
+		}
+		if pair == 0 && start == "" {
+			problems = append(problems, ParseError{source, "pair number or time is missing"})
+			continue
+		}
+		parity, ok := parseParity(cell(row, "week"))
+		if !ok {
+			problems = append(problems, ParseError{source, fmt.Sprintf("unknown week %q", cell(row, "week"))})
+			continue
+		}
+
+		lessons = append(lessons, Lesson{
+			Source:     source,
+			Weekday:    day,
+			WeekParity: parity,
+			SlotNumber: pair,
+			StartTime:  start,
+			EndTime:    end,
+			Subject:    subject,
+			Teacher:    cell(row, "teacher"),
+			Room:       cell(row, "room"),
+		})
+	}
+
+	return lessons, problems, nil
+}
+
+func findHeader(rows [][]string) (int, map[string]int) {
+	for i := 0; i < len(rows) && i < headerSearchRows; i++ {
+		columns := make(map[string]int)
+		for j, value := range rows[i] {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value == "" {
+				continue
+			}
+			for _, h := range headerAliases {
+				if _, taken := columns[h.field]; taken {
+					continue
+				}
+				if hasAnyPrefix(value, h.aliases) {
+					columns[h.field] = j
+					break
+				}
+			}
+		}
+		if _, ok := columns["subject"]; ok {
+			if _, ok := columns["day"]; ok {
+				return i, columns
+			}
+		}
+	}
+	return -1, nil
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(value, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "strings"
+
+// ClassroomKey normalizes a classroom name for matching, so "А-101", "а 101" and "A.101"
+// written with the same letters are the same room. ClassroomKeySQL is the same expression
+// for the schedules.classroom column.
+func ClassroomKey(classroom string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.ToLower(classroom))
+}
+
+const ClassroomKeySQL = "lower(replace(replace(replace(classroom, ' ', ''), '-', ''), '.', ''))"
+
+// RoomLabel is how a registered room is written in schedules
+func RoomLabel(building, number string) string {
+	if building == "" {
+		return number
+	}
+	return building + "-" + number
+}
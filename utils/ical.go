@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// ICalWriter builds an RFC 5545 calendar with CRLF line endings and folded lines
+type ICalWriter struct {
+	b strings.Builder
+}
+
+func NewICalWriter(name string) *ICalWriter {
+	w := &ICalWriter{}
+	w.Line("BEGIN:VCALENDAR")
+	w.Line("VERSION:2.0")
+	w.Line("PRODID:-//4edu.su//Workspace//RU")
+	w.Line("CALSCALE:GREGORIAN")
+	w.Line("METHOD:PUBLISH")
+	w.Prop("X-WR-CALNAME", name)
+	return w
+}
+
+// Line writes a raw content line, folding it at 75 octets
+func (w *ICalWriter) Line(line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// don't split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.b.WriteString(line[:cut])
+		w.b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.b.WriteString(line)
+	w.b.WriteString("\r\n")
+}
+
+// Prop writes a property with an escaped text value
+func (w *ICalWriter) Prop(name, value string) {
+	w.Line(name + ":" + ICalEscape(value))
+}
+
+// Time writes a property with a UTC date-time value
+func (w *ICalWriter) Time(name string, t time.Time) {
+	w.Line(name + ":" + t.UTC().Format("20060102T150405Z"))
+}
+
+func (w *ICalWriter) String() string {
+	return w.b.String() + "END:VCALENDAR\r\n"
+}
+
+func ICalEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\r\n", `\n`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "strings"
+
+// InitialsKey normalizes teacher initials for matching, so "Иванов И. И." and "иванов и.и." are
+// the same teacher. InitialsKeySQL is the same expression for a column.
+func InitialsKey(initials string) string {
+	return strings.NewReplacer(" ", "", ".", "").Replace(strings.ToLower(initials))
+}
+
+const InitialsKeySQL = "lower(replace(replace(initials, ' ', ''), '.', ''))"
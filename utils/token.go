@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+)
+
+// RandomToken returns a hex-encoded random string of n bytes, suitable for secret URLs
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// codeAlphabet leaves out characters that are easy to confuse when read aloud or copied
+// from a projector: 0/O, 1/I/L
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// RandomCode returns a random human-readable code of n characters, each drawn uniformly from
+// codeAlphabet
+func RandomCode(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range b {
+		index, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = codeAlphabet[index.Int64()]
+	}
+	return string(b), nil
+}